@@ -0,0 +1,93 @@
+package main
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/session"
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// 创建首个管理员账号，或使用 -reset 重置已有账号的密码并设为管理员
+func main() {
+	username := flag.String("username", "admin", "管理员用户名")
+	displayName := flag.String("display-name", "系统管理员", "显示名称")
+	password := flag.String("password", "", "管理员密码（也可通过 AMS_ADMIN_PASSWORD 指定，均为空时从标准输入读取）")
+	reset := flag.Bool("reset", false, "用户已存在时重置其密码并设为启用的管理员")
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	if *password == "" {
+		*password = os.Getenv("AMS_ADMIN_PASSWORD")
+	}
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "请输入管理员密码: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("读取密码失败: %v", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+	if err := model.ValidateUsername(*username); err != nil {
+		log.Fatal(err)
+	}
+	if err := model.ValidatePassword(*password); err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := model.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	users := model.NewMySQLUserRepository(db)
+	hash, err := model.HashPassword(*password)
+	if err != nil {
+		log.Fatalf("计算密码哈希失败: %v", err)
+	}
+
+	existing, err := users.GetByUsername(ctx, *username)
+	switch {
+	case err == model.ErrUserNotFound:
+		user := &model.User{
+			Username:     *username,
+			DisplayName:  *displayName,
+			PasswordHash: hash,
+			Role:         model.RoleAdmin,
+			IsActive:     true,
+		}
+		if err := users.Create(ctx, user); err != nil {
+			log.Fatalf("创建管理员失败: %v", err)
+		}
+		log.Printf("管理员 %s 创建成功", user.Username)
+	case err != nil:
+		log.Fatalf("查询用户失败: %v", err)
+	case !*reset:
+		log.Fatalf("用户 %s 已存在，如需重置密码请加 -reset", *username)
+	default:
+		existing.Role = model.RoleAdmin
+		existing.IsActive = true
+		if err := users.Save(ctx, existing, hash); err != nil {
+			log.Fatalf("重置密码失败: %v", err)
+		}
+		log.Printf("管理员 %s 密码已重置", existing.Username)
+		// 与网页修改密码一致，使旧密码建立的会话全部失效
+		if cfg.Session.Store == "mysql" {
+			if err := session.NewMySQLStore(db).DeleteByUser(ctx, existing.ID); err != nil {
+				log.Fatalf("删除用户会话失败: %v", err)
+			}
+			log.Printf("已注销用户 %s 的全部会话", existing.Username)
+		} else {
+			log.Printf("会话存储为 %s，已有会话保存在服务进程内，请重启服务使其失效", cfg.Session.Store)
+		}
+	}
+}
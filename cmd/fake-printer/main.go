@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 模拟一台通过 raw TCP（9100 端口）接收 ZPL 的标签打印机，用于在没有打印机时测试标签打印。
+// 每个连接视为一个打印任务，收到的内容打印到标准输出，指定 -dir 时另存为文件
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9100", "监听地址")
+	dir := flag.String("dir", "", "保存打印任务的目录，为空时不保存")
+	quiet := flag.Bool("quiet", false, "不在标准输出打印任务内容")
+	flag.Parse()
+
+	if *dir != "" {
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			log.Fatalf("创建目录失败: %v", err)
+		}
+	}
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("监听 %s 失败: %v", *addr, err)
+	}
+	log.Printf("模拟打印机已启动，监听 %s", ln.Addr())
+
+	for job := 1; ; job++ {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Fatalf("接受连接失败: %v", err)
+		}
+		go receive(conn, job, *dir, *quiet)
+	}
+}
+
+// receive 读取一个打印任务直到对方关闭连接
+func receive(conn net.Conn, job int, dir string, quiet bool) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		log.Printf("任务 %d: 读取失败: %v", job, err)
+		return
+	}
+	labels := strings.Count(strings.ToUpper(string(data)), "^XZ")
+	log.Printf("任务 %d: 来自 %s，%d 字节，%d 张标签", job, conn.RemoteAddr(), len(data), labels)
+	if !quiet {
+		fmt.Println(string(data))
+	}
+	if dir != "" {
+		path := filepath.Join(dir, fmt.Sprintf("job-%s-%d.zpl", time.Now().Format("20060102-150405"), job))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			log.Printf("任务 %d: 保存失败: %v", job, err)
+			return
+		}
+		log.Printf("任务 %d: 已保存到 %s", job, path)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/importer"
+	"asset-management-system/pkg/model"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 从 CSV 或 XLSX 文件批量导入资产：逐行校验后在一个事务中导入通过校验的行。
+// 先用 -dry-run 预览每行的校验结果，确认无误后再去掉 -dry-run 导入
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "用法: %s [选项] <文件.csv|文件.xlsx>\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	encoding := flag.String("encoding", importer.EncodingAuto, "CSV 文件的编码：auto、utf-8 或 gbk")
+	dryRun := flag.Bool("dry-run", false, "只校验并列出每行的问题，不导入")
+	username := flag.String("user", "", "以该用户的身份导入（记入变更记录并限制部门范围），默认为 system")
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("读取文件失败: %v", err)
+	}
+	format := "CSV"
+	var table *importer.Table
+	if importer.IsXLSX(data) {
+		format = "XLSX"
+		table, err = importer.ReadXLSX(data)
+	} else {
+		table, err = importer.ReadCSV(data, *encoding)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := model.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	assets := model.NewMySQLAssetRepository(db)
+	actor, scope := model.SystemActor, model.AllAssets
+	if *username != "" {
+		user, err := model.NewMySQLUserRepository(db).GetByUsername(ctx, *username)
+		if err != nil {
+			log.Fatalf("查询用户 %s 失败: %v", *username, err)
+		}
+		if !user.Can(model.PermCreateAsset) {
+			log.Fatalf("用户 %s 没有新建资产的权限", *username)
+		}
+		actor, scope = model.ActorFor(user), model.ScopeFor(user)
+	}
+
+	existing, err := assets.List(ctx)
+	if err != nil {
+		log.Fatalf("查询已有资产失败: %v", err)
+	}
+	serials := make(map[string]bool, len(existing))
+	for _, a := range existing {
+		if a.SerialNumber != "" {
+			serials[a.SerialNumber] = true
+		}
+	}
+	result, err := table.Parse(importer.Options{Validate: model.PrepareNewAsset, Scope: scope, Existing: serials})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if result.Encoding != "" {
+		fmt.Printf("编码: %s\n", result.Encoding)
+	}
+	fmt.Printf("识别的列: %s\n", strings.Join(result.Columns, ", "))
+	if len(result.Ignored) > 0 {
+		fmt.Printf("忽略的列: %s\n", strings.Join(result.Ignored, ", "))
+	}
+	for _, row := range result.Rows {
+		if len(row.Errors) > 0 {
+			fmt.Printf("第 %d 行（%s）: %s\n", row.Line, row.Asset.SerialNumber, strings.Join(row.Errors, "；"))
+		}
+	}
+	fmt.Printf("通过校验 %d 行，未通过 %d 行\n", result.Valid, result.Invalid)
+	if *dryRun || result.Valid == 0 {
+		if result.Invalid > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	reason := fmt.Sprintf("%s 导入：%s", format, filepath.Base(path))
+	if err := assets.Import(ctx, actor, result.ValidAssets(), reason); err != nil {
+		log.Fatalf("导入失败，所有行均未导入: %v", err)
+	}
+	fmt.Printf("已导入 %d 条资产，跳过 %d 行\n", result.Valid, result.Invalid)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"asset-management-system/migrations"
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/migrate"
+	"asset-management-system/pkg/model"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `用法: migrate [-config 配置文件] [-dsn DSN] <命令>
+
+命令:
+  up              执行所有未执行的迁移
+  down [-steps N] 回滚最近 N 个迁移（默认 1）
+  status          查看迁移执行状态
+`)
+}
+
+func main() {
+	flag.Usage = usage
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	command, args := flag.Arg(0), flag.Args()[1:]
+
+	all, err := migrate.Load(migrations.FS)
+	if err != nil {
+		log.Fatalf("读取迁移脚本失败: %v", err)
+	}
+
+	db, err := model.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	migrator := migrate.New(db, all)
+
+	switch command {
+	case "up":
+		count, err := migrator.Up()
+		if err != nil {
+			log.Fatalf("迁移失败: %v", err)
+		}
+		log.Printf("迁移完成，本次执行 %d 个迁移", count)
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "回滚的迁移数量")
+		fs.Parse(args)
+		count, err := migrator.Down(*steps)
+		if err != nil {
+			log.Fatalf("回滚失败: %v", err)
+		}
+		log.Printf("回滚完成，本次回滚 %d 个迁移", count)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("查询迁移状态失败: %v", err)
+		}
+		for _, s := range statuses {
+			state := "未执行"
+			if s.Applied {
+				state = "已执行 " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/notify"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+)
+
+// 立即扫描一次到期归还提醒并按配置发送，用于验证 SMTP、webhook 配置或补发提醒。
+// 配合 -date 可以模拟指定日期的扫描结果，配合 -dry-run 只打印不发送
+func main() {
+	dryRun := flag.Bool("dry-run", false, "只打印将要发送的提醒，不发送也不记录")
+	date := flag.String("date", "", "按指定日期（YYYY-MM-DD）判断是否到期，默认今天")
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	now := time.Now()
+	if *date != "" {
+		d, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+		if err != nil {
+			log.Fatalf("日期格式错误，应为 YYYY-MM-DD: %q", *date)
+		}
+		now = time.Date(d.Year(), d.Month(), d.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.Local)
+	}
+
+	db, err := model.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	store := model.NewMySQLNotificationRepository(db)
+	notifiers := notify.FromConfig(cfg.Reminders, store)
+	if len(notifiers) == 0 && !*dryRun {
+		log.Fatal("没有可用的提醒渠道，请在配置中开启 reminders.inbox 或配置 smtp、webhook")
+	}
+	reminder := notify.NewReminder(cfg.Reminders, model.NewMySQLAssetRepository(db), model.NewMySQLUserRepository(db), store, notifiers)
+	reminder.DryRun = *dryRun
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	sent, err := reminder.RunOnce(ctx, now)
+	if err != nil {
+		log.Fatalf("扫描到期提醒失败: %v", err)
+	}
+	log.Printf("扫描完成，共 %d 条提醒", sent)
+}
@@ -1,49 +1,66 @@
 package main
 
 import (
-"asset-management-system/pkg/handler"
-"log"
-"net/http"
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/handler"
+	"asset-management-system/pkg/model"
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-log.Println("启动资产管理系统服务器...")
-
-// 路由
-http.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
-log.Printf("路由 /login 触发，方法: %s, URL: %s", r.Method, r.URL.Path)
-handler.LoginHandler(w, r)
-})
-
-http.HandleFunc("/asset-entry", func(w http.ResponseWriter, r *http.Request) {
-log.Printf("路由 /asset-entry 触发，方法: %s, URL: %s", r.Method, r.URL.Path)
-handler.AssetEntryHandler(w, r)
-})
-
-http.HandleFunc("/assets/list", func(w http.ResponseWriter, r *http.Request) {
-log.Printf("路由 /assets/list 触发，方法: %s, URL: %s", r.Method, r.URL.Path)
-handler.AssetListHandler(w, r)
-})
-
-// 添加根路径 / 路由，检查登录状态并重定向
-http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-log.Printf("路由 / 触发，方法: %s, URL: %s", r.Method, r.URL.Path)
-if !handler.IsAuthenticated(r) { // 假设 handler 包中有 IsAuthenticated 函数
-http.Redirect(w, r, "/login", http.StatusSeeOther)
-return
+	log.Println("启动资产管理系统服务器...")
+
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	if err := cfg.CheckPaths(); err != nil {
+		log.Fatal(err)
+	}
+
+	// 整个进程共享一个连接池
+	db, err := model.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	srv, err := handler.NewServer(cfg, db)
+	if err != nil {
+		log.Fatalf("初始化处理器失败: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go srv.RefreshAssetCache(ctx)
+	go srv.CleanupSessions(ctx)
+	go srv.RunReminders(ctx)
+
+	httpServer := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           srv.Routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		log.Println("正在关闭服务器...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("关闭服务器失败: %v", err)
+		}
+	}()
+
+	// 启动服务器
+	log.Printf("服务器运行在 %s...", cfg.Server.Addr)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
 }
-// 已登录用户渲染资产录入页面
-handler.AssetEntryHandler(w, r) // 或其他默认页面
-})
-
-// 自定义静态文件服务
-fs := http.FileServer(http.Dir("./static"))
-http.Handle("/static/", http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-log.Printf("服务静态文件: %s", r.URL.Path)
-fs.ServeHTTP(w, r)
-})))
-
-// 启动服务器
-log.Println("服务器运行在 :8080...")
-log.Fatal(http.ListenAndServe(":8080", nil))
-}
\ No newline at end of file
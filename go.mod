@@ -2,6 +2,22 @@ module asset-management-system
 
 go 1.19
 
-require github.com/go-sql-driver/mysql v1.8.0
+require (
+	github.com/boombuler/barcode v1.0.2
+	github.com/go-pdf/fpdf v0.8.0
+	github.com/go-sql-driver/mysql v1.8.0
+	github.com/xuri/excelize/v2 v2.8.0
+	golang.org/x/crypto v0.21.0
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
-require filippo.io/edwards25519 v1.1.0 // indirect
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
+	github.com/richardlehane/mscfb v1.0.4 // indirect
+	github.com/richardlehane/msoleps v1.0.3 // indirect
+	github.com/xuri/efp v0.0.0-20230802181842-ad255f2331ca // indirect
+	github.com/xuri/nfp v0.0.0-20230819163627-dc951e3ffe1a // indirect
+	golang.org/x/net v0.21.0 // indirect
+)
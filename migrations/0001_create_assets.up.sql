@@ -0,0 +1,23 @@
+-- 资产表及常用查询索引
+CREATE TABLE IF NOT EXISTS assets (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    serial_number VARCHAR(100),
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    brand VARCHAR(50) NOT NULL,
+    application_date DATE,
+    specification VARCHAR(255),
+    asset_code VARCHAR(100),
+    order_date DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    department VARCHAR(100),
+    location VARCHAR(100),
+    supplier VARCHAR(100),
+    recipient VARCHAR(100),
+    recipient_department VARCHAR(100),
+    remarks TEXT
+);
+
+CREATE INDEX idx_assets_serial_number ON assets(serial_number);
+CREATE INDEX idx_assets_name ON assets(name);
+CREATE INDEX idx_assets_created_at ON assets(created_at);
@@ -0,0 +1,10 @@
+-- 服务端会话，cookie 中只保存签名后的会话 ID
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    last_seen_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    KEY idx_sessions_user_id (user_id),
+    KEY idx_sessions_expires_at (expires_at)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN is_admin TINYINT(1) NOT NULL DEFAULT 0 AFTER display_name;
+UPDATE users SET is_admin = 1 WHERE role = 'admin';
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,4 @@
+-- 以角色替代 is_admin 标记
+ALTER TABLE users ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'viewer' AFTER display_name;
+UPDATE users SET role = 'admin' WHERE is_admin = 1;
+ALTER TABLE users DROP COLUMN is_admin;
@@ -0,0 +1,7 @@
+-- 用户可见的部门范围，系统管理员不受限制
+CREATE TABLE IF NOT EXISTS user_departments (
+    user_id INT NOT NULL,
+    department VARCHAR(100) NOT NULL,
+    PRIMARY KEY (user_id, department),
+    KEY idx_user_departments_department (department)
+);
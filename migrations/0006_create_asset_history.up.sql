@@ -0,0 +1,13 @@
+-- 资产变更审计记录，每个变更字段一行
+CREATE TABLE IF NOT EXISTS asset_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    asset_id INT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    field VARCHAR(64) NOT NULL DEFAULT '',
+    old_value TEXT,
+    new_value TEXT,
+    changed_by INT NOT NULL DEFAULT 0,
+    changed_by_name VARCHAR(100) NOT NULL DEFAULT '',
+    changed_at DATETIME NOT NULL,
+    KEY idx_asset_history_asset_id (asset_id, changed_at)
+);
@@ -0,0 +1,7 @@
+-- 回滚前彻底删除回收站中的资产，否则它们会重新出现在列表中
+DELETE FROM assets WHERE deleted_at IS NOT NULL;
+DROP INDEX idx_assets_deleted_at ON assets;
+ALTER TABLE assets DROP COLUMN delete_reason;
+ALTER TABLE assets DROP COLUMN deleted_by_name;
+ALTER TABLE assets DROP COLUMN deleted_by;
+ALTER TABLE assets DROP COLUMN deleted_at;
@@ -0,0 +1,6 @@
+-- 软删除：删除只做标记，记录删除人、时间和原因，可从回收站恢复
+ALTER TABLE assets ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE assets ADD COLUMN deleted_by INT NOT NULL DEFAULT 0;
+ALTER TABLE assets ADD COLUMN deleted_by_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE assets ADD COLUMN delete_reason VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX idx_assets_deleted_at ON assets(deleted_at);
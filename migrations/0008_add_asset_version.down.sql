@@ -0,0 +1,2 @@
+ALTER TABLE asset_history DROP COLUMN version;
+ALTER TABLE assets DROP COLUMN version;
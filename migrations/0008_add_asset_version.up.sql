@@ -0,0 +1,4 @@
+-- 乐观锁版本号：每次修改加一，编辑时提交打开表单时的版本，不一致说明期间已被他人修改
+ALTER TABLE assets ADD COLUMN version INT NOT NULL DEFAULT 1;
+-- 变更记录所对应的资产版本，用于列出冲突期间他人所做的修改
+ALTER TABLE asset_history ADD COLUMN version INT NOT NULL DEFAULT 0 AFTER asset_id;
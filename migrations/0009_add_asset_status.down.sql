@@ -0,0 +1,3 @@
+ALTER TABLE asset_history DROP COLUMN reason;
+DROP INDEX idx_assets_status ON assets;
+ALTER TABLE assets DROP COLUMN status;
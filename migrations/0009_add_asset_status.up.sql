@@ -0,0 +1,7 @@
+-- 资产生命周期状态，取值见 model.AssetStatus，新建资产默认在库
+ALTER TABLE assets ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'in_stock';
+CREATE INDEX idx_assets_status ON assets(status);
+-- 已有领用人的资产视为在用
+UPDATE assets SET status = 'in_use' WHERE recipient IS NOT NULL AND recipient <> '';
+-- 状态转换等操作的原因
+ALTER TABLE asset_history ADD COLUMN reason VARCHAR(255) NOT NULL DEFAULT '' AFTER new_value;
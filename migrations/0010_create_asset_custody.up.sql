@@ -0,0 +1,24 @@
+-- 资产保管记录：每次领用/借出一行，归还时补全归还信息；checked_in_at 为空表示仍在保管中
+CREATE TABLE IF NOT EXISTS asset_custody (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    asset_id INT NOT NULL,
+    holder VARCHAR(100) NOT NULL,
+    holder_department VARCHAR(100) NOT NULL DEFAULT '',
+    checked_out_at DATETIME NOT NULL,
+    expected_return_date DATE NULL,
+    checkout_note VARCHAR(255) NOT NULL DEFAULT '',
+    checked_out_by INT NOT NULL DEFAULT 0,
+    checked_out_by_name VARCHAR(100) NOT NULL DEFAULT '',
+    checked_in_at DATETIME NULL,
+    checkin_condition VARCHAR(32) NOT NULL DEFAULT '',
+    checkin_note VARCHAR(255) NOT NULL DEFAULT '',
+    checked_in_by INT NOT NULL DEFAULT 0,
+    checked_in_by_name VARCHAR(100) NOT NULL DEFAULT '',
+    KEY idx_asset_custody_asset_id (asset_id, checked_out_at),
+    KEY idx_asset_custody_holder (holder, checked_in_at)
+);
+-- 已有领用人的资产补一条未归还的保管记录，领用时间取资产创建日期
+INSERT INTO asset_custody (asset_id, holder, holder_department, checked_out_at, checkout_note, checked_out_by_name)
+SELECT id, recipient, COALESCE(recipient_department, ''), COALESCE(created_at, NOW()), '迁移前登记的领用人', 'system'
+FROM assets
+WHERE recipient IS NOT NULL AND recipient <> '' AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN email;
+DROP INDEX idx_assets_expected_return_date ON assets;
+ALTER TABLE assets DROP COLUMN expected_return_date;
@@ -0,0 +1,8 @@
+-- 预计归还日期：领用时填写，归还时清空，逾期提醒按此日期判断
+ALTER TABLE assets ADD COLUMN expected_return_date DATE NULL;
+CREATE INDEX idx_assets_expected_return_date ON assets(expected_return_date);
+-- 从未归还的保管记录同步已填写的预计归还日期
+UPDATE assets a JOIN asset_custody c ON c.asset_id = a.id AND c.checked_in_at IS NULL
+SET a.expected_return_date = c.expected_return_date;
+-- 用户邮箱，用于发送到期提醒邮件
+ALTER TABLE users ADD COLUMN email VARCHAR(255) NOT NULL DEFAULT '';
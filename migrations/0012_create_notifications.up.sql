@@ -0,0 +1,21 @@
+-- 站内消息，read_at 为空表示未读
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    asset_id INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    read_at DATETIME NULL,
+    KEY idx_notifications_user (user_id, read_at, created_at)
+);
+-- 已发送的到期提醒，避免同一保管记录重复提醒；预计归还日期变更后重新提醒
+CREATE TABLE IF NOT EXISTS reminder_log (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    custody_id BIGINT NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    due_date DATE NOT NULL,
+    sent_at DATETIME NOT NULL,
+    KEY idx_reminder_log_custody (custody_id, kind, due_date, sent_at)
+);
@@ -0,0 +1,3 @@
+DROP INDEX idx_assets_asset_code ON assets;
+ALTER TABLE assets DROP COLUMN last_seen_by_name;
+ALTER TABLE assets DROP COLUMN last_seen_at;
@@ -0,0 +1,5 @@
+-- 最近一次确认资产在场（扫码或盘点）的时间和确认人
+ALTER TABLE assets ADD COLUMN last_seen_at DATETIME NULL;
+ALTER TABLE assets ADD COLUMN last_seen_by_name VARCHAR(100) NOT NULL DEFAULT '';
+-- 扫码时按资产编码精确查找
+CREATE INDEX idx_assets_asset_code ON assets(asset_code);
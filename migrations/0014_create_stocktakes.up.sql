@@ -0,0 +1,34 @@
+-- 盘点任务：按部门和所在地圈定范围，status 为 open（进行中）或 closed（已结束）
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    department VARCHAR(100) NOT NULL DEFAULT '',
+    location VARCHAR(100) NOT NULL DEFAULT '',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    created_at DATETIME NOT NULL,
+    created_by INT NOT NULL DEFAULT 0,
+    created_by_name VARCHAR(100) NOT NULL DEFAULT '',
+    closed_at DATETIME NULL,
+    closed_by_name VARCHAR(100) NOT NULL DEFAULT '',
+    KEY idx_stocktakes_created_at (created_at)
+);
+-- 盘点明细：开始盘点时范围内的每项资产一行（expected = 1），记录当时的部门和所在地；
+-- 盘点中登记了范围外的资产时追加一行（expected = 0）。found_at 为空表示尚未盘到，applied_at 非空表示已按实盘所在地更正
+CREATE TABLE IF NOT EXISTS stocktake_items (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    stocktake_id INT NOT NULL,
+    asset_id INT NOT NULL,
+    expected TINYINT(1) NOT NULL DEFAULT 1,
+    expected_department VARCHAR(100) NOT NULL DEFAULT '',
+    expected_location VARCHAR(100) NOT NULL DEFAULT '',
+    found_at DATETIME NULL,
+    found_location VARCHAR(100) NOT NULL DEFAULT '',
+    found_method VARCHAR(16) NOT NULL DEFAULT '',
+    found_by INT NOT NULL DEFAULT 0,
+    found_by_name VARCHAR(100) NOT NULL DEFAULT '',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    applied_at DATETIME NULL,
+    UNIQUE KEY uk_stocktake_items_asset (stocktake_id, asset_id),
+    KEY idx_stocktake_items_asset_id (asset_id)
+);
@@ -0,0 +1,6 @@
+DROP INDEX uk_reminder_log_custody ON reminder_log;
+-- 同一次提醒只保留一条记录
+DELETE l FROM reminder_log l JOIN reminder_log o
+ON o.custody_id = l.custody_id AND o.kind = l.kind AND o.due_date = l.due_date AND o.sent_at = l.sent_at AND o.id < l.id;
+ALTER TABLE reminder_log DROP COLUMN channel;
+CREATE INDEX idx_reminder_log_custody ON reminder_log(custody_id, kind, due_date, sent_at);
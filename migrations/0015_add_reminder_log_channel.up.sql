@@ -0,0 +1,11 @@
+-- 到期提醒按渠道分别记录，只重试发送失败的渠道
+ALTER TABLE reminder_log ADD COLUMN channel VARCHAR(16) NOT NULL DEFAULT '' AFTER due_date;
+-- 原有记录不区分渠道，视为所有渠道都已发送，升级后不重复提醒
+INSERT INTO reminder_log (custody_id, kind, due_date, channel, sent_at)
+SELECT DISTINCT l.custody_id, l.kind, l.due_date, c.channel, l.sent_at
+FROM reminder_log l
+CROSS JOIN (SELECT 'inbox' AS channel UNION ALL SELECT 'smtp' UNION ALL SELECT 'webhook') c
+WHERE l.channel = '';
+DELETE FROM reminder_log WHERE channel = '';
+DROP INDEX idx_reminder_log_custody ON reminder_log;
+CREATE UNIQUE INDEX uk_reminder_log_custody ON reminder_log(custody_id, kind, due_date, channel, sent_at);
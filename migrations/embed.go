@@ -0,0 +1,12 @@
+// Package migrations 包含数据库结构迁移脚本。
+//
+// 文件命名规则为 <版本号>_<名称>.up.sql 和 <版本号>_<名称>.down.sql，
+// 版本号必须递增且不可修改已发布的脚本，新的表结构变更请新增文件。
+package migrations
+
+import "embed"
+
+// FS 内嵌的全部迁移脚本
+//
+//go:embed *.sql
+var FS embed.FS
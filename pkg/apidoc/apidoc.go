@@ -0,0 +1,109 @@
+// Package apidoc 提供服务端接口的 OpenAPI 3 文档。
+//
+// 文档以 openapi.json 的形式嵌入二进制，由服务器在 /api/openapi.json 提供。
+// 注册路由时逐个列出接口的方法和路径，并使用 Undocumented 检查，
+// 新增接口而忘记补充文档会导致服务启动失败。
+package apidoc
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec 返回 OpenAPI 文档的原始 JSON
+func Spec() []byte {
+	return spec
+}
+
+// Endpoint 一个接口：请求方法加路径模板，例如 GET /api/v1/assets/{id}
+type Endpoint struct {
+	Method string
+	Path   string
+}
+
+func (e Endpoint) String() string {
+	return e.Method + " " + e.Path
+}
+
+var methods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// Endpoints 返回文档中声明的全部接口，按路径和方法排序
+func Endpoints() ([]Endpoint, error) {
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("解析 OpenAPI 文档失败: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("OpenAPI 文档版本无效: %q", doc.OpenAPI)
+	}
+	var endpoints []Endpoint
+	for path, item := range doc.Paths {
+		for method := range item {
+			if methods[method] {
+				endpoints = append(endpoints, Endpoint{Method: strings.ToUpper(method), Path: path})
+			}
+		}
+	}
+	sortEndpoints(endpoints)
+	return endpoints, nil
+}
+
+// Undocumented 返回 endpoints 中文档没有声明的接口，路径和方法都要完全一致。
+// 前缀路由分发的每个子路径都要单独列出，例如 POST /api/v1/assets/{id}/checkout
+func Undocumented(endpoints []Endpoint) ([]Endpoint, error) {
+	documented, err := Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[Endpoint]bool, len(documented))
+	for _, e := range documented {
+		known[e] = true
+	}
+	var missing []Endpoint
+	for _, e := range endpoints {
+		if !known[e] {
+			missing = append(missing, e)
+		}
+	}
+	return missing, nil
+}
+
+// Unregistered 返回文档中声明了、但 endpoints 中没有的接口，用于发现过时的文档
+func Unregistered(endpoints []Endpoint) ([]Endpoint, error) {
+	documented, err := Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[Endpoint]bool, len(endpoints))
+	for _, e := range endpoints {
+		registered[e] = true
+	}
+	var missing []Endpoint
+	for _, e := range documented {
+		if !registered[e] {
+			missing = append(missing, e)
+		}
+	}
+	return missing, nil
+}
+
+func sortEndpoints(endpoints []Endpoint) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+}
@@ -0,0 +1,63 @@
+package apidoc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEndpoints(t *testing.T) {
+	endpoints, err := Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[Endpoint]bool{}
+	for _, e := range endpoints {
+		seen[e] = true
+	}
+	for _, want := range []Endpoint{
+		{"GET", "/api/v1/assets/{id}"},
+		{"PATCH", "/api/v1/assets/{id}"},
+		{"POST", "/api/v1/stocktakes/{id}/find"},
+		{"POST", "/logout"},
+	} {
+		if !seen[want] {
+			t.Errorf("文档缺少 %s", want)
+		}
+	}
+}
+
+func TestUndocumented(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []Endpoint
+		want      []Endpoint
+	}{
+		{"已声明", []Endpoint{{"GET", "/api/v1/assets"}, {"POST", "/api/v1/assets/{id}/checkout"}}, nil},
+		{"方法不一致", []Endpoint{{"GET", "/logout"}}, []Endpoint{{"GET", "/logout"}}},
+		{"未声明的子路径", []Endpoint{{"POST", "/api/v1/assets/{id}/bogus"}}, []Endpoint{{"POST", "/api/v1/assets/{id}/bogus"}}},
+		{"前缀不再视为覆盖子路径", []Endpoint{{"GET", "/api/v1/assets/"}}, []Endpoint{{"GET", "/api/v1/assets/"}}},
+	}
+	for _, tt := range tests {
+		got, err := Undocumented(tt.endpoints)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnregistered(t *testing.T) {
+	all, err := Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unregistered(all[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, all[:1]) {
+		t.Errorf("未注册的接口 %v，期望 %v", got, all[:1])
+	}
+}
@@ -0,0 +1,539 @@
+// Package config 加载并校验资产管理系统的运行配置。
+//
+// 配置按以下优先级合并（后者覆盖前者）：内置默认值、YAML 配置文件、
+// AMS_ 前缀的环境变量、命令行参数。只有部分配置项可以通过环境变量和命令行参数覆盖，
+// 环境变量见 applyEnv，命令行参数见 Load。
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath 未指定 -config 时尝试读取的配置文件
+const DefaultPath = "config.yaml"
+
+// Config 系统配置
+type Config struct {
+	Database  DatabaseConfig  `yaml:"database"`
+	Server    ServerConfig    `yaml:"server"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Session   SessionConfig   `yaml:"session"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Reminders RemindersConfig `yaml:"reminders"`
+	Reports   ReportsConfig   `yaml:"reports"`
+	Printers  PrintersConfig  `yaml:"printers"`
+}
+
+// DatabaseConfig 数据库连接配置
+type DatabaseConfig struct {
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// QueryTimeout 单次请求内数据库操作的超时时间
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	// ImportTimeout 批量导入（含试运行校验）的数据库操作超时时间，导入在一个事务中写入全部行，耗时远超普通请求
+	ImportTimeout time.Duration `yaml:"import_timeout"`
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Addr        string `yaml:"addr"`
+	TemplateDir string `yaml:"template_dir"`
+	StaticDir   string `yaml:"static_dir"`
+	// PublicURL 用户访问系统的地址，如 https://assets.example.com，用于资产标签上的链接；
+	// 为空时使用请求中的地址
+	PublicURL string `yaml:"public_url"`
+}
+
+// CacheConfig 资产列表缓存配置
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// RefreshInterval 定时从数据库重新加载缓存的间隔，0 表示只在数据变更时刷新
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// SessionConfig 登录会话配置
+type SessionConfig struct {
+	// Store 会话存储：memory（单实例）或 mysql（多实例共享）
+	Store      string `yaml:"store"`
+	CookieName string `yaml:"cookie_name"`
+	// Secret 签名 cookie 的密钥，至少 32 字节
+	Secret string `yaml:"secret"`
+	// Secure 仅通过 HTTPS 发送 cookie，生产环境应开启
+	Secure bool `yaml:"secure"`
+	// SameSite 取值 lax、strict 或 none
+	SameSite        string        `yaml:"same_site"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	AbsoluteTimeout time.Duration `yaml:"absolute_timeout"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+}
+
+// AuthConfig 认证中间件配置
+type AuthConfig struct {
+	// PublicPaths 无需登录即可访问的路径，以 / 结尾表示前缀匹配，否则精确匹配
+	PublicPaths []string `yaml:"public_paths"`
+}
+
+// RemindersConfig 到期归还提醒配置。服务进程按 Interval 扫描未归还且设置了预计归还日期的资产，
+// 通过已配置的渠道提醒保管人和负责该部门的保管员
+type RemindersConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"`
+	// DueSoonDays 预计归还日期前多少天发送一次即将到期提醒，0 表示只提醒逾期
+	DueSoonDays int `yaml:"due_soon_days"`
+	// OverdueRepeat 逾期后重复提醒的间隔
+	OverdueRepeat time.Duration `yaml:"overdue_repeat"`
+	// NotifyManagers 同时提醒拥有保管权限且部门范围包含该资产的用户
+	NotifyManagers bool `yaml:"notify_managers"`
+	// Inbox 写入站内消息
+	Inbox   bool          `yaml:"inbox"`
+	SMTP    SMTPConfig    `yaml:"smtp"`
+	Webhook WebhookConfig `yaml:"webhook"`
+}
+
+// SMTPConfig 提醒邮件配置，Addr 为空表示不发送邮件
+type SMTPConfig struct {
+	// Addr SMTP 服务器地址（host:port），服务器支持时自动使用 STARTTLS
+	Addr     string `yaml:"addr"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+	// To 每封提醒邮件额外抄送的地址，例如资产管理员的公共邮箱
+	To      []string      `yaml:"to"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// WebhookConfig 提醒 webhook 配置，URL 为空表示不调用。每条提醒以 JSON POST 到 URL
+type WebhookConfig struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ReportsConfig PDF 单据和报表配置
+type ReportsConfig struct {
+	// FontPath 嵌入 PDF 的中文 TrueType 字体（.ttf，不支持 .ttc 和 .otf），为空时在常见的系统字体位置查找，
+	// 找不到时 PDF 相关功能不可用
+	FontPath string `yaml:"font_path"`
+	// Organization 单据抬头显示的单位名称
+	Organization string `yaml:"organization"`
+}
+
+// PrintersConfig 热敏标签打印机（ZPL）配置
+type PrintersConfig struct {
+	// Font 打印机上存储的中文字体，如 E:SIMSUN.TTF；为空时使用打印机内置字体，标签上的中文无法打印
+	Font string `yaml:"font"`
+	// TemplateDir 自定义 ZPL 模板目录，文件名为 <名称>-<宽>x<高>.zpl，为空表示只使用内置模板
+	TemplateDir string `yaml:"template_dir"`
+	// Timeout 连接并发送到打印机的超时时间
+	Timeout time.Duration `yaml:"timeout"`
+	// Devices 可以从系统直接打印的打印机，只能发送到这里列出的地址
+	Devices []PrinterConfig `yaml:"devices"`
+}
+
+// PrinterConfig 一台通过 raw TCP 接收 ZPL 的打印机
+type PrinterConfig struct {
+	Name string `yaml:"name"`
+	// Addr 打印机地址 host:port，省略端口时为 9100
+	Addr string `yaml:"addr"`
+	// DPI 打印机分辨率，203 或 300，默认 203
+	DPI int `yaml:"dpi"`
+}
+
+// MinSessionSecretLength 会话密钥最小长度
+const MinSessionSecretLength = 32
+
+// Default 返回内置默认配置（不包含数据库 DSN，必须显式配置）
+func Default() *Config {
+	return &Config{
+		Database: DatabaseConfig{
+			MaxOpenConns:    100,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: time.Hour,
+			QueryTimeout:    5 * time.Second,
+			ImportTimeout:   2 * time.Minute,
+		},
+		Server: ServerConfig{
+			Addr:        ":8080",
+			TemplateDir: "static/templates",
+			StaticDir:   "static",
+		},
+		Cache: CacheConfig{
+			Enabled: true,
+		},
+		Session: SessionConfig{
+			Store:           "mysql",
+			CookieName:      "ams_session",
+			SameSite:        "lax",
+			IdleTimeout:     30 * time.Minute,
+			AbsoluteTimeout: 12 * time.Hour,
+			CleanupInterval: 10 * time.Minute,
+		},
+		Auth: AuthConfig{
+			PublicPaths: []string{"/login", "/static/"},
+		},
+		Reminders: RemindersConfig{
+			Enabled:        true,
+			Interval:       time.Hour,
+			DueSoonDays:    2,
+			OverdueRepeat:  24 * time.Hour,
+			NotifyManagers: true,
+			Inbox:          true,
+			SMTP:           SMTPConfig{Timeout: 10 * time.Second},
+			Webhook:        WebhookConfig{Timeout: 10 * time.Second},
+		},
+		Printers: PrintersConfig{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Load 解析命令行参数并加载配置，args 通常为 os.Args[1:]。
+// 解析后剩余的位置参数通过 FlagSet.Args() 获取。
+//
+// 可用的命令行参数：-config、-dsn、-addr 以及连接池参数 -max-open-conns、-max-idle-conns、
+// -conn-max-lifetime、-query-timeout、-import-timeout，只有显式指定的参数才覆盖配置文件和环境变量。
+// Load 不检查模板、静态文件等目录是否存在，只有 HTTP 服务需要这些目录，见 CheckPaths
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+
+	configPath := fs.String("config", "", "配置文件路径（默认读取 "+DefaultPath+"，不存在则跳过）")
+	dsn := fs.String("dsn", "", "数据库 DSN，覆盖配置文件")
+	addr := fs.String("addr", "", "HTTP 监听地址，覆盖配置文件")
+	maxOpenConns := fs.Int("max-open-conns", cfg.Database.MaxOpenConns, "数据库最大连接数，覆盖配置文件")
+	maxIdleConns := fs.Int("max-idle-conns", cfg.Database.MaxIdleConns, "数据库最大空闲连接数，覆盖配置文件")
+	connMaxLifetime := fs.Duration("conn-max-lifetime", cfg.Database.ConnMaxLifetime, "数据库连接最长存活时间，覆盖配置文件")
+	queryTimeout := fs.Duration("query-timeout", cfg.Database.QueryTimeout, "单次请求内数据库操作的超时时间，覆盖配置文件")
+	importTimeout := fs.Duration("import-timeout", cfg.Database.ImportTimeout, "批量导入的数据库操作超时时间，覆盖配置文件")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	path := *configPath
+	if path == "" {
+		path = os.Getenv("AMS_CONFIG")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(DefaultPath); err == nil {
+		if err := cfg.loadFile(DefaultPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if *dsn != "" {
+		cfg.Database.DSN = *dsn
+	}
+	if *addr != "" {
+		cfg.Server.Addr = *addr
+	}
+	if set["max-open-conns"] {
+		cfg.Database.MaxOpenConns = *maxOpenConns
+	}
+	if set["max-idle-conns"] {
+		cfg.Database.MaxIdleConns = *maxIdleConns
+	}
+	if set["conn-max-lifetime"] {
+		cfg.Database.ConnMaxLifetime = *connMaxLifetime
+	}
+	if set["query-timeout"] {
+		cfg.Database.QueryTimeout = *queryTimeout
+	}
+	if set["import-timeout"] {
+		cfg.Database.ImportTimeout = *importTimeout
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv 使用环境变量覆盖配置，只支持下面列出的变量
+func (c *Config) applyEnv() error {
+	stringVars := map[string]*string{
+		"AMS_DB_DSN":         &c.Database.DSN,
+		"AMS_SERVER_ADDR":    &c.Server.Addr,
+		"AMS_TEMPLATE_DIR":   &c.Server.TemplateDir,
+		"AMS_STATIC_DIR":     &c.Server.StaticDir,
+		"AMS_PUBLIC_URL":     &c.Server.PublicURL,
+		"AMS_SESSION_STORE":  &c.Session.Store,
+		"AMS_SESSION_SECRET": &c.Session.Secret,
+		"AMS_SMTP_ADDR":      &c.Reminders.SMTP.Addr,
+		"AMS_SMTP_USERNAME":  &c.Reminders.SMTP.Username,
+		"AMS_SMTP_PASSWORD":  &c.Reminders.SMTP.Password,
+		"AMS_SMTP_FROM":      &c.Reminders.SMTP.From,
+		"AMS_WEBHOOK_URL":    &c.Reminders.Webhook.URL,
+		"AMS_REPORT_FONT":    &c.Reports.FontPath,
+		"AMS_REPORT_ORG":     &c.Reports.Organization,
+	}
+	for name, target := range stringVars {
+		if value, ok := os.LookupEnv(name); ok {
+			*target = value
+		}
+	}
+
+	intVars := map[string]*int{
+		"AMS_DB_MAX_OPEN_CONNS":       &c.Database.MaxOpenConns,
+		"AMS_DB_MAX_IDLE_CONNS":       &c.Database.MaxIdleConns,
+		"AMS_REMINDERS_DUE_SOON_DAYS": &c.Reminders.DueSoonDays,
+	}
+	for name, target := range intVars {
+		if value, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("环境变量 %s 不是有效整数: %q", name, value)
+			}
+			*target = n
+		}
+	}
+
+	durationVars := map[string]*time.Duration{
+		"AMS_DB_CONN_MAX_LIFETIME":   &c.Database.ConnMaxLifetime,
+		"AMS_DB_QUERY_TIMEOUT":       &c.Database.QueryTimeout,
+		"AMS_DB_IMPORT_TIMEOUT":      &c.Database.ImportTimeout,
+		"AMS_CACHE_REFRESH_INTERVAL": &c.Cache.RefreshInterval,
+		"AMS_REMINDERS_INTERVAL":     &c.Reminders.Interval,
+	}
+	for name, target := range durationVars {
+		if value, ok := os.LookupEnv(name); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("环境变量 %s 不是有效时长（如 30s、5m）: %q", name, value)
+			}
+			*target = d
+		}
+	}
+
+	if value, ok := os.LookupEnv("AMS_CACHE_ENABLED"); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("环境变量 AMS_CACHE_ENABLED 不是有效布尔值: %q", value)
+		}
+		c.Cache.Enabled = b
+	}
+	if value, ok := os.LookupEnv("AMS_SESSION_SECURE"); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("环境变量 AMS_SESSION_SECURE 不是有效布尔值: %q", value)
+		}
+		c.Session.Secure = b
+	}
+	if value, ok := os.LookupEnv("AMS_REMINDERS_ENABLED"); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("环境变量 AMS_REMINDERS_ENABLED 不是有效布尔值: %q", value)
+		}
+		c.Reminders.Enabled = b
+	}
+	return nil
+}
+
+// Validate 校验配置，返回包含所有问题的错误
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Database.DSN == "" {
+		problems = append(problems, "database.dsn 未配置（可使用配置文件、AMS_DB_DSN 或 -dsn 指定）")
+	} else if _, err := mysql.ParseDSN(c.Database.DSN); err != nil {
+		problems = append(problems, fmt.Sprintf("database.dsn 格式错误: %v", err))
+	}
+	if c.Database.MaxOpenConns < 1 {
+		problems = append(problems, "database.max_open_conns 必须大于 0")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "database.max_idle_conns 必须在 0 到 max_open_conns 之间")
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		problems = append(problems, "database.conn_max_lifetime 不能为负数")
+	}
+	if c.Database.QueryTimeout <= 0 {
+		problems = append(problems, "database.query_timeout 必须大于 0")
+	}
+	if c.Database.ImportTimeout <= 0 {
+		problems = append(problems, "database.import_timeout 必须大于 0")
+	}
+
+	if c.Server.Addr == "" {
+		problems = append(problems, "server.addr 不能为空")
+	}
+	if c.Server.TemplateDir == "" || c.Server.StaticDir == "" {
+		problems = append(problems, "server.template_dir 和 static_dir 不能为空")
+	}
+	if c.Server.PublicURL != "" {
+		if u, err := url.Parse(c.Server.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("server.public_url 必须是 http 或 https 地址: %q", c.Server.PublicURL))
+		}
+	}
+
+	if c.Cache.RefreshInterval < 0 {
+		problems = append(problems, "cache.refresh_interval 不能为负数")
+	}
+
+	if c.Session.Store != "memory" && c.Session.Store != "mysql" {
+		problems = append(problems, fmt.Sprintf("session.store 只能是 memory 或 mysql: %q", c.Session.Store))
+	}
+	if c.Session.CookieName == "" {
+		problems = append(problems, "session.cookie_name 不能为空")
+	}
+	if c.Session.Secret != "" && len(c.Session.Secret) < MinSessionSecretLength {
+		problems = append(problems, fmt.Sprintf("session.secret 长度不能少于 %d 字节", MinSessionSecretLength))
+	}
+	switch c.Session.SameSite {
+	case "lax", "strict":
+	case "none":
+		if !c.Session.Secure {
+			problems = append(problems, "session.same_site 为 none 时必须开启 session.secure")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("session.same_site 只能是 lax、strict 或 none: %q", c.Session.SameSite))
+	}
+	if c.Session.IdleTimeout <= 0 || c.Session.AbsoluteTimeout <= 0 || c.Session.CleanupInterval <= 0 {
+		problems = append(problems, "session.idle_timeout、absolute_timeout 和 cleanup_interval 必须大于 0")
+	} else if c.Session.IdleTimeout > c.Session.AbsoluteTimeout {
+		problems = append(problems, "session.idle_timeout 不能大于 absolute_timeout")
+	}
+
+	for _, p := range c.Auth.PublicPaths {
+		if !strings.HasPrefix(p, "/") {
+			problems = append(problems, fmt.Sprintf("auth.public_paths 中的路径必须以 / 开头: %q", p))
+		}
+	}
+
+	if c.Reminders.Enabled {
+		problems = append(problems, c.Reminders.validate()...)
+	}
+
+	if c.Reports.FontPath != "" && !strings.EqualFold(filepath.Ext(c.Reports.FontPath), ".ttf") {
+		problems = append(problems, fmt.Sprintf("reports.font_path 必须是 .ttf 字体: %q", c.Reports.FontPath))
+	}
+
+	problems = append(problems, c.Printers.validate()...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("配置校验失败:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// CheckPaths 检查 HTTP 服务用到的目录和文件是否存在。命令行工具不渲染页面，
+// 因此 Load 不做这些检查，由服务启动时调用
+func (c *Config) CheckPaths() error {
+	var problems []string
+	dirs := []struct {
+		key, path string
+	}{
+		{"server.template_dir", c.Server.TemplateDir},
+		{"server.static_dir", c.Server.StaticDir},
+		{"printers.template_dir", c.Printers.TemplateDir},
+	}
+	for _, d := range dirs {
+		if d.path == "" {
+			continue
+		}
+		if info, err := os.Stat(d.path); err != nil || !info.IsDir() {
+			problems = append(problems, fmt.Sprintf("%s 目录不存在: %q", d.key, d.path))
+		}
+	}
+	if c.Reports.FontPath != "" {
+		if info, err := os.Stat(c.Reports.FontPath); err != nil || info.IsDir() {
+			problems = append(problems, fmt.Sprintf("reports.font_path 字体文件不存在: %q", c.Reports.FontPath))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("配置校验失败:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+func (c *RemindersConfig) validate() []string {
+	var problems []string
+	if c.Interval <= 0 || c.OverdueRepeat <= 0 {
+		problems = append(problems, "reminders.interval 和 overdue_repeat 必须大于 0")
+	}
+	if c.DueSoonDays < 0 {
+		problems = append(problems, "reminders.due_soon_days 不能为负数")
+	}
+	if c.SMTP.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.SMTP.Addr); err != nil {
+			problems = append(problems, fmt.Sprintf("reminders.smtp.addr 应为 host:port: %q", c.SMTP.Addr))
+		}
+		if _, err := mail.ParseAddress(c.SMTP.From); err != nil {
+			problems = append(problems, fmt.Sprintf("reminders.smtp.from 不是有效的邮箱地址: %q", c.SMTP.From))
+		}
+		for _, to := range c.SMTP.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				problems = append(problems, fmt.Sprintf("reminders.smtp.to 中的邮箱地址无效: %q", to))
+			}
+		}
+		if c.SMTP.Timeout <= 0 {
+			problems = append(problems, "reminders.smtp.timeout 必须大于 0")
+		}
+	}
+	if c.Webhook.URL != "" {
+		if u, err := url.Parse(c.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("reminders.webhook.url 必须是 http 或 https 地址: %q", c.Webhook.URL))
+		}
+		if c.Webhook.Timeout <= 0 {
+			problems = append(problems, "reminders.webhook.timeout 必须大于 0")
+		}
+	}
+	return problems
+}
+
+func (c *PrintersConfig) validate() []string {
+	var problems []string
+	if c.Timeout <= 0 {
+		problems = append(problems, "printers.timeout 必须大于 0")
+	}
+	names := make(map[string]bool)
+	for i, d := range c.Devices {
+		if d.Name == "" {
+			problems = append(problems, fmt.Sprintf("printers.devices[%d].name 不能为空", i))
+		} else if names[d.Name] {
+			problems = append(problems, fmt.Sprintf("printers.devices 中的打印机名称重复: %q", d.Name))
+		}
+		names[d.Name] = true
+		if d.Addr == "" {
+			problems = append(problems, fmt.Sprintf("printers.devices[%d].addr 不能为空", i))
+		}
+		if d.DPI != 0 && d.DPI != 203 && d.DPI != 300 {
+			problems = append(problems, fmt.Sprintf("printers.devices[%d].dpi 只能是 203 或 300", i))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDSN = "user:password@tcp(127.0.0.1:3306)/asset_management"
+
+// load 在空目录中加载配置，避免读到工作目录下的 config.yaml
+func load(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	t.Setenv("AMS_CONFIG", "")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Load(fs, args)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "database:\n  dsn: \"" + testDSN + "\"\n  max_open_conns: 50\n  query_timeout: 3s\n  import_timeout: 5m\nserver:\n  addr: \":9000\"\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AMS_DB_MAX_IDLE_CONNS", "5")
+	t.Setenv("AMS_DB_QUERY_TIMEOUT", "7s")
+
+	cfg, err := load(t, "-config", file, "-max-open-conns", "20", "-import-timeout", "10m", "-addr", ":9100", "rest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"命令行参数覆盖配置文件", cfg.Database.MaxOpenConns, 20},
+		{"-import-timeout 覆盖配置文件", cfg.Database.ImportTimeout, 10 * time.Minute},
+		{"环境变量覆盖默认值", cfg.Database.MaxIdleConns, 5},
+		{"环境变量覆盖配置文件", cfg.Database.QueryTimeout, 7 * time.Second},
+		{"未指定的参数不覆盖", cfg.Database.ConnMaxLifetime, time.Hour},
+		{"-addr", cfg.Server.Addr, ":9100"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: %v，期望 %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		err  string
+	}{
+		{"缺少 DSN", nil, nil, "database.dsn 未配置"},
+		{"环境变量不是整数", map[string]string{"AMS_DB_MAX_OPEN_CONNS": "many"}, []string{"-dsn", testDSN}, "AMS_DB_MAX_OPEN_CONNS"},
+		{"空闲连接数大于最大连接数", nil, []string{"-dsn", testDSN, "-max-open-conns", "5", "-max-idle-conns", "6"}, "max_idle_conns"},
+		{"查询超时为 0", nil, []string{"-dsn", testDSN, "-query-timeout", "0s"}, "query_timeout"},
+		{"导入超时为 0", nil, []string{"-dsn", testDSN, "-import-timeout", "0s"}, "import_timeout"},
+		{"配置文件不存在", nil, []string{"-config", "no-such.yaml"}, "no-such.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 先用 Setenv 登记测试结束后恢复，再删除变量，避免外部环境中的 DSN 影响结果
+			t.Setenv("AMS_DB_DSN", "")
+			os.Unsetenv("AMS_DB_DSN")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := load(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("错误 %v，期望包含 %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestLoadWithoutServerDirs 命令行工具在仓库目录之外运行时没有模板和静态文件目录，Load 不应失败
+func TestLoadWithoutServerDirs(t *testing.T) {
+	cfg, err := load(t, "-dsn", testDSN)
+	if err != nil {
+		t.Fatalf("Load 失败: %v", err)
+	}
+	if err := cfg.CheckPaths(); err == nil || !strings.Contains(err.Error(), "server.template_dir") {
+		t.Errorf("CheckPaths 应报告缺少模板目录，实际 %v", err)
+	}
+
+	dir := t.TempDir()
+	cfg.Server.TemplateDir, cfg.Server.StaticDir = dir, dir
+	if err := cfg.CheckPaths(); err != nil {
+		t.Errorf("目录存在时 CheckPaths 失败: %v", err)
+	}
+	cfg.Reports.FontPath = filepath.Join(dir, "missing.ttf")
+	if err := cfg.CheckPaths(); err == nil || !strings.Contains(err.Error(), "reports.font_path") {
+		t.Errorf("CheckPaths 应报告缺少字体，实际 %v", err)
+	}
+}
+
+func TestValidatePrinters(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices []PrinterConfig
+		err     string
+	}{
+		{"正常", []PrinterConfig{{Name: "A", Addr: "127.0.0.1:9100"}, {Name: "B", Addr: "127.0.0.1", DPI: 300}}, ""},
+		{"名称重复", []PrinterConfig{{Name: "A", Addr: "h1"}, {Name: "A", Addr: "h2"}}, "名称重复"},
+		{"缺少地址", []PrinterConfig{{Name: "A"}}, "addr 不能为空"},
+		{"分辨率无效", []PrinterConfig{{Name: "A", Addr: "h1", DPI: 600}}, "dpi"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.Database.DSN = testDSN
+		cfg.Printers.Devices = tt.devices
+		err := cfg.Validate()
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: 错误 %v，期望包含 %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// apiAssetsPrefix 资产资源路径，/api/v1/assets 为集合，/api/v1/assets/{id} 为单个资产
+const apiAssetsPrefix = "/api/v1/assets"
+
+// maxAPIBodyBytes 单个 JSON 请求体的大小上限
+const maxAPIBodyBytes = 1 << 20
+
+// apiError 接口统一的错误响应体
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// writeJSON 以指定状态码写入 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("编码 JSON 失败: %v", err)
+	}
+}
+
+// writeAPIError 写入 {"error": "..."} 形式的错误响应
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{Error: message})
+}
+
+// APIAssetsHandler 处理 /api/v1/assets 资源：
+//
+//	GET    /api/v1/assets          分页列表，支持 page、pageSize、query、status 参数
+//	POST   /api/v1/assets          新建资产
+//	GET    /api/v1/assets/{id}     获取单个资产
+//	PUT    /api/v1/assets/{id}     整体替换资产
+//	PATCH  /api/v1/assets/{id}     只修改请求体中出现的字段
+//	DELETE /api/v1/assets/{id}     移入回收站，可用 reason 参数说明原因
+//	GET    /api/v1/assets/{id}/history  资产的变更记录，按时间倒序
+//	POST   /api/v1/assets/{id}/transitions  按状态机变更资产状态
+//	POST   /api/v1/assets/{id}/checkout  领用或借出给保管人
+//	POST   /api/v1/assets/{id}/checkin   归还
+//	GET    /api/v1/assets/{id}/custody   资产的保管记录，按领用时间倒序
+//	GET    /api/v1/assets/{id}/receipt   保管记录的 PDF 领用交接单或归还单
+//	GET    /api/v1/assets/{id}/barcode   资产的二维码或一维条码图片（PNG）
+//	GET    /api/v1/assets/{id}/zpl       资产的热敏打印机标签（ZPL）
+//	POST   /api/v1/assets/{id}/seen      确认资产在场
+//	GET    /api/v1/assets/lookup  按扫描到的资产链接、资产编码或序列号查找资产
+//	POST   /api/v1/assets/import  从 CSV 或 XLSX 批量导入，dry_run=1 时只校验不导入
+//
+// 请求和响应都是 JSON，错误统一返回 {"error": "..."}
+func (s *Server) APIAssetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理资产接口请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, apiAssetsPrefix), "/")
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			s.apiListAssets(w, r)
+		case http.MethodPost:
+			s.apiCreateAsset(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		}
+		return
+	}
+
+	if rest == "import" {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiImportAssets(w, r)
+		return
+	}
+	if rest == "lookup" {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiLookupAsset(w, r)
+		return
+	}
+
+	idPart, sub := rest, ""
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		idPart, sub = rest[:i], rest[i+1:]
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id < 1 {
+		writeAPIError(w, http.StatusNotFound, "资产不存在")
+		return
+	}
+	switch sub {
+	case "":
+	case "history":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiAssetHistory(w, r, id)
+		return
+	case "transitions":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiTransitionAsset(w, r, id)
+		return
+	case "checkout", "checkin":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		if sub == "checkout" {
+			s.apiCheckOut(w, r, id)
+		} else {
+			s.apiCheckIn(w, r, id)
+		}
+		return
+	case "custody":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiCustodyHistory(w, r, id)
+		return
+	case "receipt":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiCustodyReceipt(w, r, id)
+		return
+	case "barcode":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiAssetBarcode(w, r, id)
+		return
+	case "zpl":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiAssetZPL(w, r, id)
+		return
+	case "seen":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiMarkSeen(w, r, id)
+		return
+	default:
+		writeAPIError(w, http.StatusNotFound, "接口不存在")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.apiGetAsset(w, r, id)
+	case http.MethodPut, http.MethodPatch:
+		s.apiUpdateAsset(w, r, id)
+	case http.MethodDelete:
+		s.apiDeleteAsset(w, r, id)
+	default:
+		w.Header().Set("Allow", "GET, PUT, PATCH, DELETE")
+		writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+	}
+}
+
+func (s *Server) apiListAssets(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	q := r.URL.Query()
+	page := 1
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeAPIError(w, http.StatusBadRequest, "page 必须是正整数")
+			return
+		}
+		page = n
+	}
+	pageSize := 20
+	if v := q.Get("pageSize"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			writeAPIError(w, http.StatusBadRequest, "pageSize 必须是 1 到 1000 之间的整数")
+			return
+		}
+		pageSize = n
+	}
+	statuses, err := parseStatusFilter(q.Get("status"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	assets, err := s.findAssets(ctx, model.ScopeFor(CurrentUser(r.Context())), q.Get("query"))
+	if err != nil {
+		log.Printf("查询资产列表失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询资产列表失败")
+		return
+	}
+	writeJSON(w, http.StatusOK, paginateAssets(filterByStatus(assets, statuses), page, pageSize))
+}
+
+func (s *Server) apiGetAsset(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	asset, ok := s.apiScopedAsset(w, r, id)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, asset)
+}
+
+func (s *Server) apiCreateAsset(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, model.PermCreateAsset) {
+		return
+	}
+	asset := &model.Asset{}
+	if !decodeJSONBody(w, r, asset) {
+		return
+	}
+	asset.ID = 0
+	if err := model.PrepareNewAsset(asset); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !model.ScopeFor(CurrentUser(r.Context())).Allows(asset) {
+		writeAPIError(w, http.StatusForbidden, "无权操作该部门的资产")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	if err := s.assets.Create(ctx, model.ActorFor(CurrentUser(r.Context())), asset); err != nil {
+		log.Printf("资产录入失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "资产录入失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("接口新建资产成功: id=%d", asset.ID)
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", apiAssetsPrefix, asset.ID))
+	writeJSON(w, http.StatusCreated, asset)
+}
+
+// apiUpdateAsset PUT 用请求体整体替换资产，PATCH 把请求体合并到现有资产上。
+// 请求体中的 version 为读取资产时的版本，期间被他人修改时返回 409；省略 version 表示不做检查
+func (s *Server) apiUpdateAsset(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermEditAsset) {
+		return
+	}
+	existing, ok := s.apiScopedAsset(w, r, id)
+	if !ok {
+		return
+	}
+
+	asset := &model.Asset{}
+	if r.Method == http.MethodPatch {
+		*asset = *existing
+		asset.Version = 0
+	}
+	if !decodeJSONBody(w, r, asset) {
+		return
+	}
+	asset.ID = id
+	// 状态只能通过 transitions 接口按状态机修改，领用人和领取部门只能通过领用/归还修改
+	if asset.Status == "" {
+		asset.Status = existing.Status
+	} else if asset.Status != existing.Status {
+		writeAPIError(w, http.StatusBadRequest, "资产状态不能直接修改，请使用 "+apiAssetsPrefix+"/{id}/transitions")
+		return
+	}
+	if asset.Recipient == "" && asset.RecipientDepartment == "" {
+		asset.Recipient, asset.RecipientDepartment = existing.Recipient, existing.RecipientDepartment
+	} else if asset.Recipient != existing.Recipient || asset.RecipientDepartment != existing.RecipientDepartment {
+		writeAPIError(w, http.StatusBadRequest, "领用人不能直接修改，请使用 "+apiAssetsPrefix+"/{id}/checkout 和 checkin")
+		return
+	}
+	if err := model.PrepareAsset(asset); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !model.ScopeFor(CurrentUser(r.Context())).Allows(asset) {
+		writeAPIError(w, http.StatusForbidden, "无权操作该部门的资产")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	err := s.assets.Update(ctx, model.ActorFor(CurrentUser(r.Context())), asset)
+	if err == model.ErrAssetNotFound {
+		writeAPIError(w, http.StatusNotFound, "资产不存在")
+		return
+	}
+	if err == model.ErrCustodyChange {
+		// 读取资产后被他人领用或归还
+		writeAPIError(w, http.StatusBadRequest, "领用人不能直接修改，请使用 "+apiAssetsPrefix+"/{id}/checkout 和 checkin")
+		return
+	}
+	var conflict *model.VersionConflictError
+	if errors.As(err, &conflict) {
+		writeConflict(w, conflict)
+		return
+	}
+	if err != nil {
+		log.Printf("资产更新失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "资产更新失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("接口更新资产成功: id=%d", id)
+	writeJSON(w, http.StatusOK, asset)
+}
+
+func (s *Server) apiDeleteAsset(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermDeleteAsset) {
+		return
+	}
+	reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+	if err := validateDeleteReason(reason); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := s.apiScopedAsset(w, r, id); !ok {
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	err := s.assets.Delete(ctx, model.ActorFor(CurrentUser(r.Context())), id, reason)
+	if err == model.ErrAssetNotFound {
+		writeAPIError(w, http.StatusNotFound, "资产不存在")
+		return
+	}
+	if err != nil {
+		log.Printf("资产删除失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "资产删除失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("接口删除资产成功，已移入回收站: id=%d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apiAssetHistory 返回资产的变更时间线
+func (s *Server) apiAssetHistory(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	if _, ok := s.apiScopedAsset(w, r, id); !ok {
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	changes, err := s.assets.History(ctx, id)
+	if err != nil {
+		log.Printf("查询资产变更记录失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询资产变更记录失败")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"history": changes})
+}
+
+// assetConflict 409 响应体：当前内容和提交的版本之后他人所做的修改
+type assetConflict struct {
+	Error   string              `json:"error"`
+	Current *model.Asset        `json:"current"`
+	Changes []model.AssetChange `json:"changes"`
+}
+
+// writeConflict 写入 409 响应，表单和 JSON 接口共用
+func writeConflict(w http.ResponseWriter, conflict *model.VersionConflictError) {
+	changes := conflict.Changes
+	if changes == nil {
+		changes = []model.AssetChange{}
+	}
+	writeJSON(w, http.StatusConflict, assetConflict{
+		Error:   "资产在编辑期间已被他人修改，请查看最新内容后重新提交",
+		Current: conflict.Current,
+		Changes: changes,
+	})
+}
+
+// apiScopedAsset 获取用户范围内的资产，范围外的资产按不存在处理；失败时写入错误响应并返回 false
+func (s *Server) apiScopedAsset(w http.ResponseWriter, r *http.Request, id int) (*model.Asset, bool) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	asset, err := s.assets.Get(ctx, id)
+	if err == model.ErrAssetNotFound || (err == nil && !model.ScopeFor(CurrentUser(r.Context())).Allows(asset)) {
+		writeAPIError(w, http.StatusNotFound, "资产不存在")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("查询资产详情失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询资产详情失败")
+		return nil, false
+	}
+	return asset, true
+}
+
+// decodeJSONBody 把请求体解码到 v，要求 Content-Type 为 application/json 且不含未知字段；
+// 失败时写入错误响应并返回 false。限定 Content-Type 同时避免跨站表单提交写接口
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeAPIError(w, http.StatusUnsupportedMediaType, "请求体必须是 application/json")
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, "请求体过大")
+			return false
+		}
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("无效的 JSON: %v", err))
+		return false
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		writeAPIError(w, http.StatusBadRequest, "请求体只能包含一个 JSON 对象")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func testAssets() *fakeAssets {
+	return newFakeAssets(
+		model.Asset{ID: 1, Name: "笔记本电脑", SerialNumber: "SN-1", AssetCode: "ZC-1", Category: "笔记本", Brand: "联想", Supplier: "京东", Department: "IT", Location: "北京总部 5F", CreatedAt: "2026-01-01"},
+		model.Asset{ID: 2, Name: "投影仪", SerialNumber: "SN-2", AssetCode: "ZC-2", Category: "投影仪", Brand: "爱普生", Supplier: "京东", Department: "HR", Location: "上海", CreatedAt: "2026-01-02"},
+	)
+}
+
+func TestAPIListAssetsScope(t *testing.T) {
+	s := newTestServer(testAssets())
+	tests := []struct {
+		user *model.User
+		want []int
+	}{
+		{testAdmin, []int{2, 1}},
+		{testLead, []int{1}},
+	}
+	for _, tt := range tests {
+		w := serve(t, s.APIAssetsHandler, tt.user, http.MethodGet, "/api/v1/assets", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: 状态码 %d，期望 200: %s", tt.user.Username, w.Code, w.Body.String())
+		}
+		var page assetPage
+		decodeResponse(t, w, &page)
+		var got []int
+		for _, a := range page.Assets {
+			got = append(got, a.ID)
+		}
+		if len(got) != len(tt.want) || page.Total != len(tt.want) {
+			t.Fatalf("%s: 资产 %v，期望 %v", tt.user.Username, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: 资产 %v，期望 %v", tt.user.Username, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestAPIGetAsset(t *testing.T) {
+	s := newTestServer(testAssets())
+	tests := []struct {
+		name   string
+		user   *model.User
+		target string
+		want   int
+	}{
+		{"存在", testAdmin, "/api/v1/assets/1", http.StatusOK},
+		{"不存在", testAdmin, "/api/v1/assets/9", http.StatusNotFound},
+		{"ID 无效", testAdmin, "/api/v1/assets/abc", http.StatusNotFound},
+		{"范围外按不存在处理", testLead, "/api/v1/assets/2", http.StatusNotFound},
+		{"未知子资源", testAdmin, "/api/v1/assets/1/bogus", http.StatusNotFound},
+		{"未登录", nil, "/api/v1/assets/1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		w := serve(t, s.APIAssetsHandler, tt.user, http.MethodGet, tt.target, "")
+		if w.Code != tt.want {
+			t.Errorf("%s: 状态码 %d，期望 %d: %s", tt.name, w.Code, tt.want, w.Body.String())
+		}
+	}
+}
+
+func TestAPICreateAsset(t *testing.T) {
+	tests := []struct {
+		name string
+		user *model.User
+		body string
+		want int
+	}{
+		{"成功", testManager, `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT"}`, http.StatusCreated},
+		{"缺少名称", testAdmin, `{"serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT"}`, http.StatusBadRequest},
+		{"未知字段", testAdmin, `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT","color":"黑"}`, http.StatusBadRequest},
+		{"日期格式错误", testAdmin, `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT","order_date":"2026/01/01"}`, http.StatusBadRequest},
+		{"范围外部门", testManager, `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"HR"}`, http.StatusForbidden},
+		{"初始状态不能是已退役", testAdmin, `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT","status":"retired"}`, http.StatusBadRequest},
+		{"有领用人时不能在库", testAdmin, `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT","recipient":"张三","recipient_department":"IT","status":"in_stock"}`, http.StatusBadRequest},
+		{"没有新建权限", testLead, `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT"}`, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		assets := testAssets()
+		s := newTestServer(assets)
+		w := serve(t, s.APIAssetsHandler, tt.user, http.MethodPost, "/api/v1/assets", tt.body)
+		if w.Code != tt.want {
+			t.Errorf("%s: 状态码 %d，期望 %d: %s", tt.name, w.Code, tt.want, w.Body.String())
+			continue
+		}
+		if tt.want != http.StatusCreated {
+			if len(assets.assets) != 2 {
+				t.Errorf("%s: 失败的请求新建了资产", tt.name)
+			}
+			continue
+		}
+		if loc := w.Header().Get("Location"); loc != "/api/v1/assets/3" {
+			t.Errorf("%s: Location 为 %q", tt.name, loc)
+		}
+		if a, err := assets.Get(context.Background(), 3); err != nil || a.Name != "显示器" || a.Status != model.StatusInStock {
+			t.Errorf("%s: 保存的资产 %+v, %v", tt.name, a, err)
+		}
+	}
+}
+
+func TestAPIUpdateAsset(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"PATCH 修改所在地", http.MethodPatch, `{"location":"北京总部 6F","version":1}`, http.StatusOK},
+		{"版本过期", http.MethodPatch, `{"location":"北京总部 6F","version":5}`, http.StatusConflict},
+		{"直接修改状态", http.MethodPatch, `{"status":"disposed"}`, http.StatusBadRequest},
+		{"直接修改领用人", http.MethodPatch, `{"recipient":"张三","recipient_department":"IT"}`, http.StatusBadRequest},
+		{"PUT 缺少必填字段", http.MethodPut, `{"location":"北京"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		assets := testAssets()
+		s := newTestServer(assets)
+		w := serve(t, s.APIAssetsHandler, testAdmin, tt.method, "/api/v1/assets/1", tt.body)
+		if w.Code != tt.want {
+			t.Errorf("%s: 状态码 %d，期望 %d: %s", tt.name, w.Code, tt.want, w.Body.String())
+			continue
+		}
+		a, _ := assets.Get(context.Background(), 1)
+		if changed := a.Location != "北京总部 5F"; changed != (tt.want == http.StatusOK) {
+			t.Errorf("%s: 所在地为 %q", tt.name, a.Location)
+		}
+	}
+}
+
+func TestAPIDeleteAsset(t *testing.T) {
+	assets := testAssets()
+	s := newTestServer(assets)
+	if w := serve(t, s.APIAssetsHandler, testManager, http.MethodDelete, "/api/v1/assets/2", ""); w.Code != http.StatusNotFound {
+		t.Errorf("删除范围外资产: 状态码 %d，期望 404", w.Code)
+	}
+	if w := serve(t, s.APIAssetsHandler, testLead, http.MethodDelete, "/api/v1/assets/1", ""); w.Code != http.StatusForbidden {
+		t.Errorf("没有删除权限: 状态码 %d，期望 403", w.Code)
+	}
+	if w := serve(t, s.APIAssetsHandler, testManager, http.MethodDelete, "/api/v1/assets/1?reason=报废", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("删除资产: 状态码 %d，期望 204: %s", w.Code, w.Body.String())
+	}
+	if w := serve(t, s.APIAssetsHandler, testAdmin, http.MethodGet, "/api/v1/assets/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("删除后读取: 状态码 %d，期望 404", w.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"asset-management-system/pkg/apidoc"
+	"log"
+	"net/http"
+)
+
+// OpenAPIHandler 返回 OpenAPI 3 接口文档
+func (s *Server) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理接口文档请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(apidoc.Spec())
+}
+
+// APIDocsHandler 渲染接口文档页面，页面从 /api/openapi.json 读取文档，不依赖外部资源
+func (s *Server) APIDocsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理接口文档页面请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if r.Method != http.MethodGet {
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.apiDocsTemplate.Execute(w, nil); err != nil {
+		log.Printf("渲染接口文档页面失败: %v", err)
+		http.Error(w, "渲染接口文档页面失败", http.StatusInternalServerError)
+	}
+}
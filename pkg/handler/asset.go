@@ -0,0 +1,508 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/report"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Levenshtein 距离算法，用于模糊搜索
+func levenshteinDistance(s1, s2 string) int {
+	if len(s1) == 0 {
+		return len(s2)
+	}
+	if len(s2) == 0 {
+		return len(s1)
+	}
+
+	matrix := make([][]int, len(s1)+1)
+	for i := range matrix {
+		matrix[i] = make([]int, len(s2)+1)
+	}
+
+	for i := 0; i <= len(s1); i++ {
+		matrix[i][0] = i
+	}
+	for j := 0; j <= len(s2); j++ {
+		matrix[0][j] = j
+	}
+
+	for i := 1; i <= len(s1); i++ {
+		for j := 1; j <= len(s2); j++ {
+			if s1[i-1] == s2[j-1] {
+				matrix[i][j] = matrix[i-1][j-1]
+			} else {
+				min := matrix[i-1][j] + 1
+				if matrix[i][j-1]+1 < min {
+					min = matrix[i][j-1] + 1
+				}
+				if matrix[i-1][j-1]+1 < min {
+					min = matrix[i-1][j-1] + 1
+				}
+				matrix[i][j] = min
+			}
+		}
+	}
+	return matrix[len(s1)][len(s2)]
+}
+
+// AssetEntryHandler 处理资产录入页面
+func (s *Server) AssetEntryHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理资产录入请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if r.Method == "GET" {
+		if !s.authorize(w, r, model.PermViewAsset) {
+			return
+		}
+		log.Println("加载资产录入和列表页面")
+		user := CurrentUser(r.Context())
+		data := struct {
+			CreatedAt         string
+			User              *model.User
+			Perms             pagePermissions
+			Statuses          []model.AssetStatus
+			StatusLabels      map[model.AssetStatus]string
+			StatusTransitions map[model.AssetStatus][]model.AssetStatus
+			Conditions        []model.CustodyCondition
+			ConditionLabels   map[model.CustodyCondition]string
+			LabelLayouts      []report.LabelLayout
+		}{
+			CreatedAt:         time.Now().Format("2006-01-02"),
+			User:              user,
+			Perms:             permissionsFor(user),
+			Statuses:          model.Statuses(),
+			StatusLabels:      statusLabels(),
+			StatusTransitions: statusTransitions(),
+			Conditions:        model.Conditions(),
+			ConditionLabels:   conditionLabels(),
+			LabelLayouts:      report.LabelLayouts(),
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := s.assetEntryFullTemplate.Execute(w, data); err != nil {
+			log.Printf("渲染模板失败: %v", err)
+			http.Error(w, "渲染模板失败", http.StatusInternalServerError)
+		} else {
+			log.Println("模板渲染成功")
+		}
+	}
+
+	if r.Method == "POST" {
+		log.Println("处理资产录入 POST 请求")
+		r.ParseForm()
+		perm := model.PermCreateAsset
+		if r.FormValue("action") == "edit" {
+			perm = model.PermEditAsset
+		}
+		if !s.authorize(w, r, perm) {
+			return
+		}
+		serialNumber := r.FormValue("serialNumber")
+		name := r.FormValue("name")
+		category := r.FormValue("category")
+		brand := r.FormValue("brand")
+		applicationDateStr := r.FormValue("applicationDate")
+		specification := r.FormValue("specification")
+		assetCode := r.FormValue("assetCode")
+		orderDateStr := r.FormValue("orderDate")
+		createdAtStr := r.FormValue("createdAt")
+		department := r.FormValue("department")
+		location := r.FormValue("location")
+		supplier := r.FormValue("supplier")
+		recipient := r.FormValue("recipient")
+		recipientDepartment := r.FormValue("recipient_department")
+		expectedReturnDateStr := r.FormValue("expectedReturnDate")
+		remarks := r.FormValue("remarks")
+
+		log.Printf("接收到表单数据: serial_number=%s, name=%s, ...", serialNumber, name)
+
+		// 表单验证
+		if err := model.ValidateAssetFields(&model.Asset{
+			SerialNumber: serialNumber, Name: name, Category: category, Brand: brand, Department: department,
+			Location: location, Supplier: supplier, Recipient: recipient, RecipientDepartment: recipientDepartment,
+		}); err != nil {
+			log.Printf("表单验证失败: %v", err)
+			http.Error(w, fmt.Sprintf("表单验证失败: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		// 转换为日期（使用字符串格式存储到数据库）
+		var applicationDateStrSQL, orderDateStrSQL, createdAtStrSQL, expectedReturnDateStrSQL string
+		if applicationDateStr != "" {
+			applicationDate, err := time.Parse("2006-01-02", applicationDateStr)
+			if err != nil {
+				log.Printf("解析申请时间失败: %v", err)
+				http.Error(w, "申请时间格式错误", http.StatusBadRequest)
+				return
+			}
+			applicationDateStrSQL = applicationDate.Format("2006-01-02")
+		}
+		if orderDateStr != "" {
+			orderDate, err := time.Parse("2006-01-02", orderDateStr)
+			if err != nil {
+				log.Printf("解析订购日期失败: %v", err)
+				http.Error(w, "订购日期格式错误", http.StatusBadRequest)
+				return
+			}
+			orderDateStrSQL = orderDate.Format("2006-01-02")
+		}
+		if createdAtStr != "" {
+			createdAt, err := time.Parse("2006-01-02", createdAtStr)
+			if err != nil {
+				log.Printf("解析创建日期失败: %v", err)
+				http.Error(w, "创建日期格式错误", http.StatusBadRequest)
+				return
+			}
+			createdAtStrSQL = createdAt.Format("2006-01-02")
+		} else {
+			createdAtStrSQL = time.Now().Format("2006-01-02")
+		}
+		if expectedReturnDateStr != "" {
+			expectedReturnDate, err := time.Parse("2006-01-02", expectedReturnDateStr)
+			if err != nil {
+				log.Printf("解析预计归还日期失败: %v", err)
+				http.Error(w, "预计归还日期格式错误", http.StatusBadRequest)
+				return
+			}
+			expectedReturnDateStrSQL = expectedReturnDate.Format("2006-01-02")
+		}
+
+		asset := &model.Asset{
+			SerialNumber:        serialNumber,
+			Name:                name,
+			Category:            category,
+			Brand:               brand,
+			ApplicationDate:     applicationDateStrSQL,
+			Specification:       specification,
+			AssetCode:           assetCode,
+			OrderDate:           orderDateStrSQL,
+			CreatedAt:           createdAtStrSQL,
+			Department:          department,
+			Location:            location,
+			Supplier:            supplier,
+			Recipient:           recipient,
+			RecipientDepartment: recipientDepartment,
+			Remarks:             remarks,
+			ExpectedReturnDate:  expectedReturnDateStrSQL,
+		}
+
+		ctx, cancel := s.queryContext(r)
+		defer cancel()
+
+		// 只能把资产登记到自己负责的部门
+		scope := model.ScopeFor(CurrentUser(r.Context()))
+		if !scope.Allows(asset) {
+			log.Printf("资产部门超出用户范围: department=%s, recipient_department=%s", department, recipientDepartment)
+			http.Error(w, "无权操作该部门的资产", http.StatusForbidden)
+			return
+		}
+
+		action := r.FormValue("action") // 区分新建或编辑
+		if action == "edit" {
+			// 编辑现有资产
+			idStr := r.FormValue("id")
+			id, err := strconv.Atoi(idStr)
+			if err != nil {
+				log.Printf("无效的资产 ID: %v", err)
+				http.Error(w, "无效的资产 ID", http.StatusBadRequest)
+				return
+			}
+			asset.ID = id
+			// 打开编辑表单时的版本，用于检测期间是否被他人修改
+			if asset.Version, err = strconv.Atoi(r.FormValue("version")); err != nil || asset.Version < 1 {
+				log.Printf("无效的资产版本: %q", r.FormValue("version"))
+				http.Error(w, "缺少资产版本，请刷新页面后重试", http.StatusBadRequest)
+				return
+			}
+			if !s.assetInScope(ctx, w, scope, id) {
+				return
+			}
+
+			log.Println("开始更新资产数据")
+			err = s.assets.Update(ctx, model.ActorFor(CurrentUser(r.Context())), asset)
+			if err == model.ErrAssetNotFound {
+				log.Printf("资产不存在: id=%d", id)
+				http.Error(w, "资产不存在", http.StatusNotFound)
+				return
+			}
+			if err == model.ErrCustodyChange {
+				http.Error(w, "领用人不能直接修改，请使用领用和归还", http.StatusBadRequest)
+				return
+			}
+			var conflict *model.VersionConflictError
+			if errors.As(err, &conflict) {
+				log.Printf("资产编辑冲突: id=%d, 提交版本=%d, 当前版本=%d", id, asset.Version, conflict.Current.Version)
+				writeConflict(w, conflict)
+				return
+			}
+			if err != nil {
+				log.Printf("资产更新失败: %v", err)
+				http.Error(w, "资产更新失败", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			// 新建资产
+			log.Println("开始插入资产数据")
+			if err := s.assets.Create(ctx, model.ActorFor(CurrentUser(r.Context())), asset); err != nil {
+				log.Printf("资产录入失败: %v", err)
+				http.Error(w, "资产录入失败", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// 更新缓存
+		s.loadAssetCache(ctx)
+
+		log.Println("资产操作成功，刷新资产列表")
+		// 返回 JSON 响应，刷新列表
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"message": "success", "action": "%s"}`, action)
+	}
+
+	if r.Method == "DELETE" {
+		log.Println("处理资产删除请求")
+		if !s.authorize(w, r, model.PermDeleteAsset) {
+			return
+		}
+		idStr := r.URL.Query().Get("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Printf("无效的资产 ID: %v", err)
+			http.Error(w, "无效的资产 ID", http.StatusBadRequest)
+			return
+		}
+		reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+		if err := validateDeleteReason(reason); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := s.queryContext(r)
+		defer cancel()
+
+		if !s.assetInScope(ctx, w, model.ScopeFor(CurrentUser(r.Context())), id) {
+			return
+		}
+
+		log.Println("开始将资产移入回收站")
+		err = s.assets.Delete(ctx, model.ActorFor(CurrentUser(r.Context())), id, reason)
+		if err == model.ErrAssetNotFound {
+			log.Printf("资产不存在: id=%d", id)
+			http.Error(w, "资产不存在", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("资产删除失败: %v", err)
+			http.Error(w, "资产删除失败", http.StatusInternalServerError)
+			return
+		}
+
+		// 更新缓存
+		s.loadAssetCache(ctx)
+
+		log.Println("资产删除成功，刷新资产列表")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"message": "success"}`)
+	}
+}
+
+// AssetListHandler 处理资产列表页面（优化模糊搜索，使用 LIKE 和 Levenshtein 距离）
+func (s *Server) AssetListHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理资产列表请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+
+	// 获取分页和搜索参数
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20 // 默认每页 20 条
+	}
+	query := r.URL.Query().Get("query") // 模糊搜索关键字
+	statuses, err := parseStatusFilter(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	scope := model.ScopeFor(CurrentUser(r.Context()))
+
+	filteredAssets := []model.Asset{}
+
+	if idStr := r.URL.Query().Get("id"); idStr != "" {
+		// 按 ID 获取单个资产（编辑模态框使用）
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			log.Printf("无效的资产 ID: %v", err)
+			http.Error(w, "无效的资产 ID", http.StatusBadRequest)
+			return
+		}
+		log.Printf("查询资产详情，ID: %d", id)
+		asset, err := s.assets.Get(ctx, id)
+		if err == model.ErrAssetNotFound || (err == nil && !scope.Allows(asset)) {
+			log.Printf("资产不存在: id=%d", id)
+			http.Error(w, "资产不存在", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("查询资产详情失败: %v", err)
+			http.Error(w, "查询资产详情失败", http.StatusInternalServerError)
+			return
+		}
+		filteredAssets = []model.Asset{*asset}
+		page = 1
+	} else {
+		log.Printf("查询资产列表，页码: %d, 每页条数: %d, 搜索关键字: %s", page, pageSize, query)
+		assets, err := s.findAssets(ctx, scope, query)
+		if err != nil {
+			log.Printf("查询资产列表失败: %v", err)
+			http.Error(w, "查询资产列表失败", http.StatusInternalServerError)
+			return
+		}
+		filteredAssets = filterByStatus(assets, statuses)
+	}
+
+	// 返回 JSON 数据（用于 AJAX 刷新）
+	log.Println("返回资产列表 JSON 数据")
+	w.Header().Set("Content-Type", "application/json")
+	response := paginateAssets(filteredAssets, page, pageSize)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("编码 JSON 失败: %v", err)
+		http.Error(w, "编码 JSON 失败", http.StatusInternalServerError)
+	} else {
+		log.Println("JSON 数据编码成功")
+	}
+}
+
+// findAssets 返回用户范围内的资产。query 非空时先用 LIKE 快速过滤，再用 Levenshtein 距离验证相似度
+func (s *Server) findAssets(ctx context.Context, scope model.AssetScope, query string) ([]model.Asset, error) {
+	if query == "" {
+		if s.cfg.Cache.Enabled {
+			// 使用缓存
+			return scope.Filter(s.cachedAssets()), nil
+		}
+		assets, err := s.assets.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return scope.Filter(assets), nil
+	}
+
+	query = strings.ToLower(query)
+	// 首先使用 LIKE 进行快速过滤（提高性能）
+	assets, err := s.assets.Search(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("LIKE 模糊搜索失败: %w", err)
+	}
+
+	filteredAssets := []model.Asset{}
+	for _, asset := range scope.Filter(assets) {
+		if matchesQuery(&asset, query) {
+			filteredAssets = append(filteredAssets, asset)
+		}
+	}
+	return filteredAssets, nil
+}
+
+// eachAsset 逐条读取用户范围内、符合关键字和状态筛选的资产并调用 fn，匹配规则与资产列表一致。
+// 与 findAssets 不同，它不把结果载入内存，也不使用缓存，适合导出全部资产
+func (s *Server) eachAsset(ctx context.Context, scope model.AssetScope, query string, statuses []model.AssetStatus, fn func(*model.Asset) error) error {
+	query = strings.ToLower(query)
+	return s.assets.Each(ctx, query, func(a *model.Asset) error {
+		if !scope.Allows(a) || !statusIn(a.Status, statuses) || (query != "" && !matchesQuery(a, query)) {
+			return nil
+		}
+		return fn(a)
+	})
+}
+
+// matchesQuery 用 Levenshtein 距离验证 LIKE 命中的资产与关键字是否足够相似，query 需为小写
+func matchesQuery(asset *model.Asset, query string) bool {
+	fields := []string{
+		asset.SerialNumber, asset.Name, asset.Category, asset.Brand,
+		asset.Department, asset.Location, asset.Supplier, asset.Recipient,
+		asset.RecipientDepartment, asset.Remarks,
+	}
+	for _, field := range fields {
+		if field != "" {
+			distance := levenshteinDistance(strings.ToLower(field), query)
+			maxLength := len(field)
+			if maxLength > 0 && float64(distance)/float64(maxLength) < 0.3 { // 相似度阈值 0.3（可调整）
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// assetPage 分页后的资产列表响应
+type assetPage struct {
+	Assets   []model.Asset `json:"assets"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	Pages    int           `json:"pages"`
+	PageSize int           `json:"pageSize"`
+}
+
+// paginateAssets 截取第 page 页（从 1 开始），页码超出范围时返回空列表
+func paginateAssets(assets []model.Asset, page, pageSize int) assetPage {
+	total := len(assets)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return assetPage{
+		Assets:   assets[start:end],
+		Page:     page,
+		Total:    total,
+		Pages:    (total + pageSize - 1) / pageSize,
+		PageSize: pageSize,
+	}
+}
+
+// assetInScope 确认资产存在且在用户范围内，否则写入错误响应并返回 false。
+// 范围外的资产按不存在处理，不暴露其他部门的资产 ID
+func (s *Server) assetInScope(ctx context.Context, w http.ResponseWriter, scope model.AssetScope, id int) bool {
+	if scope.All {
+		return true
+	}
+	existing, err := s.assets.Get(ctx, id)
+	if err == model.ErrAssetNotFound || (err == nil && !scope.Allows(existing)) {
+		log.Printf("资产不存在或不在用户范围内: id=%d", id)
+		http.Error(w, "资产不存在", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		log.Printf("查询资产详情失败: %v", err)
+		http.Error(w, "查询资产详情失败", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// validateDeleteReason 删除原因可以为空，但不能超过数据库列宽
+func validateDeleteReason(reason string) error {
+	if utf8.RuneCountInString(reason) > 255 {
+		return fmt.Errorf("删除原因不能超过 255 个字符")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAssetEntryEditRecipient(t *testing.T) {
+	tests := []struct {
+		name                string
+		recipient, recipDep string
+		want                int
+		wantRecipient       string
+	}{
+		{"保持原领用人", "张三", "IT", http.StatusOK, "张三"},
+		{"领用人留空表示不变", "", "", http.StatusOK, "张三"},
+		{"修改领用人", "李四", "IT", http.StatusBadRequest, "张三"},
+		{"修改领取部门", "张三", "HR", http.StatusBadRequest, "张三"},
+	}
+	for _, tt := range tests {
+		assets := testAssets()
+		a := assets.assets[1]
+		a.Recipient, a.RecipientDepartment, a.Status = "张三", "IT", model.StatusInUse
+		assets.assets[1] = a
+		s := newTestServer(assets)
+		form := url.Values{
+			"action": {"edit"}, "id": {"1"}, "version": {"1"},
+			"serialNumber": {"SN-1"}, "name": {"笔记本电脑"}, "category": {"笔记本"}, "brand": {"联想"},
+			"department": {"IT"}, "location": {"北京总部 6F"}, "supplier": {"京东"},
+			"recipient": {tt.recipient}, "recipient_department": {tt.recipDep},
+		}
+		w := serveForm(t, s.AssetEntryHandler, testAdmin, "/asset-entry", form)
+		if w.Code != tt.want {
+			t.Errorf("%s: 状态码 %d，期望 %d: %s", tt.name, w.Code, tt.want, w.Body.String())
+			continue
+		}
+		got, _ := assets.Get(context.Background(), 1)
+		if got.Recipient != tt.wantRecipient {
+			t.Errorf("%s: 领用人 %q，期望 %q", tt.name, got.Recipient, tt.wantRecipient)
+		}
+		if moved := got.Location == "北京总部 6F"; moved != (tt.want == http.StatusOK) {
+			t.Errorf("%s: 所在地 %q", tt.name, got.Location)
+		}
+	}
+}
+
+func TestAssetEditConflict(t *testing.T) {
+	form := func(location string) url.Values {
+		return url.Values{
+			"action": {"edit"}, "id": {"1"}, "version": {"1"},
+			"serialNumber": {"SN-1"}, "name": {"笔记本电脑"}, "category": {"笔记本"}, "brand": {"联想"},
+			"department": {"IT"}, "location": {location}, "supplier": {"京东"},
+		}
+	}
+	tests := []struct {
+		name   string
+		submit func(s *Server, location string) *httptest.ResponseRecorder
+	}{
+		{"表单", func(s *Server, location string) *httptest.ResponseRecorder {
+			return serveForm(t, s.AssetEntryHandler, testAdmin, "/asset-entry", form(location))
+		}},
+		{"接口", func(s *Server, location string) *httptest.ResponseRecorder {
+			return serve(t, s.APIAssetsHandler, testAdmin, http.MethodPatch, "/api/v1/assets/1", `{"location":"`+location+`","version":1}`)
+		}},
+	}
+	for _, tt := range tests {
+		assets := testAssets()
+		s := newTestServer(assets)
+		// 两人打开同一版本编辑，先提交的成功，后提交的收到 409 和最新内容
+		if w := tt.submit(s, "北京总部 6F"); w.Code != http.StatusOK {
+			t.Fatalf("%s: 第一次提交: 状态码 %d: %s", tt.name, w.Code, w.Body.String())
+		}
+		w := tt.submit(s, "北京总部 7F")
+		if w.Code != http.StatusConflict {
+			t.Fatalf("%s: 版本过期: 状态码 %d，期望 409: %s", tt.name, w.Code, w.Body.String())
+		}
+		var conflict assetConflict
+		decodeResponse(t, w, &conflict)
+		if conflict.Current == nil || conflict.Current.Version != 2 || conflict.Current.Location != "北京总部 6F" || conflict.Changes == nil {
+			t.Errorf("%s: 冲突响应 %s", tt.name, w.Body.String())
+		}
+		if a, _ := assets.Get(context.Background(), 1); a.Location != "北京总部 6F" || a.Version != 2 {
+			t.Errorf("%s: 过期的提交覆盖了资产: %+v", tt.name, a)
+		}
+	}
+}
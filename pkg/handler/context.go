@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/session"
+	"context"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	sessionContextKey
+)
+
+// CurrentUser 返回请求 context 中的当前登录用户，未登录时返回 nil
+func CurrentUser(ctx context.Context) *model.User {
+	user, _ := ctx.Value(userContextKey).(*model.User)
+	return user
+}
+
+// currentSession 返回请求 context 中的当前会话，未登录时返回 nil
+func currentSession(ctx context.Context) *session.Session {
+	sess, _ := ctx.Value(sessionContextKey).(*session.Session)
+	return sess
+}
+
+// withSession 加载请求携带的会话，并把会话和对应用户放入 context。
+// 会话无效、用户不存在或已停用时按未登录处理，不拦截请求
+func (s *Server) withSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 静态文件无需会话，避免每个资源请求都查询存储
+		if strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := s.queryContext(r)
+		sess, err := s.sessions.Load(ctx, r)
+		if err != nil {
+			cancel()
+			if err != session.ErrNotFound {
+				log.Printf("加载会话失败: %v", err)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := s.users.Get(ctx, sess.UserID)
+		cancel()
+		if err != nil || !user.IsActive {
+			if err != nil && err != model.ErrUserNotFound {
+				log.Printf("加载会话用户失败: %v", err)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reqCtx := context.WithValue(r.Context(), sessionContextKey, sess)
+		reqCtx = context.WithValue(reqCtx, userContextKey, user)
+		next.ServeHTTP(w, r.WithContext(reqCtx))
+	})
+}
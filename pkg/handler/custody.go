@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/report"
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// apiCustodyPrefix 保管资源路径
+const apiCustodyPrefix = "/api/v1/custody"
+
+// checkOutRequest 领用请求体，status 为 in_use（领用，默认）或 lent_out（借出）
+type checkOutRequest struct {
+	Holder             string            `json:"holder"`
+	HolderDepartment   string            `json:"holder_department"`
+	ExpectedReturnDate string            `json:"expected_return_date"`
+	Note               string            `json:"note"`
+	Status             model.AssetStatus `json:"status"`
+	Version            int               `json:"version"`
+}
+
+// checkInRequest 归还请求体，condition 省略时为完好
+type checkInRequest struct {
+	Condition model.CustodyCondition `json:"condition"`
+	Note      string                 `json:"note"`
+	Version   int                    `json:"version"`
+}
+
+// holderSummary 保管人名下的资产数量，overdue 为超过预计归还日期仍未归还的数量
+type holderSummary struct {
+	Holder           string `json:"holder"`
+	HolderDepartment string `json:"holder_department"`
+	Count            int    `json:"count"`
+	Overdue          int    `json:"overdue"`
+}
+
+// CustodyHandler 渲染“在用资产”页面：按保管人查看当前领用的资产并办理归还
+func (s *Server) CustodyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理在用资产页面请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
+	}
+	user := CurrentUser(r.Context())
+	data := struct {
+		Perms           pagePermissions
+		Conditions      []model.CustodyCondition
+		ConditionLabels map[model.CustodyCondition]string
+		StatusLabels    map[model.AssetStatus]string
+	}{
+		Perms:           permissionsFor(user),
+		Conditions:      model.Conditions(),
+		ConditionLabels: conditionLabels(),
+		StatusLabels:    statusLabels(),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.custodyTemplate.Execute(w, data); err != nil {
+		log.Printf("渲染在用资产页面失败: %v", err)
+		http.Error(w, "渲染在用资产页面失败", http.StatusInternalServerError)
+	}
+}
+
+// APICustodyHandler 处理保管查询接口：
+//
+//	GET /api/v1/custody                  当前保管人列表及名下资产数量
+//	GET /api/v1/custody/assets?holder=   当前被领用的资产，指定 holder 时只返回该保管人的资产
+func (s *Server) APICustodyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理保管接口请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, apiCustodyPrefix), "/")
+	if rest != "" && rest != "assets" {
+		writeAPIError(w, http.StatusNotFound, "接口不存在")
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	held, err := s.assets.HeldAssets(ctx, r.URL.Query().Get("holder"))
+	if err != nil {
+		log.Printf("查询在用资产失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询在用资产失败")
+		return
+	}
+	scope := model.ScopeFor(CurrentUser(r.Context()))
+	visible := []model.HeldAsset{}
+	for _, h := range held {
+		if scope.Allows(&h.Asset) {
+			visible = append(visible, h)
+		}
+	}
+
+	if rest == "assets" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"assets": visible})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"holders": summarizeHolders(visible, time.Now().Format("2006-01-02"))})
+}
+
+// summarizeHolders 按保管人和部门汇总在用资产，按保管人排序
+func summarizeHolders(held []model.HeldAsset, today string) []holderSummary {
+	index := map[[2]string]int{}
+	holders := []holderSummary{}
+	for _, h := range held {
+		key := [2]string{h.Custody.Holder, h.Custody.HolderDepartment}
+		i, ok := index[key]
+		if !ok {
+			i = len(holders)
+			index[key] = i
+			holders = append(holders, holderSummary{Holder: key[0], HolderDepartment: key[1]})
+		}
+		holders[i].Count++
+		if h.Custody.ExpectedReturnDate != "" && h.Custody.ExpectedReturnDate < today {
+			holders[i].Overdue++
+		}
+	}
+	sort.SliceStable(holders, func(i, j int) bool {
+		if holders[i].Holder != holders[j].Holder {
+			return holders[i].Holder < holders[j].Holder
+		}
+		return holders[i].HolderDepartment < holders[j].HolderDepartment
+	})
+	return holders
+}
+
+// apiCheckOut 把资产领用或借出给保管人
+func (s *Server) apiCheckOut(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermCustody) {
+		return
+	}
+	var req checkOutRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	co := model.CheckOut{
+		Holder:             strings.TrimSpace(req.Holder),
+		HolderDepartment:   strings.TrimSpace(req.HolderDepartment),
+		ExpectedReturnDate: strings.TrimSpace(req.ExpectedReturnDate),
+		Note:               strings.TrimSpace(req.Note),
+		Status:             req.Status,
+	}
+	if err := validateCheckOut(&co, time.Now()); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	existing, ok := s.apiScopedAsset(w, r, id)
+	if !ok {
+		return
+	}
+	// 领用后的资产仍需在用户范围内，与编辑时的检查一致
+	after := *existing
+	after.RecipientDepartment = co.HolderDepartment
+	if !model.ScopeFor(CurrentUser(r.Context())).Allows(&after) {
+		writeAPIError(w, http.StatusForbidden, "无权操作该部门的资产")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	asset, err := s.assets.CheckOut(ctx, model.ActorFor(CurrentUser(r.Context())), id, co, req.Version)
+	if err != nil {
+		writeLifecycleError(w, err, "资产领用失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("资产已领用: id=%d, 保管人: %s, 部门: %s, 预计归还: %s", id, co.Holder, co.HolderDepartment, co.ExpectedReturnDate)
+	writeJSON(w, http.StatusOK, asset)
+}
+
+// apiCheckIn 归还资产并记录归还状况
+func (s *Server) apiCheckIn(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermCustody) {
+		return
+	}
+	var req checkInRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	ci := model.CheckIn{Condition: req.Condition, Note: strings.TrimSpace(req.Note)}
+	if ci.Condition == "" {
+		ci.Condition = model.ConditionGood
+	}
+	if !ci.Condition.Valid() {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("无效的归还状况: %s", ci.Condition))
+		return
+	}
+	if utf8.RuneCountInString(ci.Note) > 255 {
+		writeAPIError(w, http.StatusBadRequest, "归还说明不能超过 255 个字符")
+		return
+	}
+	if _, ok := s.apiScopedAsset(w, r, id); !ok {
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	asset, err := s.assets.CheckIn(ctx, model.ActorFor(CurrentUser(r.Context())), id, ci, req.Version)
+	if err != nil {
+		writeLifecycleError(w, err, "资产归还失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("资产已归还: id=%d, 状况: %s", id, ci.Condition)
+	writeJSON(w, http.StatusOK, asset)
+}
+
+// apiCustodyHistory 返回资产的保管记录
+func (s *Server) apiCustodyHistory(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	if _, ok := s.apiScopedAsset(w, r, id); !ok {
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	records, err := s.assets.CustodyHistory(ctx, id)
+	if err != nil {
+		log.Printf("查询资产保管记录失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询资产保管记录失败")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"custody": records})
+}
+
+// apiCustodyReceipt 生成保管记录的 PDF 单据：custody 指定保管记录，默认为当前未归还的记录，
+// 没有时为最近一次；kind 为 handover（领用交接单）或 return（归还单），默认按记录是否已归还决定
+func (s *Server) apiCustodyReceipt(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermCustody) {
+		return
+	}
+	asset, ok := s.apiScopedAsset(w, r, id)
+	if !ok {
+		return
+	}
+	if s.reports == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, errNoReports.Error())
+		return
+	}
+	var custodyID int64
+	if v := r.URL.Query().Get("custody"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			writeAPIError(w, http.StatusBadRequest, "custody 参数错误")
+			return
+		}
+		custodyID = n
+	}
+	kind := report.ReceiptKind(r.URL.Query().Get("kind"))
+	if kind != "" && !kind.Valid() {
+		writeAPIError(w, http.StatusBadRequest, "kind 只能是 handover 或 return")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	records, err := s.assets.CustodyHistory(ctx, id)
+	if err != nil {
+		log.Printf("查询资产保管记录失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询资产保管记录失败")
+		return
+	}
+	record := findCustodyRecord(records, custodyID)
+	if record == nil {
+		writeAPIError(w, http.StatusNotFound, "保管记录不存在")
+		return
+	}
+	if kind == "" {
+		kind = report.Handover
+		if !record.Open() {
+			kind = report.Return
+		}
+	}
+	if kind == report.Return && record.Open() {
+		writeAPIError(w, http.StatusConflict, "资产尚未归还，不能生成归还单")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := s.reports.Receipt(&buf, kind, asset, record, time.Now()); err != nil {
+		log.Printf("生成单据失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "生成单据失败")
+		return
+	}
+	number := kind.Number(record.ID)
+	w.Header().Set("Content-Type", "application/pdf")
+	setAttachment(w, fmt.Sprintf("%s-%s.pdf", kind.Title(), number), number+".pdf")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("写入单据失败: %v", err)
+	}
+}
+
+// findCustodyRecord 按 ID 查找保管记录；id 为 0 时返回未归还的记录，没有时返回最近一次（records 按领用时间倒序）
+func findCustodyRecord(records []model.CustodyRecord, id int64) *model.CustodyRecord {
+	for i := range records {
+		if records[i].ID == id || (id == 0 && records[i].Open()) {
+			return &records[i]
+		}
+	}
+	if id == 0 && len(records) > 0 {
+		return &records[0]
+	}
+	return nil
+}
+
+// validateCheckOut 校验领用内容，预计归还日期不能早于今天
+func validateCheckOut(co *model.CheckOut, now time.Time) error {
+	if co.Holder == "" {
+		return fmt.Errorf("保管人不能为空")
+	}
+	if co.HolderDepartment == "" {
+		return fmt.Errorf("保管人部门不能为空")
+	}
+	if utf8.RuneCountInString(co.Holder) > 100 || utf8.RuneCountInString(co.HolderDepartment) > 100 {
+		return fmt.Errorf("保管人和部门不能超过 100 个字符")
+	}
+	if utf8.RuneCountInString(co.Note) > 255 {
+		return fmt.Errorf("领用说明不能超过 255 个字符")
+	}
+	if co.Status != "" && co.Status != model.StatusInUse && co.Status != model.StatusLentOut {
+		return fmt.Errorf("领用后的状态只能是 in_use 或 lent_out")
+	}
+	if co.ExpectedReturnDate != "" {
+		t, err := time.Parse("2006-01-02", co.ExpectedReturnDate)
+		if err != nil {
+			return fmt.Errorf("预计归还日期格式错误，应为 YYYY-MM-DD")
+		}
+		if t.Format("2006-01-02") < now.Format("2006-01-02") {
+			return fmt.Errorf("预计归还日期不能早于今天")
+		}
+		co.ExpectedReturnDate = t.Format("2006-01-02")
+	}
+	return nil
+}
+
+// conditionLabels 归还状况的中文名称，供页面脚本使用
+func conditionLabels() map[model.CustodyCondition]string {
+	labels := map[model.CustodyCondition]string{}
+	for _, c := range model.Conditions() {
+		labels[c] = c.Label()
+	}
+	return labels
+}
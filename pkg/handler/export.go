@@ -0,0 +1,496 @@
+package handler
+
+import (
+	"asset-management-system/pkg/importer"
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/report"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxContentType XLSX 文件的 MIME 类型
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// idColumn 资产 ID 列，只用于导出，需要通过 columns 参数显式选择
+var idColumn = importer.Column{Field: "id", Label: "ID"}
+
+// dateColumns 导出 XLSX 时写为日期单元格的列，便于在 Excel 中排序和筛选
+var dateColumns = map[string]bool{
+	"application_date":     true,
+	"order_date":           true,
+	"created_at":           true,
+	"expected_return_date": true,
+}
+
+// assetEncoder 把资产逐条写入导出文件。实现 io.Closer 的编码器在导出结束或出错后关闭
+type assetEncoder interface {
+	// Begin 写出表头等文件开头部分
+	Begin() error
+	Encode(a *model.Asset) error
+	// End 写出文件结尾并刷新缓冲
+	End() error
+}
+
+// exportOptions 创建编码器的参数
+type exportOptions struct {
+	columns []importer.Column
+	// subtitle 和 reports 只用于 PDF 报表：标题下方的导出说明和 PDF 生成器
+	subtitle string
+	reports  *report.Generator
+}
+
+// exportFormat 一种导出格式的响应类型、默认列和编码器。columns 为空表示默认导出全部可导入的列
+type exportFormat struct {
+	contentType string
+	columns     []importer.Column
+	newEncoder  func(w io.Writer, opts exportOptions) (assetEncoder, error)
+}
+
+// exportFormats 支持的导出格式，键同时作为文件扩展名
+var exportFormats = map[string]exportFormat{
+	"xlsx":   {xlsxContentType, nil, newXLSXEncoder},
+	"csv":    {"text/csv; charset=utf-8", nil, newCSVEncoder},
+	"json":   {"application/json; charset=utf-8", nil, newJSONEncoder},
+	"ndjson": {"application/x-ndjson; charset=utf-8", nil, newNDJSONEncoder},
+	"pdf":    {"application/pdf", reportColumns, newPDFEncoder},
+}
+
+// reportColumns PDF 报表默认的列，横向 A4 放不下全部字段
+var reportColumns = func() []importer.Column {
+	var columns []importer.Column
+	for _, field := range []string{"serial_number", "asset_code", "name", "category", "brand", "status", "department", "location", "recipient", "expected_return_date"} {
+		c, _ := importer.Lookup(field)
+		columns = append(columns, c)
+	}
+	return columns
+}()
+
+// reportColumnWeights PDF 报表中较宽的列，其余列的相对宽度为 1
+var reportColumnWeights = map[string]float64{
+	"serial_number": 1.4,
+	"asset_code":    1.3,
+	"name":          1.6,
+	"specification": 1.6,
+	"remarks":       2.2,
+}
+
+// AssetExportHandler 导出当前搜索关键字和状态筛选下的全部资产（不分页）：
+//
+//	GET /assets/export?format=xlsx|csv|json|ndjson|pdf&query=...&status=...&columns=serial_number,name
+//
+// 资产从数据库逐行读取并写入响应，不会一次载入全部资产。columns 指定导出的列及顺序，
+// 可使用字段名或中文列名，默认导出全部可导入的列（PDF 报表默认导出常用的列）；
+// 列名与资产页面一致，CSV 和 XLSX 可以直接修改后重新导入。
+// CSV 和 XLSX 中以 =、+、-、@、制表符或回车开头的值前加单引号，避免打开时被当作公式执行，导入时自动去掉
+func (s *Server) AssetExportHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理资产导出请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if r.Method != http.MethodGet {
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorize(w, r, model.PermExportAsset) {
+		return
+	}
+	name := strings.ToLower(r.URL.Query().Get("format"))
+	if name == "" {
+		name = "xlsx"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("不支持的导出格式: %s（可选 %s）", name, strings.Join(exportFormatNames(), "、")), http.StatusBadRequest)
+		return
+	}
+	columns, err := parseExportColumns(r.URL.Query().Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if columns == nil {
+		columns = format.columns
+	}
+	if columns == nil {
+		columns = importer.Columns
+	}
+	statuses, err := parseStatusFilter(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query().Get("query")
+	user := CurrentUser(r.Context())
+	now := time.Now()
+
+	ew := &exportWriter{ResponseWriter: w}
+	enc, err := format.newEncoder(ew, exportOptions{
+		columns:  columns,
+		subtitle: exportSubtitle(user, query, statuses, now),
+		reports:  s.reports,
+	})
+	if err != nil {
+		log.Printf("无法导出 %s: %v", name, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	date := now.Format("2006-01-02")
+	w.Header().Set("Content-Type", format.contentType)
+	setAttachment(w, fmt.Sprintf("资产列表-%s.%s", date, name), fmt.Sprintf("assets-%s.%s", date, name))
+	if c, ok := enc.(io.Closer); ok {
+		defer c.Close()
+	}
+	// 导出耗时与资产数量有关，不使用单次查询的超时，客户端断开时随请求取消
+	count := 0
+	err = enc.Begin()
+	if err == nil {
+		err = s.eachAsset(r.Context(), model.ScopeFor(user), query, statuses, func(a *model.Asset) error {
+			count++
+			return enc.Encode(a)
+		})
+	}
+	if err == nil {
+		err = enc.End()
+	}
+	if err != nil {
+		log.Printf("导出资产失败（已处理 %d 条）: %v", count, err)
+		if !ew.written {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, "导出资产失败", http.StatusInternalServerError)
+			return
+		}
+		// 响应头已发出，中断连接，避免客户端把不完整的文件当作完整的导出结果
+		panic(http.ErrAbortHandler)
+	}
+	log.Printf("导出资产 %d 条，格式: %s, 搜索关键字: %s", count, name, query)
+}
+
+// exportWriter 记录是否已向客户端写出内容，用于判断出错时还能否返回错误状态码
+type exportWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(p)
+}
+
+func exportFormatNames() []string {
+	names := make([]string, 0, len(exportFormats))
+	for name := range exportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exportSubtitle 导出说明：导出时间、导出人和筛选条件，显示在 PDF 报表标题下方
+func exportSubtitle(user *model.User, query string, statuses []model.AssetStatus, now time.Time) string {
+	parts := []string{"导出时间：" + now.Format("2006-01-02 15:04")}
+	if user != nil {
+		name := user.DisplayName
+		if name == "" {
+			name = user.Username
+		}
+		parts = append(parts, "导出人："+name)
+	}
+	if query != "" {
+		parts = append(parts, "关键字："+query)
+	}
+	if len(statuses) > 0 {
+		labels := make([]string, len(statuses))
+		for i, status := range statuses {
+			labels[i] = status.Label()
+		}
+		parts = append(parts, "状态："+strings.Join(labels, "、"))
+	}
+	return strings.Join(parts, "　")
+}
+
+// parseExportColumns 解析逗号分隔的列名，为空时返回 nil，由导出格式决定默认的列
+func parseExportColumns(v string) ([]importer.Column, error) {
+	var columns []importer.Column
+	seen := map[string]bool{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		c, ok := importer.Lookup(name)
+		if strings.EqualFold(name, idColumn.Field) {
+			c, ok = idColumn, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("未知的列: %s", name)
+		}
+		if seen[c.Field] {
+			return nil, fmt.Errorf("列重复: %s", name)
+		}
+		seen[c.Field] = true
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// textValue 资产在该列的文本值，用于 CSV 和 XLSX，状态使用中文名称
+func textValue(c importer.Column, a *model.Asset) string {
+	if c.Field == idColumn.Field {
+		return strconv.Itoa(a.ID)
+	}
+	return c.Value(a)
+}
+
+// jsonValue 资产在该列的 JSON 值，ID 为数字，状态使用状态值，与接口返回的资产一致
+func jsonValue(c importer.Column, a *model.Asset) interface{} {
+	switch c.Field {
+	case idColumn.Field:
+		return a.ID
+	case "status":
+		return a.Status
+	}
+	return c.Value(a)
+}
+
+// csvEncoder 第一行为中文表头。开头写入 UTF-8 BOM，使 Excel 能正确识别中文
+type csvEncoder struct {
+	w       *bufio.Writer
+	csv     *csv.Writer
+	columns []importer.Column
+}
+
+func newCSVEncoder(w io.Writer, opts exportOptions) (assetEncoder, error) {
+	bw := bufio.NewWriter(w)
+	return &csvEncoder{w: bw, csv: csv.NewWriter(bw), columns: opts.columns}, nil
+}
+
+func (e *csvEncoder) Begin() error {
+	if _, err := e.w.WriteString("\ufeff"); err != nil {
+		return err
+	}
+	header := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		header[i] = c.Label
+	}
+	return e.csv.Write(header)
+}
+
+func (e *csvEncoder) Encode(a *model.Asset) error {
+	record := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		record[i] = importer.EscapeFormula(textValue(c, a))
+	}
+	return e.csv.Write(record)
+}
+
+func (e *csvEncoder) End() error {
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// jsonEncoder 输出 JSON 数组或 NDJSON，每个资产是按所选列顺序排列的对象
+type jsonEncoder struct {
+	w       *bufio.Writer
+	columns []importer.Column
+	// lines 为 true 时输出 NDJSON：每行一个对象，没有外层数组
+	lines bool
+	count int
+}
+
+func newJSONEncoder(w io.Writer, opts exportOptions) (assetEncoder, error) {
+	return &jsonEncoder{w: bufio.NewWriter(w), columns: opts.columns}, nil
+}
+
+func newNDJSONEncoder(w io.Writer, opts exportOptions) (assetEncoder, error) {
+	return &jsonEncoder{w: bufio.NewWriter(w), columns: opts.columns, lines: true}, nil
+}
+
+func (e *jsonEncoder) Begin() error {
+	if e.lines {
+		return nil
+	}
+	_, err := e.w.WriteString("[\n")
+	return err
+}
+
+func (e *jsonEncoder) Encode(a *model.Asset) error {
+	if !e.lines && e.count > 0 {
+		e.w.WriteString(",\n")
+	}
+	e.count++
+	e.w.WriteByte('{')
+	for i, c := range e.columns {
+		key, err := json.Marshal(c.Field)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(jsonValue(c, a))
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		e.w.Write(key)
+		e.w.WriteByte(':')
+		e.w.Write(value)
+	}
+	e.w.WriteByte('}')
+	if e.lines {
+		e.w.WriteByte('\n')
+	}
+	// bufio.Writer 会记住第一次写入失败，这里统一检查
+	_, err := e.w.Write(nil)
+	return err
+}
+
+func (e *jsonEncoder) End() error {
+	if !e.lines {
+		if e.count > 0 {
+			e.w.WriteByte('\n')
+		}
+		e.w.WriteString("]\n")
+	}
+	return e.w.Flush()
+}
+
+// xlsxEncoder 首行为加粗并冻结的中文表头，日期列写为日期单元格。
+// 行数据由 excelize 的流式写入器暂存（数据较多时写入临时文件），End 时把工作簿写入响应
+type xlsxEncoder struct {
+	w         io.Writer
+	columns   []importer.Column
+	file      *excelize.File
+	sheet     *excelize.StreamWriter
+	dateStyle int
+	row       int
+}
+
+func newXLSXEncoder(w io.Writer, opts exportOptions) (assetEncoder, error) {
+	return &xlsxEncoder{w: w, columns: opts.columns, file: excelize.NewFile(), row: 1}, nil
+}
+
+func (e *xlsxEncoder) Begin() error {
+	const sheet = "资产列表"
+	if err := e.file.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+	headerStyle, err := e.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	dateFormat := "yyyy-mm-dd"
+	if e.dateStyle, err = e.file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return err
+	}
+	if e.sheet, err = e.file.NewStreamWriter(sheet); err != nil {
+		return err
+	}
+	if err := e.sheet.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	if err := e.sheet.SetColWidth(1, len(e.columns), 16); err != nil {
+		return err
+	}
+	header := make([]interface{}, len(e.columns))
+	for i, c := range e.columns {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: c.Label}
+	}
+	return e.writeRow(header)
+}
+
+func (e *xlsxEncoder) Encode(a *model.Asset) error {
+	row := make([]interface{}, len(e.columns))
+	for i, c := range e.columns {
+		v := textValue(c, a)
+		if d, err := time.Parse("2006-01-02", v); err == nil && dateColumns[c.Field] {
+			row[i] = excelize.Cell{StyleID: e.dateStyle, Value: d}
+		} else if c.Field == idColumn.Field {
+			row[i] = a.ID
+		} else {
+			row[i] = importer.EscapeFormula(v)
+		}
+	}
+	return e.writeRow(row)
+}
+
+func (e *xlsxEncoder) writeRow(values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	e.row++
+	return e.sheet.SetRow(cell, values)
+}
+
+func (e *xlsxEncoder) End() error {
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	_, err := e.file.WriteTo(e.w)
+	return err
+}
+
+// Close 删除流式写入产生的临时文件
+func (e *xlsxEncoder) Close() error {
+	return e.file.Close()
+}
+
+// errNoReports 服务器没有可用的中文字体，无法生成 PDF
+var errNoReports = errors.New("服务器未配置中文字体，无法生成 PDF")
+
+// pdfEncoder 横向 A4 的资产清单，表头在每页重复，末尾给出合计条数
+type pdfEncoder struct {
+	w      io.Writer
+	opts   exportOptions
+	table  *report.Table
+	values []string
+}
+
+func newPDFEncoder(w io.Writer, opts exportOptions) (assetEncoder, error) {
+	if opts.reports == nil {
+		return nil, errNoReports
+	}
+	return &pdfEncoder{w: w, opts: opts, values: make([]string, len(opts.columns))}, nil
+}
+
+func (e *pdfEncoder) Begin() error {
+	columns := make([]report.Column, len(e.opts.columns))
+	for i, c := range e.opts.columns {
+		weight := reportColumnWeights[c.Field]
+		if weight == 0 {
+			weight = 1
+		}
+		columns[i] = report.Column{Label: c.Label, Weight: weight}
+	}
+	e.table = e.opts.reports.NewTable("资产清单", e.opts.subtitle, columns)
+	return nil
+}
+
+func (e *pdfEncoder) Encode(a *model.Asset) error {
+	for i, c := range e.opts.columns {
+		e.values[i] = textValue(c, a)
+	}
+	return e.table.Row(e.values)
+}
+
+func (e *pdfEncoder) End() error {
+	return e.table.Write(e.w)
+}
+
+// setAttachment 设置下载文件名：filename 按 RFC 6266 编码以支持中文，不支持的客户端使用 ASCII 的 fallback
+func setAttachment(w http.ResponseWriter, filename, fallback string) {
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, url.PathEscape(filename)))
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"asset-management-system/pkg/importer"
+	"asset-management-system/pkg/model"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExportColumns(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		err  string
+	}{
+		{"", nil, ""},
+		{"id, serial_number,资产名称", []string{"id", "serial_number", "name"}, ""},
+		{"ID,状态", []string{"id", "status"}, ""},
+		{"name,,", []string{"name"}, ""},
+		{"name,资产名称", nil, "列重复"},
+		{"color", nil, "未知的列"},
+	}
+	for _, tt := range tests {
+		columns, err := parseExportColumns(tt.in)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: 错误 %v，期望包含 %q", tt.in, err, tt.err)
+			}
+			continue
+		}
+		var got []string
+		for _, c := range columns {
+			got = append(got, c.Field)
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: %v, %v，期望 %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestAssetExportCSV(t *testing.T) {
+	s := newTestServer(testAssets())
+	w := serve(t, s.AssetExportHandler, testLead, http.MethodGet, "/assets/export?format=csv&columns=id,serial_number,状态", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type 为 %q", ct)
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "\ufeff") {
+		t.Error("CSV 缺少 UTF-8 BOM")
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(body, "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 部门负责人只能导出本部门的资产，状态导出为中文名称，可直接重新导入
+	want := [][]string{{"ID", "序列号", "状态"}, {"1", "SN-1", "在库"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("导出内容 %q，期望 %q", records, want)
+	}
+	for _, h := range want[0][1:] {
+		if _, ok := importer.Lookup(h); !ok {
+			t.Errorf("表头 %q 不能被导入识别", h)
+		}
+	}
+}
+
+func TestAssetExportNDJSON(t *testing.T) {
+	s := newTestServer(testAssets())
+	w := serve(t, s.AssetExportHandler, testAdmin, http.MethodGet, "/assets/export?format=ndjson&columns=id,status&query=sn-2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("导出 %d 行，期望 1 行: %s", len(lines), w.Body.String())
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["id"] != float64(2) || got["status"] != "in_stock" {
+		t.Errorf("导出内容 %v", got)
+	}
+}
+
+func TestAssetExportErrors(t *testing.T) {
+	s := newTestServer(testAssets())
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"不支持的格式", "/assets/export?format=doc", http.StatusBadRequest},
+		{"未知的列", "/assets/export?format=csv&columns=color", http.StatusBadRequest},
+		{"无效的状态", "/assets/export?format=csv&status=lost", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := serve(t, s.AssetExportHandler, testAdmin, http.MethodGet, tt.target, ""); w.Code != tt.want {
+			t.Errorf("%s: 状态码 %d，期望 %d", tt.name, w.Code, tt.want)
+		}
+	}
+	if w := serve(t, s.AssetExportHandler, testViewer, http.MethodGet, "/assets/export?format=csv", ""); w.Code != http.StatusForbidden {
+		t.Errorf("没有导出权限: 状态码 %d，期望 403", w.Code)
+	}
+}
+
+// formulaAssets 名称、所在地和备注以公式字符开头的资产
+func formulaAssets() *fakeAssets {
+	return newFakeAssets(model.Asset{ID: 1, SerialNumber: "SN-1", Name: "=HYPERLINK(\"http://example.com\",\"点击\")", Category: "笔记本", Brand: "联想",
+		Department: "IT", Location: "-2+3", Supplier: "京东", Remarks: "@SUM(1)", OrderDate: "2026-01-01", CreatedAt: "2026-01-02"})
+}
+
+func TestAssetExportXLSXEscapesFormulas(t *testing.T) {
+	s := newTestServer(formulaAssets())
+	w := serve(t, s.AssetExportHandler, testAdmin, http.MethodGet, "/assets/export?format=xlsx", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+	table, err := importer.ReadXLSX(w.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Records) != 1 {
+		t.Fatalf("导出 %d 行", len(table.Records))
+	}
+	cells := map[string]string{}
+	for i, h := range table.Header {
+		cells[h] = table.Records[0][i]
+	}
+	want := map[string]string{"资产名称": "'=HYPERLINK(\"http://example.com\",\"点击\")", "所在地": "'-2+3", "备注": "'@SUM(1)", "序列号": "SN-1"}
+	for h, v := range want {
+		if cells[h] != v {
+			t.Errorf("%s 为 %q，期望 %q", h, cells[h], v)
+		}
+	}
+
+	// 重新导入时去掉单引号
+	result, err := table.Parse(importer.Options{Scope: model.AssetScope{All: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := result.Rows[0].Asset
+	if got.Name != "=HYPERLINK(\"http://example.com\",\"点击\")" || got.Location != "-2+3" || got.Remarks != "@SUM(1)" {
+		t.Errorf("重新导入的资产 %+v", got)
+	}
+}
+
+func TestAssetExportCSVEscapesFormulas(t *testing.T) {
+	s := newTestServer(formulaAssets())
+	w := serve(t, s.AssetExportHandler, testAdmin, http.MethodGet, "/assets/export?format=csv", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+	table, err := importer.ReadCSV(w.Body.Bytes(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := map[string]string{}
+	for i, h := range table.Header {
+		cells[h] = table.Records[0][i]
+	}
+	want := map[string]string{"资产名称": "'=HYPERLINK(\"http://example.com\",\"点击\")", "所在地": "'-2+3", "备注": "'@SUM(1)", "序列号": "SN-1"}
+	for h, v := range want {
+		if cells[h] != v {
+			t.Errorf("%s 为 %q，期望 %q", h, cells[h], v)
+		}
+	}
+
+	result, err := table.Parse(importer.Options{Scope: model.AssetScope{All: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Rows[0].Asset; got.Name != "=HYPERLINK(\"http://example.com\",\"点击\")" || got.Location != "-2+3" || got.Remarks != "@SUM(1)" {
+		t.Errorf("重新导入的资产 %+v", got)
+	}
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAssets 内存中的资产存储，实现 model.AssetStore 和回收站的 ListDeleted、Restore。
+// 其余接口由嵌入的 model.AssetRepository 提供，测试未设置时调用会 panic，便于发现遗漏
+type fakeAssets struct {
+	model.AssetRepository
+
+	mu      sync.Mutex
+	assets  map[int]model.Asset
+	deleted map[int]model.DeletedAsset
+	nextID  int
+}
+
+func newFakeAssets(assets ...model.Asset) *fakeAssets {
+	f := &fakeAssets{assets: map[int]model.Asset{}, deleted: map[int]model.DeletedAsset{}, nextID: 1}
+	for _, a := range assets {
+		if a.Version == 0 {
+			a.Version = 1
+		}
+		if a.Status == "" {
+			a.Status = model.StatusInStock
+		}
+		f.assets[a.ID] = a
+		if a.ID >= f.nextID {
+			f.nextID = a.ID + 1
+		}
+	}
+	return f
+}
+
+func (f *fakeAssets) Get(ctx context.Context, id int) (*model.Asset, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.assets[id]
+	if !ok {
+		return nil, model.ErrAssetNotFound
+	}
+	return &a, nil
+}
+
+// List 按 ID 倒序返回，代替按创建时间倒序
+func (f *fakeAssets) List(ctx context.Context) ([]model.Asset, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assets := []model.Asset{}
+	for _, a := range f.assets {
+		assets = append(assets, a)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].ID > assets[j].ID })
+	return assets, nil
+}
+
+func (f *fakeAssets) Create(ctx context.Context, actor model.Actor, asset *model.Asset) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	asset.ID = f.nextID
+	f.nextID++
+	asset.Version = 1
+	if asset.Status == "" {
+		asset.Status = model.StatusInStock
+	}
+	f.assets[asset.ID] = *asset
+	return nil
+}
+
+func (f *fakeAssets) Update(ctx context.Context, actor model.Actor, asset *model.Asset) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.assets[asset.ID]
+	if !ok {
+		return model.ErrAssetNotFound
+	}
+	if asset.Version != 0 && asset.Version != existing.Version {
+		current := existing
+		return &model.VersionConflictError{Current: &current}
+	}
+	if (asset.Recipient != "" || asset.RecipientDepartment != "") &&
+		(asset.Recipient != existing.Recipient || asset.RecipientDepartment != existing.RecipientDepartment) {
+		return model.ErrCustodyChange
+	}
+	asset.Status, asset.Recipient, asset.RecipientDepartment = existing.Status, existing.Recipient, existing.RecipientDepartment
+	asset.Version = existing.Version + 1
+	f.assets[asset.ID] = *asset
+	return nil
+}
+
+// Delete 与 MySQLAssetRepository.Delete 相同，把资产移入回收站
+func (f *fakeAssets) Delete(ctx context.Context, actor model.Actor, id int, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.assets[id]
+	if !ok {
+		return model.ErrAssetNotFound
+	}
+	a.Version++
+	f.deleted[id] = model.DeletedAsset{Asset: a, DeletedAt: time.Now().Format(model.DateTimeLayout),
+		DeletedBy: actor.UserID, DeletedByName: actor.Username, DeleteReason: reason}
+	delete(f.assets, id)
+	return nil
+}
+
+// ListDeleted 按 ID 倒序返回，代替按删除时间倒序
+func (f *fakeAssets) ListDeleted(ctx context.Context) ([]model.DeletedAsset, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assets := []model.DeletedAsset{}
+	for _, d := range f.deleted {
+		assets = append(assets, d)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].ID > assets[j].ID })
+	return assets, nil
+}
+
+func (f *fakeAssets) Restore(ctx context.Context, actor model.Actor, id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.deleted[id]
+	if !ok {
+		return model.ErrAssetNotFound
+	}
+	d.Version++
+	f.assets[id] = d.Asset
+	delete(f.deleted, id)
+	return nil
+}
+
+// Search 在名称、序列号、部门和所在地中做不区分大小写的子串匹配，代替 LIKE
+func (f *fakeAssets) Search(ctx context.Context, query string) ([]model.Asset, error) {
+	all, _ := f.List(ctx)
+	query = strings.ToLower(query)
+	assets := []model.Asset{}
+	for _, a := range all {
+		text := strings.ToLower(strings.Join([]string{a.Name, a.SerialNumber, a.Department, a.Location}, " "))
+		if strings.Contains(text, query) {
+			assets = append(assets, a)
+		}
+	}
+	return assets, nil
+}
+
+func (f *fakeAssets) Each(ctx context.Context, query string, fn func(*model.Asset) error) error {
+	assets, _ := f.List(ctx)
+	if query != "" {
+		assets, _ = f.Search(ctx, query)
+	}
+	for i := range assets {
+		if err := fn(&assets[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindByCode 资产编码匹配的在前，其余按 ID 倒序，代替按创建时间倒序
+func (f *fakeAssets) FindByCode(ctx context.Context, code string) ([]model.Asset, error) {
+	all, _ := f.List(ctx)
+	var byCode, bySerial []model.Asset
+	for _, a := range all {
+		if a.AssetCode == code {
+			byCode = append(byCode, a)
+		} else if a.SerialNumber == code {
+			bySerial = append(bySerial, a)
+		}
+	}
+	return append(append([]model.Asset{}, byCode...), bySerial...), nil
+}
+
+// fakeStocktakes 内存中的盘点存储，资产内容从 assets 读取。
+// 只实现处理登记和查看明细用到的方法，其余由嵌入的 model.StocktakeRepository 提供
+type fakeStocktakes struct {
+	model.StocktakeRepository
+
+	mu         sync.Mutex
+	assets     *fakeAssets
+	stocktakes map[int]model.Stocktake
+	items      []model.StocktakeItem
+	nextItemID int64
+}
+
+// newFakeStocktakes 创建进行中的盘点 st，expected 为应盘资产
+func newFakeStocktakes(assets *fakeAssets, st model.Stocktake, expected ...int) *fakeStocktakes {
+	f := &fakeStocktakes{assets: assets, stocktakes: map[int]model.Stocktake{st.ID: st}, nextItemID: 1}
+	for _, id := range expected {
+		a, _ := assets.Get(context.Background(), id)
+		f.items = append(f.items, model.StocktakeItem{ID: f.nextItemID, StocktakeID: st.ID, AssetID: id, Expected: true,
+			ExpectedDepartment: a.Department, ExpectedLocation: a.Location})
+		f.nextItemID++
+	}
+	return f
+}
+
+func (f *fakeStocktakes) Get(ctx context.Context, id int) (*model.Stocktake, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st, ok := f.stocktakes[id]
+	if !ok {
+		return nil, model.ErrStocktakeNotFound
+	}
+	return &st, nil
+}
+
+func (f *fakeStocktakes) Items(ctx context.Context, id int) ([]model.StocktakeItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := []model.StocktakeItem{}
+	for _, item := range f.items {
+		if item.StocktakeID != id {
+			continue
+		}
+		a, err := f.assets.Get(ctx, item.AssetID)
+		if err != nil {
+			continue
+		}
+		item.Asset = *a
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Find 与 MySQLStocktakeRepository.Find 相同：范围外的资产追加为 expected=false，重复登记时 f.Location 为空则保留原来的实盘所在地
+func (f *fakeStocktakes) Find(ctx context.Context, actor model.Actor, id, assetID int, find model.StocktakeFind) (*model.StocktakeItem, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st, ok := f.stocktakes[id]
+	if !ok {
+		return nil, false, model.ErrStocktakeNotFound
+	}
+	if st.Status != model.StocktakeOpen {
+		return nil, false, model.ErrStocktakeClosed
+	}
+	a, err := f.assets.Get(ctx, assetID)
+	if err != nil {
+		return nil, false, err
+	}
+	index := -1
+	for i, item := range f.items {
+		if item.StocktakeID == id && item.AssetID == assetID {
+			index = i
+		}
+	}
+	if index < 0 {
+		f.items = append(f.items, model.StocktakeItem{ID: f.nextItemID, StocktakeID: id, AssetID: assetID,
+			ExpectedDepartment: a.Department, ExpectedLocation: a.Location})
+		f.nextItemID++
+		index = len(f.items) - 1
+	}
+	item := &f.items[index]
+	duplicate := item.Found()
+	item.FoundAt = time.Now().Format(model.DateTimeLayout)
+	if find.Location != "" {
+		item.FoundLocation = find.Location
+	}
+	item.FoundMethod, item.FoundBy, item.FoundByName, item.Note = find.Method, actor.UserID, actor.Username, find.Note
+	result := *item
+	result.Asset = *a
+	return &result, duplicate, nil
+}
+
+// 测试用户
+var (
+	testAdmin   = &model.User{ID: 1, Username: "admin", Role: model.RoleAdmin, IsActive: true}
+	testLead    = &model.User{ID: 2, Username: "lead", Role: model.RoleDepartmentLead, IsActive: true, Departments: []string{"IT"}}
+	testViewer  = &model.User{ID: 3, Username: "viewer", Role: model.RoleViewer, IsActive: true}
+	testManager = &model.User{ID: 4, Username: "manager", Role: model.RoleAssetManager, IsActive: true, Departments: []string{"IT"}}
+)
+
+// newTestServer 创建使用假存储的 Server，不连接数据库、不解析模板
+func newTestServer(assets model.AssetRepository) *Server {
+	cfg := &config.Config{}
+	cfg.Database.QueryTimeout = time.Minute
+	cfg.Database.ImportTimeout = time.Minute
+	return &Server{cfg: cfg, assets: assets}
+}
+
+// serveForm 以 user 的身份提交表单
+func serveForm(t *testing.T, h http.HandlerFunc, user *model.User, target string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(context.WithValue(req.Context(), userContextKey, user))
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+// serve 以 user 的身份调用 h，body 非空时作为 JSON 请求体
+func serve(t *testing.T, h http.HandlerFunc, user *model.User, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, user))
+	}
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+// decodeResponse 把 JSON 响应解码到 v
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("解析响应失败: %v\n%s", err, w.Body.String())
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"asset-management-system/pkg/importer"
+	"asset-management-system/pkg/model"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// maxImportBytes 导入文件的大小上限
+const maxImportBytes = 10 << 20
+
+// importResponse 导入接口的响应：逐行校验结果，以及实际导入的数量
+type importResponse struct {
+	*importer.Result
+	DryRun   bool `json:"dry_run"`
+	Imported int  `json:"imported"`
+	// IDs 导入后新资产的 ID，顺序与通过校验的行一致
+	IDs []int `json:"ids"`
+}
+
+// apiImportAssets 从 CSV 或 XLSX 文件批量导入资产：
+//
+//	POST /api/v1/assets/import?dry_run=1&encoding=auto
+//
+// 请求体可以是 multipart/form-data 的 file 字段，也可以直接是文件内容，按内容识别是否为 XLSX，encoding 只对 CSV 有效。
+// dry_run 时只返回逐行校验结果；否则在一个事务中导入全部通过校验的行，未通过的行跳过并在响应中列出
+func (s *Server) apiImportAssets(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, model.PermCreateAsset) {
+		return
+	}
+	dryRun, err := parseBoolParam(r.URL.Query().Get("dry_run"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "dry_run 只能是 true 或 false")
+		return
+	}
+	data, filename, ok := readImportFile(w, r)
+	if !ok {
+		return
+	}
+	table, format, err := readImportTable(data, r.URL.Query().Get("encoding"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.importTable(w, r, table, filename, format, dryRun)
+}
+
+// importTable 校验表格中的资产，非试运行时导入通过校验的行
+func (s *Server) importTable(w http.ResponseWriter, r *http.Request, table *importer.Table, filename, format string, dryRun bool) {
+	ctx, cancel := s.importContext(r)
+	defer cancel()
+	existing, err := s.existingSerials(ctx)
+	if err != nil {
+		log.Printf("查询已有资产失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询已有资产失败")
+		return
+	}
+	result, err := table.Parse(importer.Options{
+		Validate: model.PrepareNewAsset,
+		Scope:    model.ScopeFor(CurrentUser(r.Context())),
+		Existing: existing,
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := importResponse{Result: result, DryRun: dryRun, IDs: []int{}}
+	if dryRun {
+		log.Printf("%s 导入试运行: 文件: %s, 通过: %d, 未通过: %d", format, filename, result.Valid, result.Invalid)
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	if result.Valid == 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	assets := result.ValidAssets()
+	reason := fmt.Sprintf("%s 导入：%s", format, filename)
+	if err := s.assets.Import(ctx, model.ActorFor(CurrentUser(r.Context())), assets, reason); err != nil {
+		log.Printf("%s 导入失败: %v", format, err)
+		writeAPIError(w, http.StatusInternalServerError, "导入失败，所有行均未导入")
+		return
+	}
+	s.loadAssetCache(ctx)
+	for _, a := range assets {
+		resp.IDs = append(resp.IDs, a.ID)
+	}
+	resp.Imported = len(assets)
+
+	log.Printf("%s 导入成功: 文件: %s, 导入: %d, 跳过: %d", format, filename, resp.Imported, result.Invalid)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// readImportTable 按文件内容选择 XLSX 或 CSV 解析，返回表格和格式名称
+func readImportTable(data []byte, encoding string) (*importer.Table, string, error) {
+	if importer.IsXLSX(data) {
+		table, err := importer.ReadXLSX(data)
+		return table, "XLSX", err
+	}
+	table, err := importer.ReadCSV(data, encoding)
+	return table, "CSV", err
+}
+
+// existingSerials 返回系统中未删除资产的序列号
+func (s *Server) existingSerials(ctx context.Context) (map[string]bool, error) {
+	assets, err := s.assets.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	serials := make(map[string]bool, len(assets))
+	for _, a := range assets {
+		if a.SerialNumber != "" {
+			serials[a.SerialNumber] = true
+		}
+	}
+	return serials, nil
+}
+
+// readImportFile 读取上传的文件：multipart 请求取 file 字段，其他请求直接读取请求体
+func readImportFile(w http.ResponseWriter, r *http.Request) ([]byte, string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	filename := "上传内容"
+	var src io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeImportReadError(w, err)
+			return nil, "", false
+		}
+		defer file.Close()
+		src, filename = file, header.Filename
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		writeImportReadError(w, err)
+		return nil, "", false
+	}
+	if len(data) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "文件为空")
+		return nil, "", false
+	}
+	return data, filename, true
+}
+
+func writeImportReadError(w http.ResponseWriter, err error) {
+	switch {
+	case isMaxBytesError(err):
+		writeAPIError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("文件不能超过 %d MB", maxImportBytes>>20))
+	case errors.Is(err, http.ErrMissingFile):
+		writeAPIError(w, http.StatusBadRequest, "请通过 file 字段上传文件")
+	default:
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("读取上传文件失败: %v", err))
+	}
+}
+
+func isMaxBytesError(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// parseBoolParam 解析查询参数中的布尔值，空字符串为 false
+func parseBoolParam(v string) (bool, error) {
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/report"
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 条码编码的内容
+const (
+	// barcodeContentCode 资产编码，没有资产编码时为序列号
+	barcodeContentCode = "code"
+	// barcodeContentLink 资产页面的链接，手机扫描后直接打开该资产
+	barcodeContentLink = "link"
+)
+
+// maxLabels 一次最多打印的标签数
+const maxLabels = 500
+
+// assetLink 资产的页面链接。配置了 server.public_url 时使用该地址，否则使用当前请求的地址
+func (s *Server) assetLink(r *http.Request, id int) string {
+	base := strings.TrimRight(s.cfg.Server.PublicURL, "/")
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host
+	}
+	return fmt.Sprintf("%s/asset-entry?asset=%d", base, id)
+}
+
+// barcodeContent 返回资产条码编码的内容
+func (s *Server) barcodeContent(r *http.Request, a *model.Asset, content string) (string, error) {
+	if content == barcodeContentLink {
+		return s.assetLink(r, a.ID), nil
+	}
+	if a.AssetCode != "" {
+		return a.AssetCode, nil
+	}
+	if a.SerialNumber != "" {
+		return a.SerialNumber, nil
+	}
+	return "", fmt.Errorf("资产“%s”没有资产编码和序列号", a.Name)
+}
+
+// parseBarcodeOptions 解析条码类型 type 和内容 content。
+// type 默认为 qr；content 默认二维码为 link，一维条码为 code
+func parseBarcodeOptions(q url.Values) (report.Symbology, string, error) {
+	symbology := report.QR
+	if v := q.Get("type"); v != "" {
+		symbology = report.Symbology(v)
+		if !symbology.Valid() {
+			return "", "", fmt.Errorf("type 只能是 qr 或 code128")
+		}
+	}
+	content := q.Get("content")
+	switch content {
+	case "":
+		content = barcodeContentCode
+		if symbology == report.QR {
+			content = barcodeContentLink
+		}
+	case barcodeContentCode, barcodeContentLink:
+	default:
+		return "", "", fmt.Errorf("content 只能是 code 或 link")
+	}
+	return symbology, content, nil
+}
+
+// apiAssetBarcode 返回资产的二维码或一维条码图片（PNG），size 为图片宽度（像素）
+func (s *Server) apiAssetBarcode(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	asset, ok := s.apiScopedAsset(w, r, id)
+	if !ok {
+		return
+	}
+	symbology, content, err := parseBarcodeOptions(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	size := 256
+	if v := r.URL.Query().Get("size"); v != "" {
+		size, err = strconv.Atoi(v)
+		if err != nil || size < 64 || size > 2048 {
+			writeAPIError(w, http.StatusBadRequest, "size 应为 64 到 2048 之间的整数")
+			return
+		}
+	}
+	payload, err := s.barcodeContent(r, asset, content)
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	var buf bytes.Buffer
+	if err := symbology.WritePNG(&buf, payload, size); err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("写入条码图片失败: %v", err)
+	}
+}
+
+// AssetLabelsHandler 生成选中资产的标签：
+//
+//	GET /assets/labels?ids=1,2,3&layout=a4-3x8&type=qr|code128&content=link|code&skip=0
+//	GET /assets/labels?ids=1,2,3&format=zpl&template=qr-50x30&dpi=203&copies=1
+//
+// 默认生成 PDF，每张标签包含条码、资产名称、资产编码和序列号。layout 为标签纸规格，默认为第一种；
+// skip 为标签纸上已用掉的张数，从下一个位置开始打印。format=zpl 时按 ZPL 模板生成热敏打印机指令
+func (s *Server) AssetLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理标签打印请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	q := r.URL.Query()
+	ids, err := parseLabelIDs(q.Get("ids"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch q.Get("format") {
+	case "", "pdf":
+		s.writeLabelPDF(w, r, ids)
+	case "zpl":
+		s.writeLabelZPL(w, r, ids)
+	default:
+		http.Error(w, "format 只能是 pdf 或 zpl", http.StatusBadRequest)
+	}
+}
+
+// writeLabelPDF 按标签纸规格生成标签 PDF
+func (s *Server) writeLabelPDF(w http.ResponseWriter, r *http.Request, ids []int) {
+	if s.reports == nil {
+		http.Error(w, errNoReports.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	layout := report.LabelLayouts()[0]
+	if v := q.Get("layout"); v != "" {
+		var ok bool
+		if layout, ok = report.FindLabelLayout(v); !ok {
+			http.Error(w, fmt.Sprintf("不支持的标签纸规格: %s", v), http.StatusBadRequest)
+			return
+		}
+	}
+	skip := 0
+	if v := q.Get("skip"); v != "" {
+		var err error
+		skip, err = strconv.Atoi(v)
+		if err != nil || skip < 0 || skip >= layout.PerPage() {
+			http.Error(w, fmt.Sprintf("skip 应为 0 到 %d 之间的整数", layout.PerPage()-1), http.StatusBadRequest)
+			return
+		}
+	}
+	symbology, content, err := parseBarcodeOptions(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	assets, status, err := s.labelAssets(r, ids)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	labels := make([]report.Label, 0, len(assets))
+	for _, asset := range assets {
+		payload, err := s.barcodeContent(r, asset, content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		labels = append(labels, report.Label{Content: payload, Lines: labelLines(asset)})
+	}
+
+	var buf bytes.Buffer
+	if err := s.reports.Labels(&buf, layout, symbology, labels, skip); err != nil {
+		// 标签内容无法编码为条码，例如 Code128 中含有中文
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	date := time.Now().Format("2006-01-02")
+	w.Header().Set("Content-Type", "application/pdf")
+	setAttachment(w, fmt.Sprintf("资产标签-%s.pdf", date), fmt.Sprintf("labels-%s.pdf", date))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("写入标签失败: %v", err)
+	}
+}
+
+// labelAssets 按顺序读取要打印标签的资产。失败时返回错误信息和对应的 HTTP 状态码，
+// 资产不存在或不在用户的部门范围内时为 404
+func (s *Server) labelAssets(r *http.Request, ids []int) ([]*model.Asset, int, error) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	scope := model.ScopeFor(CurrentUser(r.Context()))
+	assets := make([]*model.Asset, 0, len(ids))
+	for _, id := range ids {
+		asset, err := s.assets.Get(ctx, id)
+		if err == model.ErrAssetNotFound || (err == nil && !scope.Allows(asset)) {
+			return nil, http.StatusNotFound, fmt.Errorf("资产不存在: %d", id)
+		}
+		if err != nil {
+			log.Printf("查询资产失败: %v", err)
+			return nil, http.StatusInternalServerError, fmt.Errorf("查询资产失败")
+		}
+		assets = append(assets, asset)
+	}
+	return assets, http.StatusOK, nil
+}
+
+// labelLines 标签上条码旁边的文字：资产名称、资产编码和序列号，空的项不显示
+func labelLines(a *model.Asset) []string {
+	return []string{a.Name, a.AssetCode, a.SerialNumber}
+}
+
+// parseLabelIDs 解析逗号分隔的资产 ID，去掉重复的 ID 并保持顺序
+func parseLabelIDs(v string) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("无效的资产 ID: %s", part)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("请选择要打印标签的资产")
+	}
+	if len(ids) > maxLabels {
+		return nil, fmt.Errorf("一次最多打印 %d 张标签", maxLabels)
+	}
+	return ids, nil
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabelIDs(t *testing.T) {
+	tooMany := make([]string, maxLabels+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprint(i + 1)
+	}
+	tests := []struct {
+		in   string
+		want []int
+		err  string
+	}{
+		{"3", []int{3}, ""},
+		{" 3, 1 ,2,", []int{3, 1, 2}, ""},
+		{"2,1,2,1", []int{2, 1}, ""},
+		{"", nil, "请选择"},
+		{" , ", nil, "请选择"},
+		{"1,abc", nil, "无效的资产 ID: abc"},
+		{"0", nil, "无效的资产 ID"},
+		{"-1", nil, "无效的资产 ID"},
+		{strings.Join(tooMany, ","), nil, "一次最多打印"},
+	}
+	for _, tt := range tests {
+		ids, err := parseLabelIDs(tt.in)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%.20q: 错误 %v，期望包含 %q", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%q: %v, %v，期望 %v", tt.in, ids, err, tt.want)
+		}
+	}
+}
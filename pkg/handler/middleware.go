@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// requireAuth 拦截未登录的请求：浏览器重定向到登录页，API 客户端返回 401 JSON。
+// 配置中的公开路径（及 /login）直接放行
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.isPublicPath(r.URL.Path) || IsAuthenticated(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Printf("未登录访问被拒绝: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+		if wantsJSON(r) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "未登录或会话已过期"})
+			return
+		}
+		target := "/login"
+		if r.Method == http.MethodGet && r.URL.Path != "/" {
+			target += "?next=" + url.QueryEscape(r.URL.RequestURI())
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+	})
+}
+
+func (s *Server) isPublicPath(path string) bool {
+	if path == "/login" {
+		return true
+	}
+	for _, p := range s.cfg.Auth.PublicPaths {
+		if strings.HasSuffix(p, "/") {
+			if strings.HasPrefix(path, p) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// pagePermissions 页面模板据此显示或隐藏当前用户不能使用的按钮
+type pagePermissions struct {
+	Create      bool
+	Edit        bool
+	Delete      bool
+	Export      bool
+	ManageUsers bool
+	RecycleBin  bool
+	Custody     bool
+	Stocktake   bool
+}
+
+func permissionsFor(user *model.User) pagePermissions {
+	return pagePermissions{
+		Create:      user.Can(model.PermCreateAsset),
+		Edit:        user.Can(model.PermEditAsset),
+		Delete:      user.Can(model.PermDeleteAsset),
+		Export:      user.Can(model.PermExportAsset),
+		ManageUsers: user.Can(model.PermManageUsers),
+		RecycleBin:  user.Can(model.PermRecycleBin),
+		Custody:     user.Can(model.PermCustody),
+		Stocktake:   user.Can(model.PermStocktake),
+	}
+}
+
+// authorize 检查当前用户是否拥有权限，没有时写入 403 响应并返回 false
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, perm model.Permission) bool {
+	user := CurrentUser(r.Context())
+	if user.Can(perm) {
+		return true
+	}
+	username := ""
+	if user != nil {
+		username = user.Username
+	}
+	log.Printf("权限不足: username=%s, permission=%s, %s %s", username, perm, r.Method, r.URL.Path)
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "没有执行此操作的权限"})
+		return false
+	}
+	http.Error(w, "没有执行此操作的权限", http.StatusForbidden)
+	return false
+}
+
+// wantsJSON 判断请求来自 API 客户端（AJAX、接口路径或只接受 JSON）而不是浏览器页面跳转
+func wantsJSON(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/") || r.Header.Get("X-Requested-With") == "XMLHttpRequest" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// safeRedirectTarget 只允许跳转到站内路径，防止开放重定向。
+// 浏览器解析地址时会去掉其中的制表符和换行，/\t/evil.com 会变成 //evil.com，因此含控制字符的地址一律拒绝
+func safeRedirectTarget(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") ||
+		strings.IndexFunc(next, unicode.IsControl) >= 0 {
+		return "/asset-entry"
+	}
+	return next
+}
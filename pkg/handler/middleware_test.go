@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/session"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeUsers 内存中的用户存储，只实现会话加载和登录用到的方法
+type fakeUsers struct {
+	model.UserRepository
+	users []*model.User
+}
+
+func (f *fakeUsers) Get(ctx context.Context, id int) (*model.User, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, model.ErrUserNotFound
+}
+
+func (f *fakeUsers) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	for _, u := range f.users {
+		if u.Username == username {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, model.ErrUserNotFound
+}
+
+// authServer 返回带内存会话存储的 Server，公开路径与默认配置相同
+func authServer(users ...*model.User) *Server {
+	s := newTestServer(testAssets())
+	s.cfg.Auth.PublicPaths = []string{"/login", "/static/"}
+	s.users = &fakeUsers{users: users}
+	s.sessions = session.NewManager(session.NewMemoryStore(), session.Options{
+		CookieName:      "ams_session",
+		Secret:          []byte("0123456789abcdef0123456789abcdef"),
+		SameSite:        http.SameSiteLaxMode,
+		IdleTimeout:     time.Hour,
+		AbsoluteTimeout: time.Hour,
+	})
+	return s
+}
+
+// okHandler 代替受保护的页面
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestRequireAuth(t *testing.T) {
+	s := authServer()
+	h := s.requireAuth(okHandler)
+	tests := []struct {
+		name     string
+		user     *model.User
+		method   string
+		target   string
+		header   map[string]string
+		code     int
+		location string
+	}{
+		{"页面跳转到登录页并带上原地址", nil, http.MethodGet, "/asset-entry?asset=1", nil, http.StatusSeeOther, "/login?next=%2Fasset-entry%3Fasset%3D1"},
+		{"首页不带 next", nil, http.MethodGet, "/", nil, http.StatusSeeOther, "/login"},
+		{"表单提交不带 next", nil, http.MethodPost, "/assets", nil, http.StatusSeeOther, "/login"},
+		{"接口返回 401", nil, http.MethodGet, "/api/v1/assets", nil, http.StatusUnauthorized, ""},
+		{"AJAX 返回 401", nil, http.MethodGet, "/assets", map[string]string{"X-Requested-With": "XMLHttpRequest"}, http.StatusUnauthorized, ""},
+		{"只接受 JSON 时返回 401", nil, http.MethodGet, "/assets", map[string]string{"Accept": "application/json"}, http.StatusUnauthorized, ""},
+		{"浏览器同时接受 HTML 时跳转", nil, http.MethodGet, "/assets", map[string]string{"Accept": "text/html,application/json"}, http.StatusSeeOther, "/login?next=%2Fassets"},
+		{"登录页公开", nil, http.MethodGet, "/login", nil, http.StatusOK, ""},
+		{"静态文件按前缀公开", nil, http.MethodGet, "/static/css/app.css", nil, http.StatusOK, ""},
+		{"前缀本身不公开", nil, http.MethodGet, "/static", nil, http.StatusSeeOther, "/login?next=%2Fstatic"},
+		{"已登录", testViewer, http.MethodGet, "/asset-entry", nil, http.StatusOK, ""},
+		{"已登录访问接口", testViewer, http.MethodGet, "/api/v1/assets", nil, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.target, nil)
+		for k, v := range tt.header {
+			r.Header.Set(k, v)
+		}
+		if tt.user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), userContextKey, tt.user))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s: 状态码 %d，跳转到 %q，期望 %d，%q", tt.name, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+			continue
+		}
+		if tt.code == http.StatusUnauthorized {
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] == "" {
+				t.Errorf("%s: 响应不是 JSON 错误: %s", tt.name, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestWithSession(t *testing.T) {
+	active := &model.User{ID: 10, Username: "zhang", Role: model.RoleViewer, IsActive: true}
+	disabled := &model.User{ID: 11, Username: "li", Role: model.RoleAdmin, IsActive: false}
+	s := authServer(active, disabled)
+	h := s.withSession(s.requireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(CurrentUser(r.Context()).Username))
+	})))
+	cookieFor := func(userID int) *http.Cookie {
+		w := httptest.NewRecorder()
+		if _, err := s.sessions.Create(context.Background(), w, userID); err != nil {
+			t.Fatal(err)
+		}
+		return w.Result().Cookies()[0]
+	}
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		code   int
+	}{
+		{"有效会话", cookieFor(active.ID), http.StatusOK},
+		{"没有会话", nil, http.StatusUnauthorized},
+		{"用户已停用", cookieFor(disabled.ID), http.StatusUnauthorized},
+		{"用户已删除", cookieFor(99), http.StatusUnauthorized},
+		{"签名无效", &http.Cookie{Name: "ams_session", Value: "abc.def"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/assets", nil)
+		if tt.cookie != nil {
+			r.AddCookie(tt.cookie)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s: 状态码 %d，期望 %d", tt.name, w.Code, tt.code)
+		}
+		if tt.code == http.StatusOK && w.Body.String() != active.Username {
+			t.Errorf("%s: 当前用户为 %q", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestSafeRedirectTarget(t *testing.T) {
+	tests := []struct{ next, want string }{
+		{"/assets?status=in_use", "/assets?status=in_use"},
+		{"/asset-entry?asset=1#history", "/asset-entry?asset=1#history"},
+		{"", "/asset-entry"},
+		{"//evil.com", "/asset-entry"},
+		{"///evil.com", "/asset-entry"},
+		{"/\\evil.com", "/asset-entry"},
+		{"https://evil.com", "/asset-entry"},
+		{"http://evil.com/assets", "/asset-entry"},
+		{"evil.com", "/asset-entry"},
+		{"javascript:alert(1)", "/asset-entry"},
+		{" //evil.com", "/asset-entry"},
+		// 浏览器会去掉地址中的制表符和换行
+		{"/\t/evil.com", "/asset-entry"},
+		{"/\n/evil.com", "/asset-entry"},
+		{"/\r\n/evil.com", "/asset-entry"},
+	}
+	for _, tt := range tests {
+		if got := safeRedirectTarget(tt.next); got != tt.want {
+			t.Errorf("safeRedirectTarget(%q) = %q，期望 %q", tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestLoginRedirect(t *testing.T) {
+	hash, err := model.HashPassword("correct-horse-battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{ID: 10, Username: "zhang", Role: model.RoleViewer, IsActive: true, PasswordHash: hash}
+	s := authServer(user)
+	tests := []struct {
+		next string
+		want string
+	}{
+		{"/assets?status=in_use", "/assets?status=in_use"},
+		{"", "/asset-entry"},
+		{"//evil.com", "/asset-entry"},
+		{"https://evil.com", "/asset-entry"},
+		{"/\t/evil.com", "/asset-entry"},
+	}
+	for _, tt := range tests {
+		form := url.Values{"username": {"zhang"}, "password": {"correct-horse-battery"}, "next": {tt.next}}
+		r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		s.LoginHandler(w, r)
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != tt.want {
+			t.Errorf("next=%q: 状态码 %d，跳转到 %q，期望 %q", tt.next, w.Code, w.Header().Get("Location"), tt.want)
+		}
+	}
+
+	form := url.Values{"username": {"zhang"}, "password": {"wrong"}, "next": {"/assets"}}
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	s.LoginHandler(w, r)
+	if w.Code != http.StatusUnauthorized || len(w.Result().Cookies()) != 0 {
+		t.Errorf("密码错误: 状态码 %d，cookie %v", w.Code, w.Result().Cookies())
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	perms := []model.Permission{
+		model.PermViewAsset, model.PermCreateAsset, model.PermEditAsset, model.PermDeleteAsset, model.PermExportAsset,
+		model.PermManageUsers, model.PermAllDepartments, model.PermRecycleBin, model.PermCustody, model.PermStocktake,
+	}
+	disabled := &model.User{ID: 5, Username: "disabled", Role: model.RoleAdmin, IsActive: false}
+	tests := []struct {
+		user    *model.User
+		allowed []model.Permission
+	}{
+		{testAdmin, perms},
+		{testManager, []model.Permission{model.PermViewAsset, model.PermCreateAsset, model.PermEditAsset, model.PermDeleteAsset,
+			model.PermExportAsset, model.PermCustody, model.PermStocktake}},
+		{testLead, []model.Permission{model.PermViewAsset, model.PermEditAsset, model.PermExportAsset, model.PermCustody, model.PermStocktake}},
+		{testViewer, []model.Permission{model.PermViewAsset}},
+		{disabled, nil},
+		{nil, nil},
+	}
+	s := newTestServer(testAssets())
+	for _, tt := range tests {
+		name := "未登录"
+		if tt.user != nil {
+			name = tt.user.Username
+		}
+		allowed := map[model.Permission]bool{}
+		for _, p := range tt.allowed {
+			allowed[p] = true
+		}
+		for _, p := range perms {
+			// 接口返回 JSON 错误，页面返回纯文本
+			for _, target := range []string{"/api/v1/assets", "/assets"} {
+				r := httptest.NewRequest(http.MethodGet, target, nil)
+				if tt.user != nil {
+					r = r.WithContext(context.WithValue(r.Context(), userContextKey, tt.user))
+				}
+				w := httptest.NewRecorder()
+				if got := s.authorize(w, r, p); got != allowed[p] {
+					t.Errorf("%s %s %s: authorize = %v，期望 %v", name, p, target, got, allowed[p])
+					continue
+				}
+				if allowed[p] {
+					if w.Code != http.StatusOK || w.Body.Len() != 0 {
+						t.Errorf("%s %s %s: 允许时写入了响应 %d %s", name, p, target, w.Code, w.Body.String())
+					}
+					continue
+				}
+				wantType := "text/plain; charset=utf-8"
+				if target == "/api/v1/assets" {
+					wantType = "application/json"
+				}
+				if w.Code != http.StatusForbidden || w.Header().Get("Content-Type") != wantType {
+					t.Errorf("%s %s %s: 状态码 %d，Content-Type %q", name, p, target, w.Code, w.Header().Get("Content-Type"))
+				}
+			}
+		}
+	}
+}
+
+func TestAssetPermissionsByRole(t *testing.T) {
+	const newAsset = `{"name":"显示器","serial_number":"SN-9","category":"显示器","brand":"戴尔","location":"北京","supplier":"京东","department":"IT"}`
+	endpoints := []struct {
+		name   string
+		h      func(*Server) http.HandlerFunc
+		method string
+		target string
+		body   string
+		// 按 testAdmin、testManager、testLead、testViewer 的顺序
+		want [4]int
+	}{
+		{"查看", func(s *Server) http.HandlerFunc { return s.APIAssetsHandler }, http.MethodGet, "/api/v1/assets", "",
+			[4]int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK}},
+		{"新建", func(s *Server) http.HandlerFunc { return s.APIAssetsHandler }, http.MethodPost, "/api/v1/assets", newAsset,
+			[4]int{http.StatusCreated, http.StatusCreated, http.StatusForbidden, http.StatusForbidden}},
+		{"编辑", func(s *Server) http.HandlerFunc { return s.APIAssetsHandler }, http.MethodPatch, "/api/v1/assets/1", `{"location":"北京总部 6F","version":1}`,
+			[4]int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusForbidden}},
+		{"删除", func(s *Server) http.HandlerFunc { return s.APIAssetsHandler }, http.MethodDelete, "/api/v1/assets/1", "",
+			[4]int{http.StatusNoContent, http.StatusNoContent, http.StatusForbidden, http.StatusForbidden}},
+		{"导出", func(s *Server) http.HandlerFunc { return s.AssetExportHandler }, http.MethodGet, "/assets/export?format=csv", "",
+			[4]int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusForbidden}},
+	}
+	for _, e := range endpoints {
+		for i, user := range []*model.User{testAdmin, testManager, testLead, testViewer} {
+			s := newTestServer(testAssets())
+			if w := serve(t, e.h(s), user, e.method, e.target, e.body); w.Code != e.want[i] {
+				t.Errorf("%s %s: 状态码 %d，期望 %d: %s", user.Username, e.name, w.Code, e.want[i], w.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/notify"
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// apiNotificationsPrefix 站内消息资源路径
+const apiNotificationsPrefix = "/api/v1/notifications"
+
+// 站内消息列表的默认和最大条数
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 200
+)
+
+// NotificationsHandler 渲染站内消息页面
+func (s *Server) NotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理站内消息页面请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
+	}
+	data := struct {
+		Perms pagePermissions
+	}{
+		Perms: permissionsFor(CurrentUser(r.Context())),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.notificationsTemplate.Execute(w, data); err != nil {
+		log.Printf("渲染站内消息页面失败: %v", err)
+		http.Error(w, "渲染站内消息页面失败", http.StatusInternalServerError)
+	}
+}
+
+// APINotificationsHandler 处理当前用户的站内消息接口：
+//
+//	GET  /api/v1/notifications?unread=1&limit=  消息列表和未读数量，按时间倒序
+//	POST /api/v1/notifications/{id}/read        标记一条消息为已读
+//	POST /api/v1/notifications/read-all         标记全部消息为已读
+func (s *Server) APINotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理站内消息接口请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, apiNotificationsPrefix), "/")
+	method := http.MethodPost
+	if rest == "" {
+		method = http.MethodGet
+	}
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		return
+	}
+
+	switch {
+	case rest == "":
+		s.apiListNotifications(w, r)
+	case rest == "read-all":
+		s.apiReadAllNotifications(w, r)
+	case strings.HasSuffix(rest, "/read"):
+		id, err := strconv.ParseInt(strings.TrimSuffix(rest, "/read"), 10, 64)
+		if err != nil || id < 1 {
+			writeAPIError(w, http.StatusNotFound, "消息不存在")
+			return
+		}
+		s.apiReadNotification(w, r, id)
+	default:
+		writeAPIError(w, http.StatusNotFound, "接口不存在")
+	}
+}
+
+func (s *Server) apiListNotifications(w http.ResponseWriter, r *http.Request) {
+	limit := defaultNotificationLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNotificationLimit {
+			writeAPIError(w, http.StatusBadRequest, "limit 必须是 1 到 200 之间的整数")
+			return
+		}
+		limit = n
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "1" || r.URL.Query().Get("unread") == "true"
+
+	user := CurrentUser(r.Context())
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	notifications, err := s.notifications.List(ctx, user.ID, unreadOnly, limit)
+	if err != nil {
+		log.Printf("查询站内消息失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询站内消息失败")
+		return
+	}
+	unread, err := s.notifications.UnreadCount(ctx, user.ID)
+	if err != nil {
+		log.Printf("统计未读消息失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询站内消息失败")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"notifications": notifications, "unread": unread})
+}
+
+func (s *Server) apiReadNotification(w http.ResponseWriter, r *http.Request, id int64) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	err := s.notifications.MarkRead(ctx, CurrentUser(r.Context()).ID, id)
+	if err == model.ErrNotificationNotFound {
+		writeAPIError(w, http.StatusNotFound, "消息不存在")
+		return
+	}
+	if err != nil {
+		log.Printf("标记消息已读失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "标记消息已读失败")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) apiReadAllNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	n, err := s.notifications.MarkAllRead(ctx, CurrentUser(r.Context()).ID)
+	if err != nil {
+		log.Printf("标记全部消息已读失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "标记全部消息已读失败")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"updated": n})
+}
+
+// RunReminders 按配置定时发送到期归还提醒，ctx 取消后退出
+func (s *Server) RunReminders(ctx context.Context) {
+	if !s.cfg.Reminders.Enabled {
+		return
+	}
+	store := model.NewMySQLNotificationRepository(s.db)
+	notify.NewReminder(s.cfg.Reminders, s.assets, s.users, store, notify.FromConfig(s.cfg.Reminders, store)).Run(ctx)
+}
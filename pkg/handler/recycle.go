@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// apiRecycleBinPrefix 回收站资源路径
+const apiRecycleBinPrefix = "/api/v1/recycle-bin"
+
+// RecycleBinHandler 渲染回收站页面
+func (s *Server) RecycleBinHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理回收站页面请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermRecycleBin) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.recycleBinTemplate.Execute(w, nil); err != nil {
+		log.Printf("渲染回收站页面失败: %v", err)
+		http.Error(w, "渲染回收站页面失败", http.StatusInternalServerError)
+	}
+}
+
+// APIRecycleBinHandler 处理回收站接口：
+//
+//	GET    /api/v1/recycle-bin               回收站中的资产，按删除时间倒序
+//	POST   /api/v1/recycle-bin/{id}/restore  恢复资产
+//	DELETE /api/v1/recycle-bin/{id}          彻底删除资产
+func (s *Server) APIRecycleBinHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理回收站接口请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermRecycleBin) {
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, apiRecycleBinPrefix), "/")
+	if rest == "" {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiListDeleted(w, r)
+		return
+	}
+
+	idPart, sub := rest, ""
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		idPart, sub = rest[:i], rest[i+1:]
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id < 1 {
+		writeAPIError(w, http.StatusNotFound, "回收站中没有该资产")
+		return
+	}
+	switch {
+	case sub == "" && r.Method == http.MethodDelete:
+		s.apiPurgeAsset(w, r, id)
+	case sub == "restore" && r.Method == http.MethodPost:
+		s.apiRestoreAsset(w, r, id)
+	case sub == "":
+		w.Header().Set("Allow", "DELETE")
+		writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+	case sub == "restore":
+		w.Header().Set("Allow", "POST")
+		writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+	default:
+		writeAPIError(w, http.StatusNotFound, "接口不存在")
+	}
+}
+
+func (s *Server) apiListDeleted(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	assets, err := s.assets.ListDeleted(ctx)
+	if err != nil {
+		log.Printf("查询回收站失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询回收站失败")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"assets": assets})
+}
+
+func (s *Server) apiRestoreAsset(w http.ResponseWriter, r *http.Request, id int) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	err := s.assets.Restore(ctx, model.ActorFor(CurrentUser(r.Context())), id)
+	if err == model.ErrAssetNotFound {
+		writeAPIError(w, http.StatusNotFound, "回收站中没有该资产")
+		return
+	}
+	if err != nil {
+		log.Printf("恢复资产失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "恢复资产失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("资产已从回收站恢复: id=%d", id)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "success"})
+}
+
+func (s *Server) apiPurgeAsset(w http.ResponseWriter, r *http.Request, id int) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	err := s.assets.Purge(ctx, model.ActorFor(CurrentUser(r.Context())), id)
+	if err == model.ErrAssetNotFound {
+		writeAPIError(w, http.StatusNotFound, "回收站中没有该资产")
+		return
+	}
+	if err != nil {
+		log.Printf("彻底删除资产失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "彻底删除资产失败")
+		return
+	}
+
+	log.Printf("资产已彻底删除: id=%d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
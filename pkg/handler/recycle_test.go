@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// visibleAssets 返回列表接口、详情接口和导出中能看到的资产 1
+func visibleAssets(t *testing.T, s *Server) (listed, got, exported bool) {
+	t.Helper()
+	var page assetPage
+	decodeResponse(t, serve(t, s.APIAssetsHandler, testAdmin, http.MethodGet, "/api/v1/assets", ""), &page)
+	for _, a := range page.Assets {
+		listed = listed || a.ID == 1
+	}
+	got = serve(t, s.APIAssetsHandler, testAdmin, http.MethodGet, "/api/v1/assets/1", "").Code == http.StatusOK
+	w := serve(t, s.AssetExportHandler, testAdmin, http.MethodGet, "/assets/export?format=csv&columns=serial_number", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("导出: 状态码 %d: %s", w.Code, w.Body.String())
+	}
+	exported = strings.Contains(w.Body.String(), "SN-1")
+	return listed, got, exported
+}
+
+func TestRecycleBinRestore(t *testing.T) {
+	assets := testAssets()
+	s := newTestServer(assets)
+
+	if w := serve(t, s.APIAssetsHandler, testManager, http.MethodDelete, "/api/v1/assets/1?reason=报废", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("删除资产: 状态码 %d，期望 204: %s", w.Code, w.Body.String())
+	}
+	if listed, got, exported := visibleAssets(t, s); listed || got || exported {
+		t.Errorf("删除后仍可见: 列表 %v，详情 %v，导出 %v", listed, got, exported)
+	}
+
+	w := serve(t, s.APIRecycleBinHandler, testAdmin, http.MethodGet, "/api/v1/recycle-bin", "")
+	var bin struct {
+		Assets []model.DeletedAsset `json:"assets"`
+	}
+	decodeResponse(t, w, &bin)
+	if len(bin.Assets) != 1 || bin.Assets[0].ID != 1 || bin.Assets[0].DeleteReason != "报废" || bin.Assets[0].DeletedByName != "manager" {
+		t.Fatalf("回收站 %+v", bin.Assets)
+	}
+
+	if w := serve(t, s.APIRecycleBinHandler, testManager, http.MethodPost, "/api/v1/recycle-bin/1/restore", ""); w.Code != http.StatusForbidden {
+		t.Errorf("没有回收站权限时恢复: 状态码 %d，期望 403", w.Code)
+	}
+	if w := serve(t, s.APIRecycleBinHandler, testAdmin, http.MethodPost, "/api/v1/recycle-bin/1/restore", ""); w.Code != http.StatusOK {
+		t.Fatalf("恢复资产: 状态码 %d，期望 200: %s", w.Code, w.Body.String())
+	}
+	if listed, got, exported := visibleAssets(t, s); !listed || !got || !exported {
+		t.Errorf("恢复后不可见: 列表 %v，详情 %v，导出 %v", listed, got, exported)
+	}
+	if w := serve(t, s.APIRecycleBinHandler, testAdmin, http.MethodPost, "/api/v1/recycle-bin/1/restore", ""); w.Code != http.StatusNotFound {
+		t.Errorf("恢复不在回收站中的资产: 状态码 %d，期望 404", w.Code)
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 扫码查询时资产的匹配方式
+const (
+	matchedByLink   = "link"
+	matchedByCode   = "asset_code"
+	matchedBySerial = "serial_number"
+)
+
+// lookupResult 扫码查询的结果。序列号可能重复，因此 assets 可能有多项，由用户选择
+type lookupResult struct {
+	Code      string        `json:"code"`
+	MatchedBy string        `json:"matched_by"`
+	Assets    []model.Asset `json:"assets"`
+}
+
+// ScanHandler 手机扫码页面：用摄像头扫描资产标签上的二维码或条码，查看资产并办理归还、确认在场或报修
+func (s *Server) ScanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理扫码页面请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
+	}
+	user := CurrentUser(r.Context())
+	data := struct {
+		Perms             pagePermissions
+		Conditions        []model.CustodyCondition
+		StatusLabels      map[model.AssetStatus]string
+		StatusTransitions map[model.AssetStatus][]model.AssetStatus
+	}{
+		Perms:             permissionsFor(user),
+		Conditions:        model.Conditions(),
+		StatusLabels:      statusLabels(),
+		StatusTransitions: statusTransitions(),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.scanTemplate.Execute(w, data); err != nil {
+		log.Printf("渲染扫码页面失败: %v", err)
+		http.Error(w, "渲染扫码页面失败", http.StatusInternalServerError)
+	}
+}
+
+// apiLookupAsset 把扫描到的内容解析为资产：资产标签二维码中的资产链接（/asset-entry?asset=ID）、
+// 资产编码或序列号。只返回用户部门范围内的资产，没有匹配时返回 404
+func (s *Server) apiLookupAsset(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	code := strings.TrimSpace(r.URL.Query().Get("code"))
+	if code == "" {
+		writeAPIError(w, http.StatusBadRequest, "请提供扫描到的内容 code")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	result, err := s.lookupAssets(ctx, model.ScopeFor(CurrentUser(r.Context())), code)
+	if err != nil {
+		log.Printf("扫码查询资产失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询资产失败")
+		return
+	}
+	if len(result.Assets) == 0 {
+		writeAPIError(w, http.StatusNotFound, "未找到资产: "+code)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// lookupAssets 按扫描内容查找 scope 范围内的资产，没有匹配时 Assets 为空
+func (s *Server) lookupAssets(ctx context.Context, scope model.AssetScope, code string) (lookupResult, error) {
+	result := lookupResult{Code: code, Assets: []model.Asset{}}
+	if id, ok := assetLinkID(code); ok {
+		result.MatchedBy = matchedByLink
+		asset, err := s.assets.Get(ctx, id)
+		if err == model.ErrAssetNotFound {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		if scope.Allows(asset) {
+			result.Assets = append(result.Assets, *asset)
+		}
+		return result, nil
+	}
+
+	assets, err := s.assets.FindByCode(ctx, code)
+	if err != nil {
+		return result, err
+	}
+	// 排序保证资产编码匹配的在前，有资产编码匹配时不再返回只是序列号相同的资产
+	for _, a := range assets {
+		if !scope.Allows(&a) {
+			continue
+		}
+		if result.MatchedBy == "" {
+			result.MatchedBy = matchedBySerial
+			if a.AssetCode == code {
+				result.MatchedBy = matchedByCode
+			}
+		}
+		if result.MatchedBy == matchedByCode && a.AssetCode != code {
+			break
+		}
+		result.Assets = append(result.Assets, a)
+	}
+	return result, nil
+}
+
+// assetLinkID 从资产链接（见 assetLink）中取出资产 ID。不检查主机名，
+// 更换访问地址前打印的标签仍然可用
+func assetLinkID(code string) (int, bool) {
+	u, err := url.Parse(code)
+	if err != nil || !strings.HasSuffix(u.Path, "/asset-entry") {
+		return 0, false
+	}
+	id, err := strconv.Atoi(u.Query().Get("asset"))
+	if err != nil || id < 1 {
+		return 0, false
+	}
+	return id, true
+}
+
+// apiMarkSeen 确认资产在场，记录当前时间和操作人
+func (s *Server) apiMarkSeen(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermEditAsset) {
+		return
+	}
+	if _, ok := s.apiScopedAsset(w, r, id); !ok {
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	user := CurrentUser(r.Context())
+	asset, err := s.assets.MarkSeen(ctx, model.ActorFor(user), id)
+	if err == model.ErrAssetNotFound {
+		writeAPIError(w, http.StatusNotFound, "资产不存在")
+		return
+	}
+	if err != nil {
+		log.Printf("确认资产在场失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "确认资产在场失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("资产已确认在场: id=%d, 操作人: %s", id, user.Username)
+	writeJSON(w, http.StatusOK, asset)
+}
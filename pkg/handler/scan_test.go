@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAssetLinkID(t *testing.T) {
+	tests := []struct {
+		code string
+		id   int
+		ok   bool
+	}{
+		{"https://ams.example.com/asset-entry?asset=12", 12, true},
+		{"http://10.0.0.1:8080/asset-entry?asset=3&from=label", 3, true},
+		{"/asset-entry?asset=5", 5, true},
+		// 不检查主机名和路径前缀，反向代理下的地址同样可用
+		{"https://intranet.example.com/ams/asset-entry?asset=7", 7, true},
+		{"https://ams.example.com/asset-entry?asset=0", 0, false},
+		{"https://ams.example.com/asset-entry?asset=abc", 0, false},
+		{"https://ams.example.com/asset-entry", 0, false},
+		{"https://ams.example.com/assets?asset=1", 0, false},
+		{"ZC-0001", 0, false},
+		{"%zz", 0, false},
+	}
+	for _, tt := range tests {
+		id, ok := assetLinkID(tt.code)
+		if id != tt.id || ok != tt.ok {
+			t.Errorf("assetLinkID(%q) = %d, %v，期望 %d, %v", tt.code, id, ok, tt.id, tt.ok)
+		}
+	}
+}
+
+// scanAssets 两台不同部门的资产序列号相同，另一台资产的序列号与资产 1 的资产编码相同
+func scanAssets() *fakeAssets {
+	return newFakeAssets(
+		model.Asset{ID: 1, Name: "笔记本", AssetCode: "ZC-1", SerialNumber: "SN-1", Department: "IT"},
+		model.Asset{ID: 2, Name: "笔记本", AssetCode: "ZC-2", SerialNumber: "SN-1", Department: "HR"},
+		model.Asset{ID: 3, Name: "显示器", AssetCode: "ZC-3", SerialNumber: "ZC-1", Department: "IT"},
+	)
+}
+
+func TestLookupAssets(t *testing.T) {
+	s := newTestServer(scanAssets())
+	tests := []struct {
+		name      string
+		user      *model.User
+		code      string
+		matchedBy string
+		ids       []int
+	}{
+		{"序列号重复时返回全部", testAdmin, "SN-1", matchedBySerial, []int{2, 1}},
+		{"只返回部门范围内的资产", testLead, "SN-1", matchedBySerial, []int{1}},
+		{"资产编码优先于序列号", testAdmin, "ZC-1", matchedByCode, []int{1}},
+		{"部门范围外的资产编码", testLead, "ZC-2", "", nil},
+		{"资产链接", testAdmin, "https://ams.example.com/asset-entry?asset=2", matchedByLink, []int{2}},
+		{"部门范围外的资产链接", testLead, "https://ams.example.com/asset-entry?asset=2", matchedByLink, nil},
+		{"资产链接指向不存在的资产", testAdmin, "/asset-entry?asset=99", matchedByLink, nil},
+		{"没有匹配", testAdmin, "ZC-404", "", nil},
+	}
+	for _, tt := range tests {
+		result, err := s.lookupAssets(context.Background(), model.ScopeFor(tt.user), tt.code)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var ids []int
+		for _, a := range result.Assets {
+			ids = append(ids, a.ID)
+		}
+		if result.MatchedBy != tt.matchedBy || !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("%s: 匹配方式 %q，资产 %v，期望 %q，%v", tt.name, result.MatchedBy, ids, tt.matchedBy, tt.ids)
+		}
+	}
+}
+
+func TestAPILookupAsset(t *testing.T) {
+	s := newTestServer(scanAssets())
+	tests := []struct {
+		user   *model.User
+		target string
+		want   int
+	}{
+		{testAdmin, "/api/v1/assets/lookup?code=ZC-2", http.StatusOK},
+		{testLead, "/api/v1/assets/lookup?code=ZC-2", http.StatusNotFound},
+		{testAdmin, "/api/v1/assets/lookup?code=+", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := serve(t, s.apiLookupAsset, tt.user, http.MethodGet, tt.target, ""); w.Code != tt.want {
+			t.Errorf("%s %s: 状态码 %d，期望 %d", tt.user.Username, tt.target, w.Code, tt.want)
+		}
+	}
+}
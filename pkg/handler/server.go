@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"asset-management-system/pkg/apidoc"
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/report"
+	"asset-management-system/pkg/session"
+	"asset-management-system/pkg/zpl"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server 持有处理器共享的配置、数据库连接池、模板和缓存
+type Server struct {
+	cfg           *config.Config
+	db            *sql.DB
+	assets        model.AssetRepository
+	users         model.UserRepository
+	notifications model.NotificationRepository
+	stocktakes    model.StocktakeRepository
+
+	sessions *session.Manager
+	// reports 生成 PDF 单据和报表，没有可用的中文字体时为 nil
+	reports *report.Generator
+	// zplTemplates 热敏标签打印机的 ZPL 模板
+	zplTemplates *zpl.Templates
+
+	assetEntryFullTemplate *template.Template
+	loginTemplate          *template.Template
+	usersTemplate          *template.Template
+	apiDocsTemplate        *template.Template
+	recycleBinTemplate     *template.Template
+	custodyTemplate        *template.Template
+	notificationsTemplate  *template.Template
+	scanTemplate           *template.Template
+	stocktakesTemplate     *template.Template
+
+	// 缓存所有资产（模拟缓存，实际可使用 Redis 或其他缓存系统）
+	cacheMutex sync.Mutex
+	assetCache []model.Asset
+}
+
+// NewServer 使用长期持有的连接池创建服务，解析模板并初始化资产缓存
+func NewServer(cfg *config.Config, db *sql.DB) (*Server, error) {
+	s := &Server{
+		cfg:           cfg,
+		db:            db,
+		assets:        model.NewMySQLAssetRepository(db),
+		users:         model.NewMySQLUserRepository(db),
+		notifications: model.NewMySQLNotificationRepository(db),
+		stocktakes:    model.NewMySQLStocktakeRepository(db),
+	}
+
+	sessions, err := newSessionManager(cfg.Session, db)
+	if err != nil {
+		return nil, err
+	}
+	s.sessions = sessions
+
+	log.Println("初始化资产录入、列表、登录、用户管理、回收站、在用资产、站内消息、扫码、资产盘点和接口文档模板...")
+	// 解析资产录入和列表模板
+	if s.assetEntryFullTemplate, err = parseTemplate(cfg, "asset-entry-full.html"); err != nil {
+		return nil, fmt.Errorf("解析资产录入模板失败: %w", err)
+	}
+	// 解析登录模板
+	if s.loginTemplate, err = parseTemplate(cfg, "login.html"); err != nil {
+		return nil, fmt.Errorf("解析登录模板失败: %w", err)
+	}
+	// 解析用户管理模板
+	if s.usersTemplate, err = parseTemplate(cfg, "users.html"); err != nil {
+		return nil, fmt.Errorf("解析用户管理模板失败: %w", err)
+	}
+	// 解析回收站模板
+	if s.recycleBinTemplate, err = parseTemplate(cfg, "recycle-bin.html"); err != nil {
+		return nil, fmt.Errorf("解析回收站模板失败: %w", err)
+	}
+	// 解析在用资产模板
+	if s.custodyTemplate, err = parseTemplate(cfg, "custody.html"); err != nil {
+		return nil, fmt.Errorf("解析在用资产模板失败: %w", err)
+	}
+	// 解析站内消息模板
+	if s.notificationsTemplate, err = parseTemplate(cfg, "notifications.html"); err != nil {
+		return nil, fmt.Errorf("解析站内消息模板失败: %w", err)
+	}
+	// 解析扫码模板
+	if s.scanTemplate, err = parseTemplate(cfg, "scan.html"); err != nil {
+		return nil, fmt.Errorf("解析扫码模板失败: %w", err)
+	}
+	// 解析资产盘点模板
+	if s.stocktakesTemplate, err = parseTemplate(cfg, "stocktakes.html"); err != nil {
+		return nil, fmt.Errorf("解析资产盘点模板失败: %w", err)
+	}
+	// 解析接口文档模板
+	if s.apiDocsTemplate, err = parseTemplate(cfg, "api-docs.html"); err != nil {
+		return nil, fmt.Errorf("解析接口文档模板失败: %w", err)
+	}
+	log.Println("模板初始化成功")
+
+	// 初始化缓存
+	if cfg.Cache.Enabled {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.QueryTimeout)
+		s.loadAssetCache(ctx)
+		cancel()
+	} else {
+		log.Println("资产缓存已禁用，列表将直接查询数据库")
+	}
+
+	s.reports, err = report.New(cfg.Reports)
+	if errors.Is(err, report.ErrNoFont) {
+		log.Printf("警告: %v，PDF 单据和报表不可用", err)
+	} else if err != nil {
+		return nil, err
+	} else {
+		log.Printf("PDF 字体: %s", s.reports.FontPath())
+	}
+
+	s.zplTemplates, err = zpl.LoadTemplates(cfg.Printers.TemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("加载 ZPL 标签模板失败: %w", err)
+	}
+
+	if err := s.checkAPIDoc(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newSessionManager(cfg config.SessionConfig, db *sql.DB) (*session.Manager, error) {
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("session.secret 未配置（可使用配置文件或 AMS_SESSION_SECRET 指定，至少 %d 字节）", config.MinSessionSecretLength)
+	}
+	var store session.Store
+	if cfg.Store == "memory" {
+		store = session.NewMemoryStore()
+	} else {
+		store = session.NewMySQLStore(db)
+	}
+	sameSite := map[string]http.SameSite{
+		"lax":    http.SameSiteLaxMode,
+		"strict": http.SameSiteStrictMode,
+		"none":   http.SameSiteNoneMode,
+	}[cfg.SameSite]
+	return session.NewManager(store, session.Options{
+		CookieName:      cfg.CookieName,
+		Secret:          []byte(cfg.Secret),
+		Secure:          cfg.Secure,
+		SameSite:        sameSite,
+		IdleTimeout:     cfg.IdleTimeout,
+		AbsoluteTimeout: cfg.AbsoluteTimeout,
+	}), nil
+}
+
+func parseTemplate(cfg *config.Config, name string) (*template.Template, error) {
+	path := filepath.Join(cfg.Server.TemplateDir, name)
+	log.Printf("尝试解析模板文件: %s", path)
+	return template.ParseFiles(path)
+}
+
+// Routes 注册全部路由，除公开路径外都需要登录
+func (s *Server) Routes() http.Handler {
+	mux, _ := s.routes()
+	return s.withSession(s.requireAuth(mux))
+}
+
+// routes 创建路由表，同时返回需要出现在 OpenAPI 文档中的接口。
+// 注册时列出处理函数接受的每个接口，格式为“方法 相对路径”，相对路径拼接在路由模式之后；
+// 以 / 结尾的前缀路由需要列出它分发的全部子路径
+func (s *Server) routes() (*http.ServeMux, []apidoc.Endpoint) {
+	mux := http.NewServeMux()
+	var endpoints []apidoc.Endpoint
+	handle := func(pattern string, h http.HandlerFunc, accepts ...string) {
+		if len(accepts) == 0 {
+			panic("路由 " + pattern + " 没有列出接口")
+		}
+		for _, accept := range accepts {
+			method, rel := accept, ""
+			if i := strings.IndexByte(accept, ' '); i >= 0 {
+				method, rel = accept[:i], accept[i+1:]
+			}
+			endpoints = append(endpoints, apidoc.Endpoint{Method: method, Path: pattern + rel})
+		}
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			log.Printf("路由 %s 触发，方法: %s, URL: %s", pattern, r.Method, r.URL.Path)
+			h(w, r)
+		})
+	}
+
+	handle("/login", s.LoginHandler, "GET", "POST")
+	handle("/logout", s.LogoutHandler, "POST")
+	handle("/asset-entry", s.AssetEntryHandler, "GET", "POST", "DELETE")
+	handle("/assets/list", s.AssetListHandler, "GET")
+	handle("/assets/export", s.AssetExportHandler, "GET")
+	handle("/assets/labels", s.AssetLabelsHandler, "GET")
+	handle("/users", s.UsersHandler, "GET", "POST", "DELETE")
+	handle("/users/list", s.UserListHandler, "GET")
+	handle(apiAssetsPrefix, s.APIAssetsHandler, "GET", "POST")
+	handle(apiAssetsPrefix+"/", s.APIAssetsHandler,
+		"POST import", "GET lookup",
+		"GET {id}", "PUT {id}", "PATCH {id}", "DELETE {id}",
+		"GET {id}/history", "POST {id}/transitions",
+		"POST {id}/checkout", "POST {id}/checkin", "GET {id}/custody", "GET {id}/receipt",
+		"GET {id}/barcode", "GET {id}/zpl", "POST {id}/seen")
+	handle("/recycle-bin", s.RecycleBinHandler, "GET")
+	handle(apiRecycleBinPrefix, s.APIRecycleBinHandler, "GET")
+	handle(apiRecycleBinPrefix+"/", s.APIRecycleBinHandler, "DELETE {id}", "POST {id}/restore")
+	handle("/custody", s.CustodyHandler, "GET")
+	handle(apiCustodyPrefix, s.APICustodyHandler, "GET")
+	handle(apiCustodyPrefix+"/", s.APICustodyHandler, "GET assets")
+	handle("/notifications", s.NotificationsHandler, "GET")
+	handle("/scan", s.ScanHandler, "GET")
+	handle("/stocktakes", s.StocktakesHandler, "GET")
+	handle(apiStocktakesPrefix, s.APIStocktakesHandler, "GET", "POST")
+	handle(apiStocktakesPrefix+"/", s.APIStocktakesHandler,
+		"GET {id}", "POST {id}/find", "POST {id}/close", "GET {id}/report", "POST {id}/apply")
+	handle(apiNotificationsPrefix, s.APINotificationsHandler, "GET")
+	handle(apiNotificationsPrefix+"/", s.APINotificationsHandler, "POST {id}/read", "POST read-all")
+	handle(apiPrintersPrefix, s.APIPrintersHandler, "GET")
+	handle(apiPrintersPrefix+"/", s.APIPrintersHandler, "POST print")
+	handle("/api/openapi.json", s.OpenAPIHandler, "GET")
+	handle("/docs/api", s.APIDocsHandler, "GET")
+
+	// 根路径 / 路由，登录检查由 requireAuth 统一处理，已登录用户渲染资产录入页面
+	handle("/", s.AssetEntryHandler, "GET")
+
+	// 未知的接口路径返回 JSON 404，而不是落到根路径渲染页面
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("路由 /api/ 触发，方法: %s, URL: %s", r.Method, r.URL.Path)
+		writeAPIError(w, http.StatusNotFound, "接口不存在")
+	})
+
+	// 自定义静态文件服务
+	fs := http.FileServer(http.Dir(s.cfg.Server.StaticDir))
+	mux.Handle("/static/", http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("服务静态文件: %s", r.URL.Path)
+		fs.ServeHTTP(w, r)
+	})))
+
+	return mux, endpoints
+}
+
+// checkAPIDoc 确认每个注册的接口都写进了 OpenAPI 文档
+func (s *Server) checkAPIDoc() error {
+	_, endpoints := s.routes()
+	missing, err := apidoc.Undocumented(endpoints)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, e := range missing {
+			names[i] = e.String()
+		}
+		return fmt.Errorf("以下接口没有写入 pkg/apidoc/openapi.json: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// queryContext 返回绑定请求取消并带有查询超时的 context
+func (s *Server) queryContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), s.cfg.Database.QueryTimeout)
+}
+
+// importContext 返回批量导入使用的 context，超时时间为 database.import_timeout
+func (s *Server) importContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), s.cfg.Database.ImportTimeout)
+}
+
+// CleanupSessions 定期清理过期会话，ctx 取消后退出
+func (s *Server) CleanupSessions(ctx context.Context) {
+	s.sessions.RunCleanup(ctx, s.cfg.Session.CleanupInterval)
+}
+
+// RefreshAssetCache 定时重新加载资产缓存，用于感知其他实例或直接修改数据库造成的变更，ctx 取消后退出
+func (s *Server) RefreshAssetCache(ctx context.Context) {
+	if !s.cfg.Cache.Enabled || s.cfg.Cache.RefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.Cache.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			loadCtx, cancel := context.WithTimeout(ctx, s.cfg.Database.QueryTimeout)
+			s.loadAssetCache(loadCtx)
+			cancel()
+		}
+	}
+}
+
+func (s *Server) loadAssetCache(ctx context.Context) {
+	if !s.cfg.Cache.Enabled {
+		return
+	}
+	assets, err := s.assets.List(ctx)
+	if err != nil {
+		log.Printf("查询所有资产失败: %v", err)
+		return
+	}
+
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	s.assetCache = assets
+	log.Println("资产缓存加载成功")
+}
+
+// cachedAssets 返回缓存的副本
+func (s *Server) cachedAssets() []model.Asset {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	assets := make([]model.Asset, len(s.assetCache))
+	copy(assets, s.assetCache)
+	return assets
+}
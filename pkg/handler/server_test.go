@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"asset-management-system/pkg/apidoc"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRoutesDocumented 确认注册的接口与 OpenAPI 文档一一对应
+func TestRoutesDocumented(t *testing.T) {
+	s := newTestServer(testAssets())
+	_, endpoints := s.routes()
+	seen := map[apidoc.Endpoint]bool{}
+	for _, e := range endpoints {
+		if seen[e] {
+			t.Errorf("接口重复注册: %s", e)
+		}
+		seen[e] = true
+	}
+	undocumented, err := apidoc.Undocumented(endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range undocumented {
+		t.Errorf("接口没有写入文档: %s", e)
+	}
+	unregistered, err := apidoc.Unregistered(endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range unregistered {
+		t.Errorf("文档中的接口没有注册: %s", e)
+	}
+}
+
+// TestRoutesDispatch 以管理员身份请求列出的每个 JSON 接口，确认都能分发到处理函数，
+// 而不是返回 405 或“接口不存在”。测试服务器没有配置的存储在被调用时 panic，
+// 说明请求已经到达处理函数，同样视为分发成功
+func TestRoutesDispatch(t *testing.T) {
+	s := newTestServer(testAssets())
+	mux, endpoints := s.routes()
+	dispatch := func(method, target string) (w *httptest.ResponseRecorder, reached bool) {
+		defer func() {
+			if recover() != nil {
+				reached = true
+			}
+		}()
+		r := httptest.NewRequest(method, target, nil)
+		r = r.WithContext(context.WithValue(r.Context(), userContextKey, testAdmin))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w, w.Code != http.StatusMethodNotAllowed && !strings.Contains(w.Body.String(), "接口不存在")
+	}
+	for _, e := range endpoints {
+		if !strings.HasPrefix(e.Path, "/api/v1/") {
+			continue
+		}
+		target := strings.ReplaceAll(e.Path, "{id}", "1")
+		if w, reached := dispatch(e.Method, target); !reached {
+			t.Errorf("%s: 状态码 %d: %s", e, w.Code, w.Body.String())
+		}
+		// 未列出的方法不应被处理，确认上面的检查能发现分发错误
+		if _, reached := dispatch(http.MethodOptions, target); reached {
+			t.Errorf("OPTIONS %s 不应被处理", target)
+		}
+	}
+}
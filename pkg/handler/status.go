@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// transitionRequest 状态转换请求体，version 为读取资产时的版本，省略表示不做检查
+type transitionRequest struct {
+	To      model.AssetStatus `json:"to"`
+	Reason  string            `json:"reason"`
+	Version int               `json:"version"`
+}
+
+// transitionRejected 422 响应体：当前状态和允许转换到的状态
+type transitionRejected struct {
+	Error   string              `json:"error"`
+	From    model.AssetStatus   `json:"from"`
+	To      model.AssetStatus   `json:"to"`
+	Allowed []model.AssetStatus `json:"allowed"`
+}
+
+// apiTransitionAsset 按状态机转换资产状态，原因和操作人写入变更记录
+func (s *Server) apiTransitionAsset(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermEditAsset) {
+		return
+	}
+	var req transitionRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !req.To.Valid() {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("无效的目标状态: %s", req.To))
+		return
+	}
+	if req.Reason == "" {
+		writeAPIError(w, http.StatusBadRequest, "请填写状态变更原因")
+		return
+	}
+	if utf8.RuneCountInString(req.Reason) > 255 {
+		writeAPIError(w, http.StatusBadRequest, "状态变更原因不能超过 255 个字符")
+		return
+	}
+	if _, ok := s.apiScopedAsset(w, r, id); !ok {
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	asset, err := s.assets.Transition(ctx, model.ActorFor(CurrentUser(r.Context())), id, req.To, req.Reason, req.Version)
+	if err != nil {
+		writeLifecycleError(w, err, "资产状态变更失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+
+	log.Printf("资产状态已变更: id=%d, 状态: %s, 原因: %s", id, asset.Status, req.Reason)
+	writeJSON(w, http.StatusOK, asset)
+}
+
+// writeLifecycleError 把状态变更、领用和归还的错误写成接口响应：
+// 资产不存在 404，版本冲突 409，状态机或保管状态不允许 422，其他错误记录日志后返回 500
+func writeLifecycleError(w http.ResponseWriter, err error, message string) {
+	var conflict *model.VersionConflictError
+	var rejected *model.TransitionError
+	switch {
+	case err == model.ErrAssetNotFound:
+		writeAPIError(w, http.StatusNotFound, "资产不存在")
+	case errors.As(err, &conflict):
+		writeConflict(w, conflict)
+	case errors.As(err, &rejected):
+		writeJSON(w, http.StatusUnprocessableEntity, transitionRejected{
+			Error:   rejected.Error(),
+			From:    rejected.From,
+			To:      rejected.To,
+			Allowed: rejected.From.Next(),
+		})
+	case errors.Is(err, model.ErrCheckedOut), err == model.ErrNotCheckedOut:
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		log.Printf("%s: %v", message, err)
+		writeAPIError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// parseStatusFilter 解析逗号分隔的 status 参数，空字符串表示不过滤
+func parseStatusFilter(v string) ([]model.AssetStatus, error) {
+	var statuses []model.AssetStatus
+	for _, part := range strings.Split(v, ",") {
+		status := model.AssetStatus(strings.TrimSpace(part))
+		if status == "" {
+			continue
+		}
+		if !status.Valid() {
+			return nil, fmt.Errorf("无效的资产状态: %s", status)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// filterByStatus 返回处于指定状态之一的资产，statuses 为空时原样返回
+func filterByStatus(assets []model.Asset, statuses []model.AssetStatus) []model.Asset {
+	if len(statuses) == 0 {
+		return assets
+	}
+	filtered := []model.Asset{}
+	for _, a := range assets {
+		if statusIn(a.Status, statuses) {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered
+}
+
+// statusIn 判断 status 是否在 statuses 中，statuses 为空时视为不筛选
+func statusIn(status model.AssetStatus, statuses []model.AssetStatus) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// statusLabels 状态的中文名称，供页面脚本使用
+func statusLabels() map[model.AssetStatus]string {
+	labels := map[model.AssetStatus]string{}
+	for _, status := range model.Statuses() {
+		labels[status] = status.Label()
+	}
+	return labels
+}
+
+// statusTransitions 各状态允许转换到的状态，供页面脚本使用
+func statusTransitions() map[model.AssetStatus][]model.AssetStatus {
+	transitions := map[model.AssetStatus][]model.AssetStatus{}
+	for _, status := range model.Statuses() {
+		transitions[status] = status.Next()
+	}
+	return transitions
+}
@@ -0,0 +1,513 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/report"
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// apiStocktakesPrefix 盘点资源路径
+const apiStocktakesPrefix = "/api/v1/stocktakes"
+
+// createStocktakeRequest 新建盘点的请求体，department 和 location 为盘点范围
+type createStocktakeRequest struct {
+	Name       string `json:"name"`
+	Department string `json:"department"`
+	Location   string `json:"location"`
+	Note       string `json:"note"`
+}
+
+// findRequest 登记盘到资产的请求体：扫码时提交 code，在清单中确认时提交 asset_id。
+// location 为实际所在地，为空表示与登记的一致
+type findRequest struct {
+	AssetID  int    `json:"asset_id"`
+	Code     string `json:"code"`
+	Location string `json:"location"`
+	Note     string `json:"note"`
+}
+
+// applyRequest 更正所在地的请求体
+type applyRequest struct {
+	AssetIDs []int `json:"asset_ids"`
+}
+
+// stocktakeReport 盘点差异报告
+type stocktakeReport struct {
+	Stocktake *model.Stocktake `json:"stocktake"`
+	model.StocktakeReport
+}
+
+// StocktakesHandler 渲染资产盘点页面
+func (s *Server) StocktakesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理资产盘点页面请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermStocktake) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
+	}
+	user := CurrentUser(r.Context())
+	scope := model.ScopeFor(user)
+	data := struct {
+		Perms        pagePermissions
+		AllScope     bool
+		Departments  []string
+		StatusLabels map[model.AssetStatus]string
+	}{
+		Perms:        permissionsFor(user),
+		AllScope:     scope.All,
+		Departments:  scope.Departments,
+		StatusLabels: statusLabels(),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.stocktakesTemplate.Execute(w, data); err != nil {
+		log.Printf("渲染资产盘点页面失败: %v", err)
+		http.Error(w, "渲染资产盘点页面失败", http.StatusInternalServerError)
+	}
+}
+
+// APIStocktakesHandler 处理 /api/v1/stocktakes 资源：
+//
+//	GET  /api/v1/stocktakes               盘点列表，按创建时间倒序
+//	POST /api/v1/stocktakes               新建盘点，登记范围内的资产
+//	GET  /api/v1/stocktakes/{id}          盘点及明细
+//	POST /api/v1/stocktakes/{id}/find     登记盘到的资产（扫码或手动确认）
+//	POST /api/v1/stocktakes/{id}/close    结束盘点
+//	GET  /api/v1/stocktakes/{id}/report   差异报告：缺失、范围外和位置变化，format=pdf 时生成 PDF
+//	POST /api/v1/stocktakes/{id}/apply    按实盘所在地更正资产所在地
+//
+// 部门范围受限的用户只能看到和新建本部门的盘点
+func (s *Server) APIStocktakesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理盘点接口请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermStocktake) {
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, apiStocktakesPrefix), "/")
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			s.apiListStocktakes(w, r)
+		case http.MethodPost:
+			s.apiCreateStocktake(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		}
+		return
+	}
+
+	idPart, sub := rest, ""
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		idPart, sub = rest[:i], rest[i+1:]
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil || id < 1 {
+		writeAPIError(w, http.StatusNotFound, "盘点不存在")
+		return
+	}
+	method := map[string]string{"": http.MethodGet, "find": http.MethodPost, "close": http.MethodPost, "report": http.MethodGet, "apply": http.MethodPost}
+	allowed, ok := method[sub]
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "接口不存在")
+		return
+	}
+	if r.Method != allowed {
+		w.Header().Set("Allow", allowed)
+		writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		return
+	}
+	st, ok := s.scopedStocktake(w, r, id)
+	if !ok {
+		return
+	}
+	switch sub {
+	case "":
+		s.apiGetStocktake(w, r, st)
+	case "find":
+		s.apiFindAsset(w, r, st)
+	case "close":
+		s.apiCloseStocktake(w, r, st)
+	case "report":
+		s.apiStocktakeReport(w, r, st)
+	case "apply":
+		s.apiApplyLocations(w, r, st)
+	}
+}
+
+// stocktakeVisible 判断用户能否看到盘点：不受部门限制的用户可见全部盘点，其他用户只能看到本部门的盘点
+func stocktakeVisible(scope model.AssetScope, st *model.Stocktake) bool {
+	return scope.All || (st.Department != "" && scope.Includes(st.Department))
+}
+
+// scopedStocktake 读取用户可见的盘点，不存在或不可见时写入 404 并返回 false
+func (s *Server) scopedStocktake(w http.ResponseWriter, r *http.Request, id int) (*model.Stocktake, bool) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	st, err := s.stocktakes.Get(ctx, id)
+	if err == model.ErrStocktakeNotFound || (err == nil && !stocktakeVisible(model.ScopeFor(CurrentUser(r.Context())), st)) {
+		writeAPIError(w, http.StatusNotFound, "盘点不存在")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("查询盘点失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询盘点失败")
+		return nil, false
+	}
+	return st, true
+}
+
+func (s *Server) apiListStocktakes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	all, err := s.stocktakes.List(ctx)
+	if err != nil {
+		log.Printf("查询盘点列表失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询盘点列表失败")
+		return
+	}
+	scope := model.ScopeFor(CurrentUser(r.Context()))
+	stocktakes := []model.Stocktake{}
+	for i := range all {
+		if stocktakeVisible(scope, &all[i]) {
+			stocktakes = append(stocktakes, all[i])
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"stocktakes": stocktakes})
+}
+
+func (s *Server) apiCreateStocktake(w http.ResponseWriter, r *http.Request) {
+	var req createStocktakeRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	st := &model.Stocktake{
+		Name:       strings.TrimSpace(req.Name),
+		Department: strings.TrimSpace(req.Department),
+		Location:   strings.TrimSpace(req.Location),
+		Note:       strings.TrimSpace(req.Note),
+	}
+	switch {
+	case st.Name == "":
+		writeAPIError(w, http.StatusBadRequest, "请填写盘点名称")
+		return
+	case utf8.RuneCountInString(st.Name) > 100:
+		writeAPIError(w, http.StatusBadRequest, "盘点名称不能超过 100 个字符")
+		return
+	case utf8.RuneCountInString(st.Department) > 100 || utf8.RuneCountInString(st.Location) > 100:
+		writeAPIError(w, http.StatusBadRequest, "部门和所在地不能超过 100 个字符")
+		return
+	case utf8.RuneCountInString(st.Note) > 255:
+		writeAPIError(w, http.StatusBadRequest, "说明不能超过 255 个字符")
+		return
+	}
+	user := CurrentUser(r.Context())
+	scope := model.ScopeFor(user)
+	if !scope.All && st.Department == "" {
+		writeAPIError(w, http.StatusBadRequest, "请选择盘点的部门")
+		return
+	}
+	if !scope.Includes(st.Department) && !scope.All {
+		writeAPIError(w, http.StatusForbidden, "无权盘点该部门的资产")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	if err := s.stocktakes.Create(ctx, model.ActorFor(user), st); err != nil {
+		log.Printf("新建盘点失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "新建盘点失败")
+		return
+	}
+
+	log.Printf("盘点已开始: id=%d, 名称: %s, 部门: %s, 所在地: %s, 应盘 %d 项, 操作人: %s", st.ID, st.Name, st.Department, st.Location, st.Expected, user.Username)
+	writeJSON(w, http.StatusCreated, st)
+}
+
+// stocktakeItems 读取盘点明细，失败时写入 500 并返回 false
+func (s *Server) stocktakeItems(w http.ResponseWriter, r *http.Request, st *model.Stocktake) ([]model.StocktakeItem, bool) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	items, err := s.stocktakes.Items(ctx, st.ID)
+	if err != nil {
+		log.Printf("查询盘点明细失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询盘点明细失败")
+		return nil, false
+	}
+	return items, true
+}
+
+func (s *Server) apiGetStocktake(w http.ResponseWriter, r *http.Request, st *model.Stocktake) {
+	items, ok := s.stocktakeItems(w, r, st)
+	if !ok {
+		return
+	}
+	scope := model.ScopeFor(CurrentUser(r.Context()))
+	for i := range items {
+		scopeItem(scope, &items[i])
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"stocktake": st, "items": items})
+}
+
+// scopeItem 部门范围外的资产只保留识别资产所需的字段，不显示领用人、供应商等内容
+func scopeItem(scope model.AssetScope, item *model.StocktakeItem) {
+	a := item.Asset
+	if scope.Allows(&a) {
+		return
+	}
+	item.Asset = model.Asset{ID: a.ID, SerialNumber: a.SerialNumber, Name: a.Name, Category: a.Category, AssetCode: a.AssetCode,
+		Department: a.Department, Location: a.Location, Status: a.Status}
+}
+
+// apiFindAsset 登记盘到的资产。扫描内容匹配多项资产时返回 409 和候选资产，由用户选择后按 asset_id 重新提交。
+// 扫码时在全部资产中查找：实物出现在盘点现场就应当记录，部门范围外的资产登记为范围外资产，只返回识别资产所需的字段
+func (s *Server) apiFindAsset(w http.ResponseWriter, r *http.Request, st *model.Stocktake) {
+	var req findRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	f := model.StocktakeFind{Location: strings.TrimSpace(req.Location), Note: strings.TrimSpace(req.Note), Method: model.FoundManually}
+	switch {
+	case req.AssetID == 0 && req.Code == "":
+		writeAPIError(w, http.StatusBadRequest, "请提供 asset_id 或扫描到的内容 code")
+		return
+	case utf8.RuneCountInString(f.Location) > 100:
+		writeAPIError(w, http.StatusBadRequest, "所在地不能超过 100 个字符")
+		return
+	case utf8.RuneCountInString(f.Note) > 255:
+		writeAPIError(w, http.StatusBadRequest, "说明不能超过 255 个字符")
+		return
+	}
+	if st.Status != model.StocktakeOpen {
+		writeAPIError(w, http.StatusConflict, model.ErrStocktakeClosed.Error())
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	user := CurrentUser(r.Context())
+	assetID := req.AssetID
+	if assetID == 0 {
+		f.Method = model.FoundByScan
+		result, err := s.lookupAssets(ctx, model.AllAssets, req.Code)
+		if err != nil {
+			log.Printf("扫码查询资产失败: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "查询资产失败")
+			return
+		}
+		// 范围内的资产优先，只有范围外的资产匹配时才登记范围外的资产
+		inScope := model.ScopeFor(user).Filter(result.Assets)
+		switch {
+		case len(result.Assets) == 0:
+			writeAPIError(w, http.StatusNotFound, "未找到资产: "+req.Code)
+			return
+		case len(inScope) == 1:
+			assetID = inScope[0].ID
+		case len(inScope) > 1:
+			writeJSON(w, http.StatusConflict, map[string]interface{}{
+				"error":  fmt.Sprintf("有 %d 项资产与 %s 匹配，请选择", len(inScope), req.Code),
+				"assets": inScope,
+			})
+			return
+		case len(result.Assets) == 1:
+			assetID = result.Assets[0].ID
+		default:
+			// 候选资产不在用户范围内，无法按 asset_id 选择
+			writeAPIError(w, http.StatusConflict, fmt.Sprintf("有 %d 项部门范围外的资产与 %s 匹配，请扫描资产标签上的二维码或资产编码", len(result.Assets), req.Code))
+			return
+		}
+	} else if _, ok := s.apiScopedAsset(w, r, assetID); !ok {
+		return
+	}
+
+	item, duplicate, err := s.stocktakes.Find(ctx, model.ActorFor(user), st.ID, assetID, f)
+	switch {
+	case err == model.ErrAssetNotFound:
+		writeAPIError(w, http.StatusNotFound, "资产不存在")
+		return
+	case err == model.ErrStocktakeClosed:
+		writeAPIError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("登记盘点资产失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "登记盘点资产失败")
+		return
+	}
+	s.loadAssetCache(ctx)
+	scopeItem(model.ScopeFor(user), item)
+
+	log.Printf("盘点 %d 登记资产: id=%d, 方式: %s, 范围内: %t, 重复: %t, 操作人: %s", st.ID, assetID, f.Method, item.Expected, duplicate, user.Username)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"item": item, "duplicate": duplicate})
+}
+
+func (s *Server) apiCloseStocktake(w http.ResponseWriter, r *http.Request, st *model.Stocktake) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	user := CurrentUser(r.Context())
+	err := s.stocktakes.Close(ctx, model.ActorFor(user), st.ID)
+	if err == model.ErrStocktakeClosed {
+		writeAPIError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("结束盘点失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "结束盘点失败")
+		return
+	}
+	st, err = s.stocktakes.Get(ctx, st.ID)
+	if err != nil {
+		log.Printf("查询盘点失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "查询盘点失败")
+		return
+	}
+
+	log.Printf("盘点已结束: id=%d, 应盘 %d 项, 已盘 %d 项, 范围外 %d 项, 操作人: %s", st.ID, st.Expected, st.Found, st.Unexpected, user.Username)
+	writeJSON(w, http.StatusOK, st)
+}
+
+// apiStocktakeReport 返回盘点差异报告，format=pdf 时生成可打印的差异表
+func (s *Server) apiStocktakeReport(w http.ResponseWriter, r *http.Request, st *model.Stocktake) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "pdf" {
+		writeAPIError(w, http.StatusBadRequest, "format 只能是 json 或 pdf")
+		return
+	}
+	if format == "pdf" && s.reports == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, errNoReports.Error())
+		return
+	}
+	items, ok := s.stocktakeItems(w, r, st)
+	if !ok {
+		return
+	}
+	result := stocktakeReport{Stocktake: st, StocktakeReport: model.Reconcile(items)}
+	scope := model.ScopeFor(CurrentUser(r.Context()))
+	for _, group := range [][]model.StocktakeItem{result.Missing, result.Unexpected, result.Relocated} {
+		for i := range group {
+			scopeItem(scope, &group[i])
+		}
+	}
+	if format != "pdf" {
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := s.writeStocktakePDF(&buf, result); err != nil {
+		log.Printf("生成盘点差异报告失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "生成盘点差异报告失败")
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	setAttachment(w, fmt.Sprintf("盘点差异-%s.pdf", st.Name), fmt.Sprintf("stocktake-%d.pdf", st.ID))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("写入盘点差异报告失败: %v", err)
+	}
+}
+
+// stocktakeReportColumns 盘点差异表的列
+var stocktakeReportColumns = []report.Column{
+	{Label: "差异", Weight: 0.8},
+	{Label: "资产编码", Weight: 1.3},
+	{Label: "序列号", Weight: 1.3},
+	{Label: "资产名称", Weight: 1.6},
+	{Label: "状态", Weight: 0.7},
+	{Label: "领用人", Weight: 0.8},
+	{Label: "部门", Weight: 1},
+	{Label: "登记所在地", Weight: 1.2},
+	{Label: "实盘所在地", Weight: 1.2},
+	{Label: "盘点人", Weight: 0.8},
+	{Label: "盘点时间", Weight: 1.2},
+	{Label: "说明", Weight: 1.4},
+}
+
+// writeStocktakePDF 按缺失、范围外、位置变化的顺序写出差异表
+func (s *Server) writeStocktakePDF(w *bytes.Buffer, result stocktakeReport) error {
+	st := result.Stocktake
+	scope := []string{}
+	if st.Department != "" {
+		scope = append(scope, "部门 "+st.Department)
+	}
+	if st.Location != "" {
+		scope = append(scope, "所在地 "+st.Location)
+	}
+	if len(scope) == 0 {
+		scope = append(scope, "全部资产")
+	}
+	subtitle := fmt.Sprintf("盘点：%s；范围：%s；开始：%s %s；应盘 %d 项，已盘 %d 项，缺失 %d 项，范围外 %d 项，位置变化 %d 项；打印时间：%s",
+		st.Name, strings.Join(scope, "，"), st.CreatedAt, st.CreatedByName, st.Expected, st.Found,
+		len(result.Missing), len(result.Unexpected), len(result.Relocated), time.Now().Format(model.DateTimeLayout))
+
+	table := s.reports.NewTable("资产盘点差异表", subtitle, stocktakeReportColumns)
+	groups := []struct {
+		label string
+		items []model.StocktakeItem
+	}{
+		{"缺失", result.Missing},
+		{"范围外", result.Unexpected},
+		{"位置变化", result.Relocated},
+	}
+	for _, g := range groups {
+		for _, item := range g.items {
+			a := item.Asset
+			err := table.Row([]string{g.label, a.AssetCode, a.SerialNumber, a.Name, a.Status.Label(), a.Recipient, a.Department,
+				a.Location, item.FoundLocation, item.FoundByName, item.FoundAt, item.Note})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return table.Write(w)
+}
+
+// apiApplyLocations 把选中的位置变化资产所在地更正为实盘所在地，需要编辑权限
+func (s *Server) apiApplyLocations(w http.ResponseWriter, r *http.Request, st *model.Stocktake) {
+	if !s.authorize(w, r, model.PermEditAsset) {
+		return
+	}
+	var req applyRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if len(req.AssetIDs) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "请选择要更正所在地的资产")
+		return
+	}
+
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	user := CurrentUser(r.Context())
+	scope := model.ScopeFor(user)
+	// 只更正用户部门范围内的资产
+	var ids []int
+	for _, id := range req.AssetIDs {
+		asset, err := s.assets.Get(ctx, id)
+		if err == nil && scope.Allows(asset) {
+			ids = append(ids, id)
+		} else if err != nil && err != model.ErrAssetNotFound {
+			log.Printf("查询资产失败: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "查询资产失败")
+			return
+		}
+	}
+	applied, err := s.stocktakes.ApplyLocations(ctx, model.ActorFor(user), st.ID, ids)
+	if err != nil {
+		log.Printf("更正资产所在地失败: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "更正资产所在地失败")
+		return
+	}
+	if len(applied) > 0 {
+		s.loadAssetCache(ctx)
+	}
+
+	log.Printf("盘点 %d 更正资产所在地: %v, 操作人: %s", st.ID, applied, user.Username)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"applied": applied, "skipped": len(req.AssetIDs) - len(applied)})
+}
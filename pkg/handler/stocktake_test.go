@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"asset-management-system/pkg/model"
+	"net/http"
+	"testing"
+)
+
+// stocktakeServer IT 部门进行中的盘点 1，应盘资产 1 和 3。资产 2 与资产 1 的序列号相同，
+// 资产 4 和 5 是序列号相同的 HR 部门资产
+func stocktakeServer(status model.StocktakeStatus) *Server {
+	assets := newFakeAssets(
+		model.Asset{ID: 1, Name: "笔记本", AssetCode: "ZC-1", SerialNumber: "SN-1", Department: "IT", Location: "北京总部 5F"},
+		model.Asset{ID: 2, Name: "笔记本", AssetCode: "ZC-2", SerialNumber: "SN-1", Department: "HR", Location: "上海",
+			Supplier: "联想", Recipient: "王五", RecipientDepartment: "HR", Status: model.StatusInUse},
+		model.Asset{ID: 3, Name: "显示器", AssetCode: "ZC-3", SerialNumber: "SN-3", Department: "IT", Location: "北京总部 5F"},
+		model.Asset{ID: 4, Name: "打印机", AssetCode: "ZC-4", SerialNumber: "SN-4", Department: "HR"},
+		model.Asset{ID: 5, Name: "打印机", AssetCode: "ZC-5", SerialNumber: "SN-4", Department: "HR"},
+	)
+	s := newTestServer(assets)
+	s.stocktakes = newFakeStocktakes(assets, model.Stocktake{ID: 1, Name: "IT 年度盘点", Department: "IT", Status: status}, 1, 3)
+	return s
+}
+
+// findResponse 登记盘到资产的响应
+type findResponse struct {
+	Item      model.StocktakeItem `json:"item"`
+	Duplicate bool                `json:"duplicate"`
+	Assets    []model.Asset       `json:"assets"`
+}
+
+func TestStocktakeFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *model.User
+		body     string
+		code     int
+		assetID  int
+		expected bool
+		matches  int
+	}{
+		{"扫描应盘资产", testLead, `{"code":"ZC-1"}`, http.StatusOK, 1, true, 0},
+		{"部门范围外的资产登记为范围外", testLead, `{"code":"ZC-2"}`, http.StatusOK, 2, false, 0},
+		{"部门范围外的资产链接", testLead, `{"code":"https://ams.example.com/asset-entry?asset=2"}`, http.StatusOK, 2, false, 0},
+		{"序列号重复时优先范围内的资产", testLead, `{"code":"SN-1"}`, http.StatusOK, 1, true, 0},
+		{"序列号重复时返回候选资产", testAdmin, `{"code":"SN-1"}`, http.StatusConflict, 0, false, 2},
+		{"范围外资产的序列号重复时不返回候选资产", testLead, `{"code":"SN-4"}`, http.StatusConflict, 0, false, 0},
+		{"没有匹配", testLead, `{"code":"ZC-404"}`, http.StatusNotFound, 0, false, 0},
+		{"手动确认只能选择范围内的资产", testLead, `{"asset_id":2}`, http.StatusNotFound, 0, false, 0},
+		{"手动确认", testLead, `{"asset_id":3}`, http.StatusOK, 3, true, 0},
+		{"缺少 code 和 asset_id", testLead, `{}`, http.StatusBadRequest, 0, false, 0},
+	}
+	for _, tt := range tests {
+		s := stocktakeServer(model.StocktakeOpen)
+		w := serve(t, s.APIStocktakesHandler, tt.user, http.MethodPost, "/api/v1/stocktakes/1/find", tt.body)
+		if w.Code != tt.code {
+			t.Errorf("%s: 状态码 %d，期望 %d: %s", tt.name, w.Code, tt.code, w.Body.String())
+			continue
+		}
+		if w.Code != http.StatusOK && w.Code != http.StatusConflict {
+			continue
+		}
+		var resp findResponse
+		decodeResponse(t, w, &resp)
+		if len(resp.Assets) != tt.matches {
+			t.Errorf("%s: 候选资产 %d 项，期望 %d 项", tt.name, len(resp.Assets), tt.matches)
+		}
+		if w.Code == http.StatusOK && (resp.Item.AssetID != tt.assetID || resp.Item.Expected != tt.expected || resp.Duplicate) {
+			t.Errorf("%s: 登记资产 %d，范围内 %t，重复 %t，期望 %d，%t", tt.name, resp.Item.AssetID, resp.Item.Expected, resp.Duplicate, tt.assetID, tt.expected)
+		}
+	}
+}
+
+func TestStocktakeFindOutOfScope(t *testing.T) {
+	s := stocktakeServer(model.StocktakeOpen)
+	w := serve(t, s.APIStocktakesHandler, testLead, http.MethodPost, "/api/v1/stocktakes/1/find", `{"code":"ZC-2"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+	var resp findResponse
+	decodeResponse(t, w, &resp)
+	// 只返回识别资产所需的字段
+	a := resp.Item.Asset
+	if a.ID != 2 || a.Name != "笔记本" || a.AssetCode != "ZC-2" || a.Department != "HR" {
+		t.Errorf("范围外资产缺少识别字段: %+v", a)
+	}
+	if a.Recipient != "" || a.RecipientDepartment != "" || a.Supplier != "" {
+		t.Errorf("范围外资产返回了领用人或供应商: %+v", a)
+	}
+
+	// 查看盘点明细和差异报告时同样只显示识别字段
+	w = serve(t, s.APIStocktakesHandler, testLead, http.MethodGet, "/api/v1/stocktakes/1", "")
+	var detail struct {
+		Items []model.StocktakeItem `json:"items"`
+	}
+	decodeResponse(t, w, &detail)
+	if len(detail.Items) != 3 || detail.Items[2].AssetID != 2 || detail.Items[2].Asset.Recipient != "" {
+		t.Errorf("盘点明细 %+v", detail.Items)
+	}
+	w = serve(t, s.APIStocktakesHandler, testLead, http.MethodGet, "/api/v1/stocktakes/1/report", "")
+	var report model.StocktakeReport
+	decodeResponse(t, w, &report)
+	if len(report.Unexpected) != 1 || report.Unexpected[0].Asset.Recipient != "" {
+		t.Errorf("范围外 %+v", report.Unexpected)
+	}
+
+	// 管理员可以看到完整的资产内容
+	w = serve(t, s.APIStocktakesHandler, testAdmin, http.MethodGet, "/api/v1/stocktakes/1/report", "")
+	decodeResponse(t, w, &report)
+	if len(report.Unexpected) != 1 || report.Unexpected[0].Asset.Recipient != "王五" {
+		t.Errorf("管理员查看范围外 %+v", report.Unexpected)
+	}
+}
+
+func TestStocktakeFindKeepsLocation(t *testing.T) {
+	s := stocktakeServer(model.StocktakeOpen)
+	steps := []struct {
+		body      string
+		duplicate bool
+		location  string
+	}{
+		{`{"code":"ZC-1","location":"北京总部 6F"}`, false, "北京总部 6F"},
+		// 重复扫码时没有填写所在地，保留之前登记的实盘所在地
+		{`{"code":"ZC-1"}`, true, "北京总部 6F"},
+		{`{"code":"ZC-1","location":"北京总部 7F"}`, true, "北京总部 7F"},
+	}
+	for i, step := range steps {
+		w := serve(t, s.APIStocktakesHandler, testLead, http.MethodPost, "/api/v1/stocktakes/1/find", step.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("第 %d 次登记: 状态码 %d: %s", i+1, w.Code, w.Body.String())
+		}
+		var resp findResponse
+		decodeResponse(t, w, &resp)
+		if resp.Duplicate != step.duplicate || resp.Item.FoundLocation != step.location {
+			t.Errorf("第 %d 次登记: 重复 %t，实盘所在地 %q，期望 %t，%q", i+1, resp.Duplicate, resp.Item.FoundLocation, step.duplicate, step.location)
+		}
+	}
+}
+
+func TestStocktakeFindClosed(t *testing.T) {
+	s := stocktakeServer(model.StocktakeClosed)
+	w := serve(t, s.APIStocktakesHandler, testLead, http.MethodPost, "/api/v1/stocktakes/1/find", `{"code":"ZC-1"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("盘点已结束: 状态码 %d，期望 409", w.Code)
+	}
+}
@@ -4,187 +4,23 @@ import (
 	"asset-management-system/pkg/model"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"log"
 	"net/http"
-	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
-	"sync"
-	"time"
 )
 
-// Levenshtein 距离算法，用于模糊搜索
-func levenshteinDistance(s1, s2 string) int {
-	if len(s1) == 0 {
-		return len(s2)
-	}
-	if len(s2) == 0 {
-		return len(s1)
-	}
-
-	matrix := make([][]int, len(s1)+1)
-	for i := range matrix {
-		matrix[i] = make([]int, len(s2)+1)
-	}
-
-	for i := 0; i <= len(s1); i++ {
-		matrix[i][0] = i
-	}
-	for j := 0; j <= len(s2); j++ {
-		matrix[0][j] = j
-	}
-
-	for i := 1; i <= len(s1); i++ {
-		for j := 1; j <= len(s2); j++ {
-			if s1[i-1] == s2[j-1] {
-				matrix[i][j] = matrix[i-1][j-1]
-			} else {
-				min := matrix[i-1][j] + 1
-				if matrix[i][j-1]+1 < min {
-					min = matrix[i][j-1] + 1
-				}
-				if matrix[i-1][j-1]+1 < min {
-					min = matrix[i-1][j-1] + 1
-				}
-				matrix[i][j] = min
-			}
-		}
-	}
-	return matrix[len(s1)][len(s2)]
-}
-
-// 全局模板变量
-var (
-	assetEntryFullTemplate *template.Template
-	loginTemplate         *template.Template
-)
-
-// 缓存所有资产（模拟缓存，实际可使用 Redis 或其他缓存系统）
-var assetCache []struct {
-	ID                 int    `json:"id"`
-	SerialNumber       string `json:"serial_number"`
-	Name               string `json:"name"`
-	Category           string `json:"category"`
-	Brand              string `json:"brand"`
-	ApplicationDate    string `json:"application_date"`
-	Specification      string `json:"specification"`
-	AssetCode          string `json:"asset_code"`
-	OrderDate          string `json:"order_date"`
-	CreatedAt          string `json:"created_at"`
-	Department         string `json:"department"`
-	Location           string `json:"location"`
-	Supplier           string `json:"supplier"`
-	Recipient          string `json:"recipient"`
-	RecipientDepartment string `json:"recipient_department"`
-	Remarks            string `json:"remarks"`
-}
-var cacheMutex sync.Mutex
-
-func init() {
-	log.Println("初始化资产录入、列表和登录模板...")
-	// 解析资产录入和列表模板
-	assetEntryTemplatePath := filepath.Join("static", "templates", "asset-entry-full.html")
-	log.Printf("尝试解析模板文件: %s", assetEntryTemplatePath)
-	var err error
-	assetEntryFullTemplate, err = template.ParseFiles(assetEntryTemplatePath)
-	if err != nil {
-		log.Printf("解析资产录入模板失败: %v", err)
-	}
-
-	// 解析登录模板（更新为 login.html）
-	loginTemplatePath := filepath.Join("static", "templates", "login.html")
-	log.Printf("尝试解析模板文件: %s", loginTemplatePath)
-	loginTemplate, err = template.ParseFiles(loginTemplatePath)
-	if err != nil {
-		log.Printf("解析登录模板失败: %v", err)
-	}
-	log.Println("模板初始化成功")
-
-	// 初始化缓存
-	loadAssetCache()
-}
-
-func loadAssetCache() {
-	db, err := model.InitDB()
-	if err != nil {
-		log.Printf("加载资产缓存失败: %v", err)
-		return
-	}
-	defer db.Close()
-
-	rows, err := db.Query(`
-		SELECT id, serial_number, name, category, brand, application_date, specification, asset_code, order_date, created_at, department, location, supplier, recipient, recipient_department, remarks 
-		FROM assets 
-		ORDER BY created_at DESC`)
-	if err != nil {
-		log.Printf("查询所有资产失败: %v", err)
-		return
-	}
-	defer rows.Close()
-
-	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
-	assetCache = []struct {
-		ID                 int    `json:"id"`
-		SerialNumber       string `json:"serial_number"`
-		Name               string `json:"name"`
-		Category           string `json:"category"`
-		Brand              string `json:"brand"`
-		ApplicationDate    string `json:"application_date"`
-		Specification      string `json:"specification"`
-		AssetCode          string `json:"asset_code"`
-		OrderDate          string `json:"order_date"`
-		CreatedAt          string `json:"created_at"`
-		Department         string `json:"department"`
-		Location           string `json:"location"`
-		Supplier           string `json:"supplier"`
-		Recipient          string `json:"recipient"`
-		RecipientDepartment string `json:"recipient_department"`
-		Remarks            string `json:"remarks"`
-	}{}
-
-	for rows.Next() {
-		var asset struct {
-			ID                 int    `json:"id"`
-			SerialNumber       string `json:"serial_number"`
-			Name               string `json:"name"`
-			Category           string `json:"category"`
-			Brand              string `json:"brand"`
-			ApplicationDate    string `json:"application_date"`
-			Specification      string `json:"specification"`
-			AssetCode          string `json:"asset_code"`
-			OrderDate          string `json:"order_date"`
-			CreatedAt          string `json:"created_at"`
-			Department         string `json:"department"`
-			Location           string `json:"location"`
-			Supplier           string `json:"supplier"`
-			Recipient          string `json:"recipient"`
-			RecipientDepartment string `json:"recipient_department"`
-			Remarks            string `json:"remarks"`
-		}
-		err = rows.Scan(&asset.ID, &asset.SerialNumber, &asset.Name, &asset.Category, &asset.Brand, &asset.ApplicationDate, &asset.Specification, &asset.AssetCode, &asset.OrderDate, &asset.CreatedAt, &asset.Department, &asset.Location, &asset.Supplier, &asset.Recipient, &asset.RecipientDepartment, &asset.Remarks)
-		if err != nil {
-			log.Printf("解析资产数据失败: %v", err)
-			continue
-		}
-		assetCache = append(assetCache, asset)
-	}
-	log.Println("资产缓存加载成功")
-}
-
 // LoginHandler 处理登录页面和登录逻辑
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("处理登录请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
 	if r.Method == "GET" {
 		log.Println("加载登录页面")
-		if loginTemplate == nil {
-			log.Println("登录模板未初始化")
-			http.Error(w, "登录模板未初始化", http.StatusInternalServerError)
-			return
+		data := struct {
+			Next string
+		}{
+			Next: r.URL.Query().Get("next"),
 		}
-		err := loginTemplate.Execute(w, nil)
+		err := s.loginTemplate.Execute(w, data)
 		if err != nil {
 			log.Printf("渲染登录页面失败: %v", err)
 			http.Error(w, "渲染登录页面失败", http.StatusInternalServerError)
@@ -198,503 +34,297 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		username := r.FormValue("username")
 		password := r.FormValue("password")
 
-		log.Printf("接收到登录数据: username=%s, password=%s", username, password)
+		log.Printf("接收到登录数据: username=%s", username)
+
+		ctx, cancel := s.queryContext(r)
+		defer cancel()
 
-		// 验证用户名和密码（示例，实际应从数据库验证）
-		if username == "admin" && password == "admin" {
-			log.Println("登录成功")
-			// 设置会话或 Cookie 表示已登录
-			http.SetCookie(w, &http.Cookie{
-				Name:  "authenticated",
-				Value: "true",
-				Path:  "/",
-			})
-			http.Redirect(w, r, "/asset-entry", http.StatusSeeOther)
+		user, err := model.Authenticate(ctx, s.users, username, password)
+		if err == model.ErrInvalidCredentials {
+			log.Printf("登录失败: username=%s", username)
+			http.Error(w, "用户名或密码错误", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Printf("验证用户失败: %v", err)
+			http.Error(w, "验证用户失败", http.StatusInternalServerError)
 			return
 		}
 
-		log.Println("登录失败")
-		http.Error(w, "用户名或密码错误", http.StatusUnauthorized)
+		// 丢弃登录前的会话，防止会话固定攻击
+		if err := s.sessions.Destroy(ctx, w, r); err != nil {
+			log.Printf("删除旧会话失败: %v", err)
+		}
+		if _, err := s.sessions.Create(ctx, w, user.ID); err != nil {
+			log.Printf("创建会话失败: %v", err)
+			http.Error(w, "创建会话失败", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("登录成功: username=%s", user.Username)
+		http.Redirect(w, r, safeRedirectTarget(r.FormValue("next")), http.StatusSeeOther)
 	}
 }
 
-// IsAuthenticated 检查用户是否已登录
-func IsAuthenticated(r *http.Request) bool {
-	// 检查 Cookie 或会话状态
-	cookie, err := r.Cookie("authenticated")
-	if err != nil || cookie.Value != "true" {
-		return false
+// LogoutHandler 注销当前会话并返回登录页
+func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理登出请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	// 只接受表单 POST：会话 Cookie 的 SameSite 属性使跨站 POST 不携带会话，
+	// GET 则可被其他站点的链接或图片触发
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+		return
 	}
-	return true
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	if err := s.sessions.Destroy(ctx, w, r); err != nil {
+		log.Printf("删除会话失败: %v", err)
+	}
+	if user := CurrentUser(r.Context()); user != nil {
+		log.Printf("登出成功: username=%s", user.Username)
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
-// AssetEntryHandler 处理资产录入页面
-func AssetEntryHandler(w http.ResponseWriter, r *http.Request) {
-	log.Printf("处理资产录入请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+// IsAuthenticated 检查用户是否已登录（会话有效且用户处于启用状态）
+func IsAuthenticated(r *http.Request) bool {
+	return CurrentUser(r.Context()) != nil
+}
+
+// UsersHandler 处理用户管理页面以及用户的新建、编辑和删除
+func (s *Server) UsersHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理用户管理请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermManageUsers) {
+		return
+	}
 	if r.Method == "GET" {
-		log.Println("加载资产录入和列表页面")
-		if assetEntryFullTemplate == nil {
-			log.Println("模板未初始化")
-			http.Error(w, "模板未初始化", http.StatusInternalServerError)
-			return
+		log.Println("加载用户管理页面")
+		roleLabels := map[model.Role]string{}
+		for _, role := range model.Roles() {
+			roleLabels[role] = role.Label()
 		}
-
 		data := struct {
-			CreatedAt string
+			Roles      []model.Role
+			RoleLabels map[model.Role]string
 		}{
-			CreatedAt: time.Now().Format("2006-01-02"),
+			Roles:      model.Roles(),
+			RoleLabels: roleLabels,
 		}
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		if err := assetEntryFullTemplate.Execute(w, data); err != nil {
-			log.Printf("渲染模板失败: %v", err)
-			http.Error(w, "渲染模板失败", http.StatusInternalServerError)
-		} else {
-			log.Println("模板渲染成功")
+		if err := s.usersTemplate.Execute(w, data); err != nil {
+			log.Printf("渲染用户管理页面失败: %v", err)
+			http.Error(w, "渲染用户管理页面失败", http.StatusInternalServerError)
 		}
+		return
 	}
 
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+
 	if r.Method == "POST" {
-		log.Println("处理资产录入 POST 请求")
+		log.Println("处理用户保存 POST 请求")
 		r.ParseForm()
-		serialNumber := r.FormValue("serialNumber")
-		name := r.FormValue("name")
-		category := r.FormValue("category")
-		brand := r.FormValue("brand")
-		applicationDateStr := r.FormValue("applicationDate")
-		specification := r.FormValue("specification")
-		assetCode := r.FormValue("assetCode")
-		orderDateStr := r.FormValue("orderDate")
-		createdAtStr := r.FormValue("createdAt")
-		department := r.FormValue("department")
-		location := r.FormValue("location")
-		supplier := r.FormValue("supplier")
-		recipient := r.FormValue("recipient")
-		recipientDepartment := r.FormValue("recipient_department")
-		remarks := r.FormValue("remarks")
-
-		log.Printf("接收到表单数据: serial_number=%s, name=%s, ...", serialNumber, name)
+		user := &model.User{
+			Username:    strings.TrimSpace(r.FormValue("username")),
+			DisplayName: strings.TrimSpace(r.FormValue("display_name")),
+			Email:       strings.TrimSpace(r.FormValue("email")),
+			Role:        model.Role(r.FormValue("role")),
+			IsActive:    r.FormValue("is_active") != "",
+			Departments: parseDepartments(r.FormValue("departments")),
+		}
+		password := r.FormValue("password")
+		action := r.FormValue("action") // 区分新建或编辑
 
-		// 表单验证
-		if err := validateAssetForm(serialNumber, name, category, brand, applicationDateStr, specification, assetCode, orderDateStr, department, location, supplier, recipient, recipientDepartment, remarks); err != nil {
-			log.Printf("表单验证失败: %v", err)
+		if err := model.ValidateUsername(user.Username); err != nil {
 			http.Error(w, fmt.Sprintf("表单验证失败: %v", err), http.StatusBadRequest)
 			return
 		}
-
-		// 转换为日期（使用字符串格式存储到数据库）
-		var applicationDateStrSQL, orderDateStrSQL, createdAtStrSQL string
-		if applicationDateStr != "" {
-			applicationDate, err := time.Parse("2006-01-02", applicationDateStr)
-			if err != nil {
-				log.Printf("解析申请时间失败: %v", err)
-				http.Error(w, "申请时间格式错误", http.StatusBadRequest)
-				return
-			}
-			applicationDateStrSQL = applicationDate.Format("2006-01-02")
+		if err := model.ValidateEmail(user.Email); err != nil {
+			http.Error(w, fmt.Sprintf("表单验证失败: %v", err), http.StatusBadRequest)
+			return
 		}
-		if orderDateStr != "" {
-			orderDate, err := time.Parse("2006-01-02", orderDateStr)
-			if err != nil {
-				log.Printf("解析订购日期失败: %v", err)
-				http.Error(w, "订购日期格式错误", http.StatusBadRequest)
-				return
-			}
-			orderDateStrSQL = orderDate.Format("2006-01-02")
+		if !user.Role.Valid() {
+			http.Error(w, "表单验证失败: 无效的角色", http.StatusBadRequest)
+			return
 		}
-		if createdAtStr != "" {
-			createdAt, err := time.Parse("2006-01-02", createdAtStr)
-			if err != nil {
-				log.Printf("解析创建日期失败: %v", err)
-				http.Error(w, "创建日期格式错误", http.StatusBadRequest)
+		// 编辑时密码留空表示不修改
+		if action != "edit" || password != "" {
+			if err := model.ValidatePassword(password); err != nil {
+				http.Error(w, fmt.Sprintf("表单验证失败: %v", err), http.StatusBadRequest)
 				return
 			}
-			createdAtStrSQL = createdAt.Format("2006-01-02")
-		} else {
-			createdAtStrSQL = time.Now().Format("2006-01-02")
-		}
-
-		// 初始化数据库连接
-		db, err := model.InitDB()
-		if err != nil {
-			log.Printf("数据库连接失败: %v", err)
-			http.Error(w, "数据库连接失败", http.StatusInternalServerError)
-			return
 		}
-		defer db.Close()
 
-		// 使用事务确保数据一致性
-		log.Println("开始事务插入或更新资产数据")
-		tx, err := db.Begin()
-		if err != nil {
-			log.Printf("开始事务失败: %v", err)
-			http.Error(w, "开始事务失败", http.StatusInternalServerError)
-			return
-		}
-
-		action := r.FormValue("action") // 区分新建或编辑
 		if action == "edit" {
-			// 编辑现有资产
-			idStr := r.FormValue("id")
-			id, err := strconv.Atoi(idStr)
+			id, err := strconv.Atoi(r.FormValue("id"))
 			if err != nil {
-				tx.Rollback()
-				log.Printf("无效的资产 ID: %v", err)
-				http.Error(w, "无效的资产 ID", http.StatusBadRequest)
+				log.Printf("无效的用户 ID: %v", err)
+				http.Error(w, "无效的用户 ID", http.StatusBadRequest)
 				return
 			}
+			user.ID = id
 
-			_, err = tx.Exec(`
-				UPDATE assets 
-				SET serial_number = ?, name = ?, category = ?, brand = ?, application_date = ?, specification = ?, asset_code = ?, order_date = ?, created_at = ?, department = ?, location = ?, supplier = ?, recipient = ?, recipient_department = ?, remarks = ?
-				WHERE id = ?`,
-				serialNumber, name, category, brand, applicationDateStrSQL, specification, assetCode, orderDateStrSQL, createdAtStrSQL, department, location, supplier, recipient, recipientDepartment, remarks, id)
+			existing, err := s.users.Get(ctx, id)
+			if err == model.ErrUserNotFound {
+				http.Error(w, "用户不存在", http.StatusNotFound)
+				return
+			}
 			if err != nil {
-				tx.Rollback()
-				log.Printf("资产更新失败: %v", err)
-				http.Error(w, "资产更新失败", http.StatusInternalServerError)
+				log.Printf("查询用户失败: %v", err)
+				http.Error(w, "查询用户失败", http.StatusInternalServerError)
+				return
+			}
+			if existing.Role == model.RoleAdmin && existing.IsActive && !(user.Role == model.RoleAdmin && user.IsActive) {
+				if !s.ensureOtherAdmin(w, r) {
+					return
+				}
+			}
+
+			var hash string
+			if password != "" {
+				if hash, err = model.HashPassword(password); err != nil {
+					log.Printf("计算密码哈希失败: %v", err)
+					http.Error(w, "用户更新失败", http.StatusInternalServerError)
+					return
+				}
+			}
+			// 部门在每次请求加载用户时读取，修改后立即生效，无需使会话失效
+			if err := s.users.Save(ctx, user, hash); err != nil {
+				s.writeUserError(w, "用户更新失败", err)
 				return
 			}
+			if !user.IsActive || user.Role != existing.Role || hash != "" {
+				s.revokeSessions(r, id)
+			}
 		} else {
-			// 新建资产
-			_, err = tx.Exec(`
-				INSERT INTO assets (serial_number, name, category, brand, application_date, specification, asset_code, order_date, created_at, department, location, supplier, recipient, recipient_department, remarks) 
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-				serialNumber, name, category, brand, applicationDateStrSQL, specification, assetCode, orderDateStrSQL, createdAtStrSQL, department, location, supplier, recipient, recipientDepartment, remarks)
+			hash, err := model.HashPassword(password)
 			if err != nil {
-				tx.Rollback()
-				log.Printf("资产录入失败: %v", err)
-				http.Error(w, "资产录入失败", http.StatusInternalServerError)
+				log.Printf("计算密码哈希失败: %v", err)
+				http.Error(w, "用户创建失败", http.StatusInternalServerError)
+				return
+			}
+			if err := s.users.Save(ctx, user, hash); err != nil {
+				s.writeUserError(w, "用户创建失败", err)
 				return
 			}
 		}
 
-		err = tx.Commit()
-		if err != nil {
-			log.Printf("提交事务失败: %v", err)
-			http.Error(w, "提交事务失败", http.StatusInternalServerError)
-			return
-		}
-
-		// 更新缓存
-		loadAssetCache()
-
-		log.Println("资产操作成功，刷新资产列表")
-		// 返回 JSON 响应，刷新列表
+		log.Printf("用户保存成功: username=%s", user.Username)
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{"message": "success", "action": "%s"}`, action)
+		json.NewEncoder(w).Encode(map[string]string{"message": "success", "action": action})
+		return
 	}
 
 	if r.Method == "DELETE" {
-		log.Println("处理资产删除请求")
-		idStr := r.URL.Query().Get("id")
-		id, err := strconv.Atoi(idStr)
+		log.Println("处理用户删除请求")
+		id, err := strconv.Atoi(r.URL.Query().Get("id"))
 		if err != nil {
-			log.Printf("无效的资产 ID: %v", err)
-			http.Error(w, "无效的资产 ID", http.StatusBadRequest)
+			log.Printf("无效的用户 ID: %v", err)
+			http.Error(w, "无效的用户 ID", http.StatusBadRequest)
 			return
 		}
-
-		// 初始化数据库连接
-		db, err := model.InitDB()
-		if err != nil {
-			log.Printf("数据库连接失败: %v", err)
-			http.Error(w, "数据库连接失败", http.StatusInternalServerError)
+		existing, err := s.users.Get(ctx, id)
+		if err == model.ErrUserNotFound {
+			http.Error(w, "用户不存在", http.StatusNotFound)
 			return
 		}
-		defer db.Close()
-
-		// 使用事务删除资产
-		log.Println("开始事务删除资产数据")
-		tx, err := db.Begin()
 		if err != nil {
-			log.Printf("开始事务失败: %v", err)
-			http.Error(w, "开始事务失败", http.StatusInternalServerError)
+			log.Printf("查询用户失败: %v", err)
+			http.Error(w, "查询用户失败", http.StatusInternalServerError)
 			return
 		}
-
-		_, err = tx.Exec("DELETE FROM assets WHERE id = ?", id)
-		if err != nil {
-			tx.Rollback()
-			log.Printf("资产删除失败: %v", err)
-			http.Error(w, "资产删除失败", http.StatusInternalServerError)
+		if existing.Role == model.RoleAdmin && existing.IsActive && !s.ensureOtherAdmin(w, r) {
 			return
 		}
-
-		err = tx.Commit()
-		if err != nil {
-			log.Printf("提交事务失败: %v", err)
-			http.Error(w, "提交事务失败", http.StatusInternalServerError)
+		if err := s.users.Delete(ctx, id); err != nil {
+			s.writeUserError(w, "用户删除失败", err)
 			return
 		}
+		s.revokeSessions(r, id)
 
-		// 更新缓存
-		loadAssetCache()
-
-		log.Println("资产删除成功，刷新资产列表")
+		log.Printf("用户删除成功: username=%s", existing.Username)
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
 		fmt.Fprintf(w, `{"message": "success"}`)
+		return
 	}
+
+	http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
 }
 
-// AssetListHandler 处理资产列表页面（优化模糊搜索，使用 LIKE 和 Levenshtein 距离）
-func AssetListHandler(w http.ResponseWriter, r *http.Request) {
-	log.Printf("处理资产列表请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
-	// 初始化数据库连接
-	db, err := model.InitDB()
-	if err != nil {
-		log.Printf("数据库连接失败: %v", err)
-		http.Error(w, "数据库连接失败", http.StatusInternalServerError)
+// UserListHandler 返回用户列表 JSON
+func (s *Server) UserListHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理用户列表请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	if !s.authorize(w, r, model.PermManageUsers) {
 		return
 	}
-	defer db.Close()
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
 
-	// 获取分页和搜索参数
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page < 1 {
-		page = 1
-	}
-	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if err != nil || pageSize < 1 {
-		pageSize = 20 // 默认每页 20 条
+	users, err := s.users.List(ctx)
+	if err != nil {
+		log.Printf("查询用户列表失败: %v", err)
+		http.Error(w, "查询用户列表失败", http.StatusInternalServerError)
+		return
 	}
-	offset := (page - 1) * pageSize
 
-	query := r.URL.Query().Get("query") // 模糊搜索关键字
-
-	log.Printf("查询资产列表，页码: %d, 每页条数: %d, 搜索关键字: %s", page, pageSize, query)
-
-	// 使用缓存
-	cacheMutex.Lock()
-	cachedAssets := make([]struct {
-		ID                 int    `json:"id"`
-		SerialNumber       string `json:"serial_number"`
-		Name               string `json:"name"`
-		Category           string `json:"category"`
-		Brand              string `json:"brand"`
-		ApplicationDate    string `json:"application_date"`
-		Specification      string `json:"specification"`
-		AssetCode          string `json:"asset_code"`
-		OrderDate          string `json:"order_date"`
-		CreatedAt          string `json:"created_at"`
-		Department         string `json:"department"`
-		Location           string `json:"location"`
-		Supplier           string `json:"supplier"`
-		Recipient          string `json:"recipient"`
-		RecipientDepartment string `json:"recipient_department"`
-		Remarks            string `json:"remarks"`
-	}, len(assetCache))
-	copy(cachedAssets, assetCache)
-	cacheMutex.Unlock()
-
-	// 模糊搜索逻辑（结合 LIKE 和 Levenshtein 距离）
-	var filteredAssets []struct {
-		ID                 int    `json:"id"`
-		SerialNumber       string `json:"serial_number"`
-		Name               string `json:"name"`
-		Category           string `json:"category"`
-		Brand              string `json:"brand"`
-		ApplicationDate    string `json:"application_date"`
-		Specification      string `json:"specification"`
-		AssetCode          string `json:"asset_code"`
-		OrderDate          string `json:"order_date"`
-		CreatedAt          string `json:"created_at"`
-		Department         string `json:"department"`
-		Location           string `json:"location"`
-		Supplier           string `json:"supplier"`
-		Recipient          string `json:"recipient"`
-		RecipientDepartment string `json:"recipient_department"`
-		Remarks            string `json:"remarks"`
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"users": users}); err != nil {
+		log.Printf("编码 JSON 失败: %v", err)
 	}
+}
 
-	if query != "" {
-		query = strings.ToLower(query)
-		// 首先使用 LIKE 进行快速过滤（提高性能）
-		likeQuery := "%" + query + "%"
-		rows, err := db.Query(`
-			SELECT id, serial_number, name, category, brand, application_date, specification, asset_code, order_date, created_at, department, location, supplier, recipient, recipient_department, remarks 
-			FROM assets 
-			WHERE serial_number LIKE ? OR name LIKE ? OR category LIKE ? OR brand LIKE ? OR department LIKE ? OR location LIKE ? OR supplier LIKE ? OR recipient LIKE ? OR recipient_department LIKE ? OR remarks LIKE ?
-			ORDER BY created_at DESC`,
-			likeQuery, likeQuery, likeQuery, likeQuery, likeQuery, likeQuery, likeQuery, likeQuery, likeQuery, likeQuery)
-		if err != nil {
-			log.Printf("LIKE 模糊搜索失败: %v", err)
-			http.Error(w, "模糊搜索失败", http.StatusInternalServerError)
-			return
-		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var asset struct {
-				ID                 int    `json:"id"`
-				SerialNumber       string `json:"serial_number"`
-				Name               string `json:"name"`
-				Category           string `json:"category"`
-				Brand              string `json:"brand"`
-				ApplicationDate    string `json:"application_date"`
-				Specification      string `json:"specification"`
-				AssetCode          string `json:"asset_code"`
-				OrderDate          string `json:"order_date"`
-				CreatedAt          string `json:"created_at"`
-				Department         string `json:"department"`
-				Location           string `json:"location"`
-				Supplier           string `json:"supplier"`
-				Recipient          string `json:"recipient"`
-				RecipientDepartment string `json:"recipient_department"`
-				Remarks            string `json:"remarks"`
-			}
-			err = rows.Scan(&asset.ID, &asset.SerialNumber, &asset.Name, &asset.Category, &asset.Brand, &asset.ApplicationDate, &asset.Specification, &asset.AssetCode, &asset.OrderDate, &asset.CreatedAt, &asset.Department, &asset.Location, &asset.Supplier, &asset.Recipient, &asset.RecipientDepartment, &asset.Remarks)
-			if err != nil {
-				log.Printf("解析资产数据失败: %v", err)
-				continue
-			}
-			// 进一步使用 Levenshtein 距离验证相似度
-			fields := []string{
-				asset.SerialNumber, asset.Name, asset.Category, asset.Brand,
-				asset.Department, asset.Location, asset.Supplier, asset.Recipient,
-				asset.RecipientDepartment, asset.Remarks,
-			}
-			for _, field := range fields {
-				if field != "" {
-					distance := levenshteinDistance(strings.ToLower(field), query)
-					maxLength := len(field)
-					if maxLength > 0 && float64(distance)/float64(maxLength) < 0.3 { // 相似度阈值 0.3（可调整）
-						filteredAssets = append(filteredAssets, asset)
-						break
-					}
-				}
-			}
+// parseDepartments 解析以逗号、顿号或换行分隔的部门列表，去除空白和重复项
+func parseDepartments(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == '\n' || r == '\r'
+	})
+	departments := []string{}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		department := strings.TrimSpace(field)
+		if department == "" || seen[department] {
+			continue
 		}
-	} else {
-		filteredAssets = cachedAssets
-	}
-
-	// 应用分页
-	total := len(filteredAssets)
-	start := offset
-	end := start + pageSize
-	if end > total {
-		end = total
-	}
-	pagedAssets := filteredAssets[start:end]
-
-	// 返回 JSON 数据（用于 AJAX 刷新）
-	log.Println("返回资产列表 JSON 数据")
-	w.Header().Set("Content-Type", "application/json")
-	response := struct {
-		Assets   []struct {
-			ID                 int    `json:"id"`
-			SerialNumber       string `json:"serial_number"`
-			Name               string `json:"name"`
-			Category           string `json:"category"`
-			Brand              string `json:"brand"`
-			ApplicationDate    string `json:"application_date"`
-			Specification      string `json:"specification"`
-			AssetCode          string `json:"asset_code"`
-			OrderDate          string `json:"order_date"`
-			CreatedAt          string `json:"created_at"`
-			Department         string `json:"department"`
-			Location           string `json:"location"`
-			Supplier           string `json:"supplier"`
-			Recipient          string `json:"recipient"`
-			RecipientDepartment string `json:"recipient_department"`
-			Remarks            string `json:"remarks"`
-		} `json:"assets"`
-		Total   int `json:"total"`
-		Page    int `json:"page"`
-		Pages   int `json:"pages"`
-		PageSize int `json:"pageSize"`
-	}{
-		Page:    page,
-		Total:   total,
-		Pages:   (total + pageSize - 1) / pageSize,
-		PageSize: pageSize,
+		seen[department] = true
+		departments = append(departments, department)
 	}
+	return departments
+}
 
-	for _, asset := range pagedAssets {
-		response.Assets = append(response.Assets, struct {
-			ID                 int    `json:"id"`
-			SerialNumber       string `json:"serial_number"`
-			Name               string `json:"name"`
-			Category           string `json:"category"`
-			Brand              string `json:"brand"`
-			ApplicationDate    string `json:"application_date"`
-			Specification      string `json:"specification"`
-			AssetCode          string `json:"asset_code"`
-			OrderDate          string `json:"order_date"`
-			CreatedAt          string `json:"created_at"`
-			Department         string `json:"department"`
-			Location           string `json:"location"`
-			Supplier           string `json:"supplier"`
-			Recipient          string `json:"recipient"`
-			RecipientDepartment string `json:"recipient_department"`
-			Remarks            string `json:"remarks"`
-		}{
-			ID:                 asset.ID,
-			SerialNumber:       asset.SerialNumber,
-			Name:               asset.Name,
-			Category:           asset.Category,
-			Brand:              asset.Brand,
-			ApplicationDate:    asset.ApplicationDate,
-			Specification:      asset.Specification,
-			AssetCode:          asset.AssetCode,
-			OrderDate:          asset.OrderDate,
-			CreatedAt:          asset.CreatedAt,
-			Department:         asset.Department,
-			Location:           asset.Location,
-			Supplier:           asset.Supplier,
-			Recipient:          asset.Recipient,
-			RecipientDepartment: asset.RecipientDepartment,
-			Remarks:            asset.Remarks,
-		})
+// ensureOtherAdmin 在停用、降级或删除管理员前确认还有其他启用的管理员，否则写入错误响应并返回 false
+func (s *Server) ensureOtherAdmin(w http.ResponseWriter, r *http.Request) bool {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	count, err := s.users.CountActiveAdmins(ctx)
+	if err != nil {
+		log.Printf("统计管理员数量失败: %v", err)
+		http.Error(w, "统计管理员数量失败", http.StatusInternalServerError)
+		return false
 	}
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("编码 JSON 失败: %v", err)
-		http.Error(w, "编码 JSON 失败", http.StatusInternalServerError)
-	} else {
-		log.Println("JSON 数据编码成功")
+	if count <= 1 {
+		http.Error(w, "至少需要保留一个启用的管理员", http.StatusBadRequest)
+		return false
 	}
+	return true
 }
 
-// 表单验证函数
-func validateAssetForm(serialNumber, name, category, brand, applicationDate, specification, assetCode, orderDate, department, location, supplier, recipient, recipientDepartment, remarks string) error {
-	if serialNumber == "" {
-		return fmt.Errorf("序列号不能为空")
-	}
-	if name == "" {
-		return fmt.Errorf("资产名称不能为空")
-	}
-	if category == "" {
-		return fmt.Errorf("设备类型不能为空")
-	}
-	if brand == "" {
-		return fmt.Errorf("品牌不能为空")
-	}
-	if department == "" {
-		return fmt.Errorf("所在部门不能为空")
-	}
-	if location == "" {
-		return fmt.Errorf("所在地不能为空")
-	}
-	if supplier == "" {
-		return fmt.Errorf("供应商不能为空")
-	}
-	if recipient == "" {
-		return fmt.Errorf("领用人不能为空")
-	}
-	if recipientDepartment == "" {
-		return fmt.Errorf("领取部门不能为空")
+// revokeSessions 使用户的全部会话失效（修改密码、停用或删除后调用）
+func (s *Server) revokeSessions(r *http.Request, userID int) {
+	ctx, cancel := s.queryContext(r)
+	defer cancel()
+	if err := s.sessions.Store().DeleteByUser(ctx, userID); err != nil {
+		log.Printf("删除用户会话失败: user_id=%d, %v", userID, err)
 	}
+}
 
-	// 日期格式验证（YYYY-MM-DD）
-	dateRegex := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
-	if applicationDate != "" && !dateRegex.MatchString(applicationDate) {
-		return fmt.Errorf("申请时间格式错误，应为 YYYY-MM-DD")
+func (s *Server) writeUserError(w http.ResponseWriter, message string, err error) {
+	switch err {
+	case model.ErrUserNotFound:
+		http.Error(w, "用户不存在", http.StatusNotFound)
+	case model.ErrUsernameTaken:
+		http.Error(w, "用户名已存在", http.StatusConflict)
+	default:
+		log.Printf("%s: %v", message, err)
+		http.Error(w, message, http.StatusInternalServerError)
 	}
-	if orderDate != "" && !dateRegex.MatchString(orderDate) {
-		return fmt.Errorf("订购日期格式错误，应为 YYYY-MM-DD")
-	}
-	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"asset-management-system/pkg/session"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLogoutRequiresPost(t *testing.T) {
+	store := session.NewMemoryStore()
+	s := newTestServer(testAssets())
+	s.sessions = session.NewManager(store, session.Options{
+		CookieName:      "ams_session",
+		Secret:          []byte("0123456789abcdef0123456789abcdef"),
+		SameSite:        http.SameSiteLaxMode,
+		IdleTimeout:     time.Hour,
+		AbsoluteTimeout: time.Hour,
+	})
+	created := httptest.NewRecorder()
+	sess, err := s.sessions.Create(context.Background(), created, testAdmin.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := created.Result().Cookies()[0]
+
+	for _, tt := range []struct {
+		method string
+		want   int
+		alive  bool
+	}{
+		{http.MethodGet, http.StatusMethodNotAllowed, true},
+		{http.MethodPost, http.StatusSeeOther, false},
+	} {
+		r := httptest.NewRequest(tt.method, "/logout", nil)
+		r.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		s.LogoutHandler(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: 状态码 %d，期望 %d", tt.method, w.Code, tt.want)
+		}
+		_, err := store.Get(context.Background(), sess.ID)
+		if alive := err == nil; alive != tt.alive {
+			t.Errorf("%s: 会话有效为 %v，期望 %v", tt.method, alive, tt.alive)
+		}
+	}
+}
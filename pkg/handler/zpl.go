@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"asset-management-system/pkg/zpl"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiPrintersPrefix 标签打印机资源路径
+const apiPrintersPrefix = "/api/v1/printers"
+
+// zplContentType ZPL 文件的响应类型
+const zplContentType = "application/vnd.zebra-zpl; charset=utf-8"
+
+// maxCopies 每张标签最多打印的份数
+const maxCopies = 100
+
+// printRequest 发送到打印机的请求体
+type printRequest struct {
+	Printer  string `json:"printer"`
+	Template string `json:"template"`
+	AssetIDs []int  `json:"asset_ids"`
+	Copies   int    `json:"copies"`
+}
+
+// printerInfo 打印机列表中的一项，不返回打印机地址
+type printerInfo struct {
+	Name string `json:"name"`
+	DPI  int    `json:"dpi"`
+}
+
+// zplFields 标签模板使用的资产字段
+func (s *Server) zplFields(r *http.Request, a *model.Asset) zpl.Fields {
+	code, _ := s.barcodeContent(r, a, barcodeContentCode)
+	return zpl.Fields{
+		Name:         a.Name,
+		AssetCode:    a.AssetCode,
+		SerialNumber: a.SerialNumber,
+		Category:     a.Category,
+		Department:   a.Department,
+		Location:     a.Location,
+		Code:         code,
+		Link:         s.assetLink(r, a.ID),
+	}
+}
+
+// findZPLTemplate 按名称查找 ZPL 模板，名称为空时返回默认模板
+func (s *Server) findZPLTemplate(name string) (*zpl.Template, error) {
+	if name == "" {
+		return s.zplTemplates.List()[0], nil
+	}
+	if t, ok := s.zplTemplates.Find(name); ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("标签模板不存在: %s", name)
+}
+
+// parseZPLOptions 解析下载 ZPL 的参数：template、dpi（203 或 300，默认 203）和 copies（每张标签的份数）
+func (s *Server) parseZPLOptions(q url.Values) (*zpl.Template, zpl.Options, error) {
+	opts := zpl.Options{DPI: 203, Font: s.cfg.Printers.Font, Copies: 1}
+	t, err := s.findZPLTemplate(q.Get("template"))
+	if err != nil {
+		return nil, opts, err
+	}
+	if v := q.Get("dpi"); v != "" {
+		if v != "203" && v != "300" {
+			return nil, opts, fmt.Errorf("dpi 只能是 203 或 300")
+		}
+		opts.DPI, _ = strconv.Atoi(v)
+	}
+	if v := q.Get("copies"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxCopies {
+			return nil, opts, fmt.Errorf("copies 应为 1 到 %d 之间的整数", maxCopies)
+		}
+		opts.Copies = n
+	}
+	return t, opts, nil
+}
+
+// renderZPL 把资产渲染为 ZPL。模板无法渲染时（如 Code128 中含有中文）返回的错误说明原因
+func (s *Server) renderZPL(r *http.Request, t *zpl.Template, assets []*model.Asset, opts zpl.Options) ([]byte, error) {
+	fields := make([]zpl.Fields, len(assets))
+	for i, asset := range assets {
+		fields[i] = s.zplFields(r, asset)
+	}
+	return t.Render(fields, opts)
+}
+
+// writeLabelZPL 下载选中资产的 ZPL 标签
+func (s *Server) writeLabelZPL(w http.ResponseWriter, r *http.Request, ids []int) {
+	t, opts, err := s.parseZPLOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	assets, status, err := s.labelAssets(r, ids)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	data, err := s.renderZPL(r, t, assets, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	date := time.Now().Format("2006-01-02")
+	w.Header().Set("Content-Type", zplContentType)
+	setAttachment(w, fmt.Sprintf("资产标签-%s.zpl", date), fmt.Sprintf("labels-%s.zpl", date))
+	if _, err := w.Write(data); err != nil {
+		log.Printf("写入标签失败: %v", err)
+	}
+}
+
+// apiAssetZPL 返回单个资产的 ZPL 标签
+func (s *Server) apiAssetZPL(w http.ResponseWriter, r *http.Request, id int) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	asset, ok := s.apiScopedAsset(w, r, id)
+	if !ok {
+		return
+	}
+	t, opts, err := s.parseZPLOptions(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	data, err := s.renderZPL(r, t, []*model.Asset{asset}, opts)
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", zplContentType)
+	setAttachment(w, fmt.Sprintf("资产标签-%d.zpl", id), fmt.Sprintf("label-%d.zpl", id))
+	if _, err := w.Write(data); err != nil {
+		log.Printf("写入标签失败: %v", err)
+	}
+}
+
+// APIPrintersHandler 处理 /api/v1/printers 资源：
+//
+//	GET  /api/v1/printers        已配置的打印机和可用的 ZPL 标签模板
+//	POST /api/v1/printers/print  把选中资产的标签发送到打印机
+//
+// 只能发送到配置文件中列出的打印机
+func (s *Server) APIPrintersHandler(w http.ResponseWriter, r *http.Request) {
+	log.Printf("处理打印机接口请求: %s %s, 远程地址: %s", r.Method, r.URL.Path, r.RemoteAddr)
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, apiPrintersPrefix), "/")
+	switch rest {
+	case "":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiListPrinters(w, r)
+	case "print":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+			return
+		}
+		s.apiPrintLabels(w, r)
+	default:
+		writeAPIError(w, http.StatusNotFound, "接口不存在")
+	}
+}
+
+func (s *Server) apiListPrinters(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, model.PermViewAsset) {
+		return
+	}
+	printers := make([]printerInfo, len(s.cfg.Printers.Devices))
+	for i, d := range s.cfg.Printers.Devices {
+		printers[i] = printerInfo{Name: d.Name, DPI: printerDPI(d)}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"printers":  printers,
+		"templates": s.zplTemplates.List(),
+	})
+}
+
+// apiPrintLabels 渲染选中资产的标签并通过 raw TCP 发送到打印机
+func (s *Server) apiPrintLabels(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, model.PermEditAsset) {
+		return
+	}
+	var req printRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	var printer *config.PrinterConfig
+	for i := range s.cfg.Printers.Devices {
+		if s.cfg.Printers.Devices[i].Name == req.Printer {
+			printer = &s.cfg.Printers.Devices[i]
+			break
+		}
+	}
+	if printer == nil {
+		writeAPIError(w, http.StatusNotFound, "打印机不存在")
+		return
+	}
+	t, err := s.findZPLTemplate(req.Template)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Copies == 0 {
+		req.Copies = 1
+	}
+	if req.Copies < 1 || req.Copies > maxCopies {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("copies 应为 1 到 %d 之间的整数", maxCopies))
+		return
+	}
+	if len(req.AssetIDs) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "请选择要打印标签的资产")
+		return
+	}
+	if len(req.AssetIDs) > maxLabels {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("一次最多打印 %d 张标签", maxLabels))
+		return
+	}
+
+	assets, status, err := s.labelAssets(r, req.AssetIDs)
+	if err != nil {
+		writeAPIError(w, status, err.Error())
+		return
+	}
+	data, err := s.renderZPL(r, t, assets, zpl.Options{DPI: printerDPI(*printer), Font: s.cfg.Printers.Font, Copies: req.Copies})
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err := zpl.Send(r.Context(), printer.Addr, data, s.cfg.Printers.Timeout); err != nil {
+		log.Printf("发送标签到打印机失败: %v", err)
+		writeAPIError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	log.Printf("标签已发送到打印机 %s: %d 张，每张 %d 份，模板: %s, 操作人: %s", printer.Name, len(assets), req.Copies, t.Name, CurrentUser(r.Context()).Username)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"printer": printer.Name,
+		"labels":  len(assets),
+		"copies":  req.Copies,
+	})
+}
+
+// printerDPI 打印机分辨率，未配置时为 203
+func printerDPI(d config.PrinterConfig) int {
+	if d.DPI == 0 {
+		return 203
+	}
+	return d.DPI
+}
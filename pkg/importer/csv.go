@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 支持的 CSV 编码
+const (
+	EncodingAuto = "auto"
+	EncodingUTF8 = "utf-8"
+	EncodingGBK  = "gbk"
+)
+
+// utf8BOM Excel 保存“CSV UTF-8”时在文件开头写入的字节顺序标记
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ReadCSV 读取 CSV 文件，第一行为表头。encoding 为 auto 或空时，
+// 有 BOM 或内容是合法 UTF-8 的按 UTF-8 读取，否则按 GBK（中文版 Excel 的默认编码）读取。
+// 分隔符自动识别逗号、分号和制表符
+func ReadCSV(data []byte, encoding string) (*Table, error) {
+	text, used, err := decode(data, encoding)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = sniffDelimiter(text)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("文件为空")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析 CSV 失败: %w", err)
+	}
+	table := &Table{Encoding: used, Header: header}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 CSV 失败: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		table.Records = append(table.Records, record)
+		table.Lines = append(table.Lines, line)
+	}
+	return table, nil
+}
+
+// decode 按指定编码把文件内容转为 UTF-8 字符串，返回实际使用的编码
+func decode(data []byte, encoding string) (string, string, error) {
+	switch strings.ToLower(encoding) {
+	case "", EncodingAuto:
+		if bytes.HasPrefix(data, utf8BOM) || utf8.Valid(data) {
+			return string(bytes.TrimPrefix(data, utf8BOM)), EncodingUTF8, nil
+		}
+		return decodeGBK(data)
+	case EncodingUTF8, "utf8":
+		if !utf8.Valid(data) {
+			return "", "", fmt.Errorf("文件不是有效的 UTF-8 编码，请尝试 encoding=gbk")
+		}
+		return string(bytes.TrimPrefix(data, utf8BOM)), EncodingUTF8, nil
+	case EncodingGBK, "gb2312", "gb18030":
+		return decodeGBK(data)
+	default:
+		return "", "", fmt.Errorf("不支持的编码: %s（可选 auto、utf-8、gbk）", encoding)
+	}
+}
+
+// decodeGBK 使用 GB18030 解码，它兼容 GBK 和 GB2312
+func decodeGBK(data []byte) (string, string, error) {
+	text, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", "", fmt.Errorf("按 GBK 解码失败: %w", err)
+	}
+	return string(text), EncodingGBK, nil
+}
+
+// sniffDelimiter 根据表头行中出现最多的分隔符选择逗号、分号或制表符
+func sniffDelimiter(text string) rune {
+	first := text
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		first = text[:i]
+	}
+	best, count := ',', strings.Count(first, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(first, string(d)); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,366 @@
+// Package importer 把表格文件中的资产解析为 model.Asset 并逐行校验。
+//
+// 表头可以使用中文列名（与资产页面一致）或 JSON 字段名，列的顺序不限；
+// 解析结果按行列出错误，调用方据此预览（试运行）或只导入通过校验的行。
+package importer
+
+import (
+	"asset-management-system/pkg/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Column 一个可导入的资产字段：JSON 字段名、中文列名和可接受的别名
+type Column struct {
+	Field   string
+	Label   string
+	Aliases []string
+}
+
+// Columns 可导入的列，顺序即导出和模板的列顺序
+var Columns = []Column{
+	{"serial_number", "序列号", []string{"serialNumber", "SN"}},
+	{"name", "资产名称", []string{"名称"}},
+	{"category", "设备类型", []string{"类型", "类别"}},
+	{"brand", "品牌", nil},
+	{"application_date", "申请时间", []string{"applicationDate", "申请日期"}},
+	{"specification", "设备规格", []string{"规格", "规格型号"}},
+	{"asset_code", "资产编码", []string{"assetCode"}},
+	{"order_date", "订购日期", []string{"orderDate"}},
+	{"created_at", "创建日期", []string{"createdAt"}},
+	{"department", "所在部门", []string{"部门"}},
+	{"location", "所在地", []string{"位置", "存放地点"}},
+	{"supplier", "供应商", nil},
+	{"recipient", "领用人", []string{"保管人"}},
+	{"recipient_department", "领取部门", []string{"recipientDepartment", "领用部门"}},
+	{"expected_return_date", "预计归还日期", []string{"expectedReturnDate"}},
+	{"status", "状态", nil},
+	{"remarks", "备注", nil},
+}
+
+// Value 返回资产在该列的值，状态使用中文名称，与导入时接受的写法一致
+func (c Column) Value(a *model.Asset) string {
+	switch c.Field {
+	case "serial_number":
+		return a.SerialNumber
+	case "name":
+		return a.Name
+	case "category":
+		return a.Category
+	case "brand":
+		return a.Brand
+	case "application_date":
+		return a.ApplicationDate
+	case "specification":
+		return a.Specification
+	case "asset_code":
+		return a.AssetCode
+	case "order_date":
+		return a.OrderDate
+	case "created_at":
+		return a.CreatedAt
+	case "department":
+		return a.Department
+	case "location":
+		return a.Location
+	case "supplier":
+		return a.Supplier
+	case "recipient":
+		return a.Recipient
+	case "recipient_department":
+		return a.RecipientDepartment
+	case "expected_return_date":
+		return a.ExpectedReturnDate
+	case "status":
+		return a.Status.Label()
+	case "remarks":
+		return a.Remarks
+	}
+	return ""
+}
+
+// Table 从文件读出的原始表格：表头、数据行以及每行在文件中的行号
+type Table struct {
+	// Encoding 文件的实际编码，XLSX 为空
+	Encoding string
+	Header   []string
+	Records  [][]string
+	Lines    []int
+}
+
+// Row 一行数据的解析结果，Errors 为空表示通过校验
+type Row struct {
+	Line   int         `json:"line"`
+	Asset  model.Asset `json:"asset"`
+	Errors []string    `json:"errors"`
+}
+
+// Result 整个文件的解析结果
+type Result struct {
+	Encoding string `json:"encoding,omitempty"`
+	// Columns 识别出的列，按文件中的顺序给出对应的字段名
+	Columns []string `json:"columns"`
+	// Ignored 无法识别而被忽略的列
+	Ignored []string `json:"ignored"`
+	Rows    []Row    `json:"rows"`
+	Valid   int      `json:"valid"`
+	Invalid int      `json:"invalid"`
+}
+
+// ValidAssets 返回通过校验的资产，顺序与文件一致
+func (r *Result) ValidAssets() []*model.Asset {
+	assets := []*model.Asset{}
+	for i := range r.Rows {
+		if len(r.Rows[i].Errors) == 0 {
+			assets = append(assets, &r.Rows[i].Asset)
+		}
+	}
+	return assets
+}
+
+// Options 逐行校验时使用的规则
+type Options struct {
+	// Validate 校验并规范化一条资产，应与表单和接口使用相同的规则
+	Validate func(*model.Asset) error
+	// Scope 当前用户的部门范围，范围外的行报错
+	Scope model.AssetScope
+	// Existing 系统中已有的序列号，重复的行报错
+	Existing map[string]bool
+}
+
+// columnIndex 表头名称到字段名的映射，忽略大小写和首尾空白
+var columnIndex = func() map[string]string {
+	index := map[string]string{}
+	for _, c := range Columns {
+		for _, name := range append([]string{c.Field, c.Label}, c.Aliases...) {
+			index[strings.ToLower(name)] = c.Field
+		}
+	}
+	return index
+}()
+
+// Lookup 按字段名、中文列名或别名查找列，忽略大小写和首尾空白
+func Lookup(name string) (Column, bool) {
+	field, ok := columnIndex[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Column{}, false
+	}
+	for _, c := range Columns {
+		if c.Field == field {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// formulaPrefixes 电子表格软件把以这些字符开头的单元格当作公式
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeFormula 在以公式字符开头的值前加单引号，防止导出的文件在 Excel 等软件中打开时执行公式。
+// 导入时去掉该单引号，导出的文件可以原样重新导入
+func EscapeFormula(v string) string {
+	if v != "" && strings.IndexByte(formulaPrefixes, v[0]) >= 0 {
+		return "'" + v
+	}
+	return v
+}
+
+// unescapeFormula 去掉 EscapeFormula 加上的单引号
+func unescapeFormula(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.IndexByte(formulaPrefixes, v[1]) >= 0 {
+		return v[1:]
+	}
+	return v
+}
+
+// requiredColumns 文件必须包含的列，与表单的必填项一致
+var requiredColumns = []string{"serial_number", "name", "category", "brand", "department", "location", "supplier"}
+
+// Parse 把表格映射为资产并逐行校验。缺少必填列或表头重复时返回错误，单行的问题记录在该行的 Errors 中
+func (t *Table) Parse(opts Options) (*Result, error) {
+	result := &Result{Encoding: t.Encoding, Columns: []string{}, Ignored: []string{}, Rows: []Row{}}
+	fields := make([]string, len(t.Header))
+	seen := map[string]bool{}
+	for i, h := range t.Header {
+		name := strings.TrimSpace(h)
+		field, ok := columnIndex[strings.ToLower(name)]
+		if !ok {
+			if name != "" {
+				result.Ignored = append(result.Ignored, name)
+			}
+			continue
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("表头中的“%s”重复", name)
+		}
+		seen[field] = true
+		fields[i] = field
+		result.Columns = append(result.Columns, field)
+	}
+	var missing []string
+	for _, field := range requiredColumns {
+		if !seen[field] {
+			missing = append(missing, labelOf(field))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("缺少必填列: %s", strings.Join(missing, "、"))
+	}
+
+	serials := map[string]int{}
+	for n, record := range t.Records {
+		if blank(record) {
+			continue
+		}
+		row := Row{Line: t.Lines[n], Errors: []string{}}
+		values := map[string]string{}
+		for i, v := range record {
+			if i < len(fields) && fields[i] != "" {
+				values[fields[i]] = strings.TrimSpace(unescapeFormula(v))
+			}
+		}
+		row.Asset = assetFrom(values)
+		row.Errors = append(row.Errors, normalize(&row.Asset)...)
+		if len(row.Errors) == 0 && opts.Validate != nil {
+			if err := opts.Validate(&row.Asset); err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			}
+		}
+		if row.Asset.Recipient == "" && row.Asset.ExpectedReturnDate != "" {
+			row.Errors = append(row.Errors, "填写预计归还日期时必须填写领用人")
+		}
+		if row.Asset.Recipient != "" && row.Asset.Status != "" && row.Asset.Status != model.StatusInUse {
+			row.Errors = append(row.Errors, "填写领用人时状态只能是在用")
+		}
+		if serial := row.Asset.SerialNumber; serial != "" {
+			if line, ok := serials[serial]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("序列号与第 %d 行重复", line))
+			} else {
+				serials[serial] = row.Line
+			}
+			if opts.Existing[serial] {
+				row.Errors = append(row.Errors, "序列号已存在")
+			}
+		}
+		if !opts.Scope.Allows(&row.Asset) {
+			row.Errors = append(row.Errors, "无权操作该部门的资产")
+		}
+
+		if len(row.Errors) == 0 {
+			result.Valid++
+		} else {
+			result.Invalid++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// assetFrom 按字段名填充资产
+func assetFrom(v map[string]string) model.Asset {
+	return model.Asset{
+		SerialNumber:        v["serial_number"],
+		Name:                v["name"],
+		Category:            v["category"],
+		Brand:               v["brand"],
+		ApplicationDate:     v["application_date"],
+		Specification:       v["specification"],
+		AssetCode:           v["asset_code"],
+		OrderDate:           v["order_date"],
+		CreatedAt:           v["created_at"],
+		Department:          v["department"],
+		Location:            v["location"],
+		Supplier:            v["supplier"],
+		Recipient:           v["recipient"],
+		RecipientDepartment: v["recipient_department"],
+		ExpectedReturnDate:  v["expected_return_date"],
+		Status:              model.AssetStatus(v["status"]),
+		Remarks:             v["remarks"],
+	}
+}
+
+// maxExcelSerial Excel 能表示的最大日期 9999-12-31 对应的序列号
+const maxExcelSerial = 2958465
+
+// dateLayouts 表格中常见的日期写法，电子表格软件常把日期保存为 2024/3/5
+var dateLayouts = []string{"2006-01-02", "2006-1-2", "2006/1/2", "2006.1.2", "2006年1月2日"}
+
+// normalize 把日期统一为 YYYY-MM-DD，把状态的中文名称转为状态值，返回无法识别的值
+func normalize(a *model.Asset) []string {
+	var problems []string
+	dates := []struct {
+		value *string
+		field string
+	}{
+		{&a.ApplicationDate, "application_date"},
+		{&a.OrderDate, "order_date"},
+		{&a.CreatedAt, "created_at"},
+		{&a.ExpectedReturnDate, "expected_return_date"},
+	}
+	for _, d := range dates {
+		if *d.value == "" {
+			continue
+		}
+		if t, ok := parseDate(*d.value); ok {
+			*d.value = t.Format("2006-01-02")
+		} else {
+			problems = append(problems, fmt.Sprintf("%s格式错误: %s", labelOf(d.field), *d.value))
+		}
+	}
+	if a.Status != "" && !a.Status.Valid() {
+		for _, status := range model.Statuses() {
+			if string(a.Status) == status.Label() {
+				a.Status = status
+				break
+			}
+		}
+		if !a.Status.Valid() {
+			problems = append(problems, fmt.Sprintf("无效的资产状态: %s", a.Status))
+		}
+	}
+	return problems
+}
+
+func parseDate(v string) (time.Time, bool) {
+	// XLSX 的日期单元格是从 1900 年起算的天数，小数部分为时间
+	if serial, err := strconv.ParseFloat(v, 64); err == nil {
+		if serial < 1 || serial > maxExcelSerial {
+			return time.Time{}, false
+		}
+		t, err := excelize.ExcelDateToTime(serial, false)
+		return t, err == nil
+	}
+	// 去掉电子表格可能附带的时间部分
+	if i := strings.IndexByte(v, ' '); i > 0 {
+		v = v[:i]
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// labelOf 返回字段的中文列名
+func labelOf(field string) string {
+	for _, c := range Columns {
+		if c.Field == field {
+			return c.Label
+		}
+	}
+	return field
+}
+
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
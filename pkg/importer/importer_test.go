@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"asset-management-system/pkg/model"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       model.Asset
+		want     model.Asset
+		problems int
+	}{
+		{"横线日期", model.Asset{OrderDate: "2024-3-5"}, model.Asset{OrderDate: "2024-03-05"}, 0},
+		{"斜线日期", model.Asset{OrderDate: "2024/3/5"}, model.Asset{OrderDate: "2024-03-05"}, 0},
+		{"中文日期", model.Asset{OrderDate: "2024年3月5日"}, model.Asset{OrderDate: "2024-03-05"}, 0},
+		{"带时间", model.Asset{CreatedAt: "2024/03/05 10:30"}, model.Asset{CreatedAt: "2024-03-05"}, 0},
+		{"Excel 日期序列号", model.Asset{ApplicationDate: "45000"}, model.Asset{ApplicationDate: "2023-03-15"}, 0},
+		{"序列号超出范围", model.Asset{ApplicationDate: "0"}, model.Asset{ApplicationDate: "0"}, 1},
+		{"无法识别的日期", model.Asset{ExpectedReturnDate: "下周"}, model.Asset{ExpectedReturnDate: "下周"}, 1},
+		{"状态中文名称", model.Asset{Status: "维修中"}, model.Asset{Status: model.StatusUnderRepair}, 0},
+		{"状态值", model.Asset{Status: "in_use"}, model.Asset{Status: model.StatusInUse}, 0},
+		{"无效状态", model.Asset{Status: "丢失"}, model.Asset{Status: "丢失"}, 1},
+	}
+	for _, tt := range tests {
+		got := tt.in
+		problems := normalize(&got)
+		if got != tt.want || len(problems) != tt.problems {
+			t.Errorf("%s: %+v, %v；期望 %+v，%d 个问题", tt.name, got, problems, tt.want, tt.problems)
+		}
+	}
+}
+
+func TestReadCSVEncoding(t *testing.T) {
+	text := "序列号,资产名称\nSN-1,笔记本电脑\n"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		want     string
+		err      string
+	}{
+		{"自动识别 UTF-8", []byte(text), "", EncodingUTF8, ""},
+		{"去掉 BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...), "auto", EncodingUTF8, ""},
+		{"自动识别 GBK", gbk, "auto", EncodingGBK, ""},
+		{"指定 GB2312", gbk, "gb2312", EncodingGBK, ""},
+		{"GBK 内容指定 UTF-8", gbk, "utf-8", "", "不是有效的 UTF-8"},
+		{"不支持的编码", []byte(text), "big5", "", "不支持的编码"},
+		{"空文件", nil, "", "", "文件为空"},
+	}
+	for _, tt := range tests {
+		table, err := ReadCSV(tt.data, tt.encoding)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: 错误 %v，期望包含 %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if table.Encoding != tt.want {
+			t.Errorf("%s: 编码 %q，期望 %q", tt.name, table.Encoding, tt.want)
+		}
+		if !reflect.DeepEqual(table.Header, []string{"序列号", "资产名称"}) || !reflect.DeepEqual(table.Records, [][]string{{"SN-1", "笔记本电脑"}}) {
+			t.Errorf("%s: 表头 %q，数据 %q", tt.name, table.Header, table.Records)
+		}
+	}
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		text string
+		want rune
+	}{
+		{"a,b,c\n1;2;3;4", ','},
+		{"a;b;c\n1,2", ';'},
+		{"a\tb\tc", '\t'},
+		{"单列", ','},
+	}
+	for _, tt := range tests {
+		if got := sniffDelimiter(tt.text); got != tt.want {
+			t.Errorf("%q: %q，期望 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	header := []string{"序列号", "资产名称", "设备类型", "品牌", "所在部门", "所在地", "供应商", "领用人", "领取部门", "状态", "颜色"}
+	row := func(serial, department, recipient, status string) []string {
+		return []string{serial, "笔记本电脑", "笔记本", "联想", department, "北京", "京东", recipient, department, status, "黑"}
+	}
+	table := &Table{
+		Header: header,
+		Records: [][]string{
+			row("SN-1", "IT", "", ""),
+			row("SN-2", "IT", "张三", "在用"),
+			row("SN-1", "IT", "", ""),
+			row("SN-9", "IT", "", ""),
+			row("SN-3", "HR", "", ""),
+			row("SN-4", "IT", "", "已退役"),
+			row("SN-5", "IT", "张三", "外借"),
+			{"", "", ""},
+		},
+		Lines: []int{2, 3, 4, 5, 6, 7, 8, 9},
+	}
+	result, err := table.Parse(Options{
+		Validate: model.PrepareNewAsset,
+		Scope:    model.AssetScope{Departments: []string{"IT"}},
+		Existing: map[string]bool{"SN-9": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Ignored, []string{"颜色"}) {
+		t.Errorf("忽略的列 %v", result.Ignored)
+	}
+	want := map[int]string{
+		2: "",
+		3: "",
+		4: "序列号与第 2 行重复",
+		5: "序列号已存在",
+		6: "无权操作该部门的资产",
+		7: "新资产只能是",
+		8: "填写领用人时状态只能是在用",
+	}
+	if len(result.Rows) != len(want) || result.Valid != 2 || result.Invalid != 5 {
+		t.Fatalf("共 %d 行，通过 %d，未通过 %d", len(result.Rows), result.Valid, result.Invalid)
+	}
+	for _, r := range result.Rows {
+		errs := strings.Join(r.Errors, "；")
+		if w := want[r.Line]; (w == "") != (errs == "") || !strings.Contains(errs, w) {
+			t.Errorf("第 %d 行: %q，期望包含 %q", r.Line, errs, w)
+		}
+	}
+	if got := result.ValidAssets(); len(got) != 2 || got[1].Status != model.StatusInUse {
+		t.Errorf("通过校验的资产 %+v", got)
+	}
+
+	for _, bad := range []struct {
+		header []string
+		err    string
+	}{
+		{[]string{"序列号", "资产名称"}, "缺少必填列"},
+		{append(header[:7:7], "serial_number"), "重复"},
+	} {
+		if _, err := (&Table{Header: bad.header}).Parse(Options{}); err == nil || !strings.Contains(err.Error(), bad.err) {
+			t.Errorf("表头 %v: 错误 %v，期望包含 %q", bad.header, err, bad.err)
+		}
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1", "'+1"},
+		{"-1+2", "'-1+2"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"北京总部 5F", "北京总部 5F"},
+		{"a=1", "a=1"},
+		{"'=1", "'=1"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got := EscapeFormula(tt.in)
+		if got != tt.want {
+			t.Errorf("EscapeFormula(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+		// 以公式字符开头的值导入时还原
+		if back := unescapeFormula(got); got != tt.in && back != tt.in {
+			t.Errorf("unescapeFormula(%q) = %q，期望 %q", got, back, tt.in)
+		}
+	}
+	// 用户自己输入的单引号不是 EscapeFormula 加上的，导入时保留
+	for _, v := range []string{"'", "'abc", "'北京"} {
+		if got := unescapeFormula(v); got != v {
+			t.Errorf("unescapeFormula(%q) = %q", v, got)
+		}
+	}
+}
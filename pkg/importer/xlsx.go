@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxMagic XLSX 文件是 ZIP 压缩包，以该签名开头
+var xlsxMagic = []byte("PK\x03\x04")
+
+// IsXLSX 判断文件内容是否为 XLSX
+func IsXLSX(data []byte) bool {
+	return bytes.HasPrefix(data, xlsxMagic)
+}
+
+// ReadXLSX 读取 XLSX 文件的第一个工作表，第一行为表头。
+// 单元格按原始值读取，不套用数字格式：日期单元格得到的是序列号，由 normalize 转换为日期
+func ReadXLSX(data []byte) (*Table, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法打开 XLSX 文件: %w", err)
+	}
+	defer f.Close()
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("文件中没有工作表")
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("读取工作表“%s”失败: %w", sheets[0], err)
+	}
+	defer rows.Close()
+
+	table := &Table{}
+	for line := 1; rows.Next(); line++ {
+		record, err := rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("读取第 %d 行失败: %w", line, err)
+		}
+		if table.Header == nil {
+			if blank(record) {
+				continue
+			}
+			table.Header = record
+			continue
+		}
+		table.Records = append(table.Records, record)
+		table.Lines = append(table.Lines, line)
+	}
+	if err := rows.Error(); err != nil {
+		return nil, fmt.Errorf("读取工作表“%s”失败: %w", sheets[0], err)
+	}
+	if table.Header == nil {
+		return nil, fmt.Errorf("文件为空")
+	}
+	return table, nil
+}
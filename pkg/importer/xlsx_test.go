@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	// 第一行空白，表头在第 2 行；日期单元格保存为序列号
+	f.SetSheetRow(sheet, "A2", &[]interface{}{"序列号", "资产名称", "订购日期"})
+	f.SetSheetRow(sheet, "A3", &[]interface{}{"SN-1", "笔记本电脑", 45000})
+	f.SetSheetRow(sheet, "A5", &[]interface{}{"SN-2", "显示器"})
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !IsXLSX(buf.Bytes()) || IsXLSX([]byte("序列号,资产名称")) {
+		t.Fatal("IsXLSX 识别错误")
+	}
+
+	table, err := ReadXLSX(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(table.Header, []string{"序列号", "资产名称", "订购日期"}) {
+		t.Errorf("表头 %q", table.Header)
+	}
+	// 表头之后的空行保留在表格中，由 Parse 跳过，行号与工作表一致
+	if !reflect.DeepEqual(table.Lines, []int{3, 4, 5}) {
+		t.Errorf("行号 %v，期望 [3 4 5]", table.Lines)
+	}
+	if len(table.Records) != 3 || table.Records[0][2] != "45000" || table.Records[2][0] != "SN-2" {
+		t.Errorf("数据 %q", table.Records)
+	}
+
+	if _, err := ReadXLSX([]byte("PK\x03\x04损坏")); err == nil || !strings.Contains(err.Error(), "无法打开") {
+		t.Errorf("损坏的文件: %v", err)
+	}
+}
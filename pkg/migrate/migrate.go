@@ -0,0 +1,246 @@
+// Package migrate 实现基于版本号的数据库结构迁移。
+//
+// 每个迁移由一对 up/down SQL 脚本组成，已执行的版本记录在 schema_migrations 表中，
+// 因此 Up 可以重复执行，只会应用尚未执行的迁移。
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// 迁移文件名格式：0001_create_assets.up.sql
+var fileNameRegex = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// MySQL 错误码：索引已存在
+const mysqlErrDupKeyName = 1061
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 迁移执行状态
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Load 从文件系统读取迁移脚本，按版本号升序返回
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileNameRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("迁移版本 %d 存在多个名称: %s, %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("迁移版本 %d 缺少 up 脚本", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator 在指定数据库上执行迁移
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New 创建迁移执行器
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`)
+	return err
+}
+
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, fmt.Errorf("创建 schema_migrations 表失败: %w", err)
+	}
+	rows, err := m.db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt []byte
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		t, _ := time.Parse("2006-01-02 15:04:05", string(appliedAt))
+		applied[version] = t
+	}
+	return applied, rows.Err()
+}
+
+// Up 按顺序执行所有未执行的迁移，返回本次执行的数量
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		log.Printf("执行迁移 %04d_%s ...", migration.Version, migration.Name)
+		if err := m.exec(migration.Up); err != nil {
+			return count, fmt.Errorf("迁移 %04d_%s 执行失败: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := m.db.Exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, migration.Version, migration.Name); err != nil {
+			return count, fmt.Errorf("记录迁移版本 %d 失败: %w", migration.Version, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down 按倒序回滚最近执行的 steps 个迁移，返回本次回滚的数量
+func (m *Migrator) Down(steps int) (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return count, fmt.Errorf("迁移 %04d_%s 没有 down 脚本，无法回滚", migration.Version, migration.Name)
+		}
+		log.Printf("回滚迁移 %04d_%s ...", migration.Version, migration.Name)
+		if err := m.exec(migration.Down); err != nil {
+			return count, fmt.Errorf("迁移 %04d_%s 回滚失败: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := m.db.Exec(`DELETE FROM schema_migrations WHERE version = ?`, migration.Version); err != nil {
+			return count, fmt.Errorf("删除迁移版本 %d 记录失败: %w", migration.Version, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status 返回每个迁移的执行状态
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// exec 逐条执行脚本中的语句。MySQL 的 DDL 会隐式提交，无法放入事务，
+// 因此迁移脚本应尽量保证单条语句可重入。
+func (m *Migrator) exec(script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := m.db.Exec(stmt); err != nil {
+			// 旧版 create_assets_table 已创建过索引的库，跳过重复索引以便纳入迁移管理
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDupKeyName {
+				log.Printf("索引已存在，跳过: %v", err)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements 按行尾分号拆分脚本并去掉注释行，脚本中的字符串常量不应包含行尾分号
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmt := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+			statements = append(statements, stmt)
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
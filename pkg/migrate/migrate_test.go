@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"asset-management-system/migrations"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int
+		err      string
+	}{
+		{
+			name: "按版本号排序",
+			files: fstest.MapFS{
+				"0002_b.up.sql":   {Data: []byte("B")},
+				"0001_a.up.sql":   {Data: []byte("A")},
+				"0001_a.down.sql": {Data: []byte("-A")},
+				"README.md":       {Data: []byte("忽略")},
+			},
+			versions: []int{1, 2},
+		},
+		{name: "文件名格式错误", files: fstest.MapFS{"1-a.up.sql": {}}, err: "文件名格式错误"},
+		{name: "同一版本多个名称", files: fstest.MapFS{"0001_a.up.sql": {Data: []byte("A")}, "0001_b.down.sql": {}}, err: "多个名称"},
+		{name: "缺少 up 脚本", files: fstest.MapFS{"0001_a.down.sql": {Data: []byte("-A")}}, err: "缺少 up 脚本"},
+	}
+	for _, tt := range tests {
+		got, err := Load(tt.files)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: 错误 %v，期望包含 %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var versions []int
+		for _, m := range got {
+			versions = append(versions, m.Version)
+		}
+		if !reflect.DeepEqual(versions, tt.versions) {
+			t.Errorf("%s: 版本 %v，期望 %v", tt.name, versions, tt.versions)
+		}
+	}
+}
+
+// TestEmbeddedMigrations 确认内嵌的迁移脚本版本号从 1 开始连续，且每个版本都有 down 脚本
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range all {
+		if m.Version != i+1 {
+			t.Errorf("迁移 %04d_%s 的版本号应为 %d", m.Version, m.Name, i+1)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("迁移 %04d_%s 缺少 down 脚本", m.Version, m.Name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"空脚本", "", nil},
+		{"只有注释", "-- 说明\n\n", nil},
+		{"单条语句", "DROP TABLE a;", []string{"DROP TABLE a"}},
+		{
+			"多行语句和注释",
+			"-- 新建表\nCREATE TABLE a (\n  id INT -- 主键\n);\nALTER TABLE a ADD b INT;\n",
+			[]string{"CREATE TABLE a (\n  id INT -- 主键\n)", "ALTER TABLE a ADD b INT"},
+		},
+		{"最后一条没有分号", "DROP TABLE a;\nDROP TABLE b", []string{"DROP TABLE a", "DROP TABLE b"}},
+	}
+	for _, tt := range tests {
+		if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %q，期望 %q", tt.name, got, tt.want)
+		}
+	}
+}
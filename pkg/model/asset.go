@@ -0,0 +1,565 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ErrAssetNotFound 表示指定的资产不存在
+var ErrAssetNotFound = errors.New("资产不存在")
+
+// ErrVersionConflict 表示资产在编辑期间已被他人修改，具体内容见 VersionConflictError
+var ErrVersionConflict = errors.New("资产已被他人修改")
+
+// VersionConflictError 更新时提交的版本与当前版本不一致
+type VersionConflictError struct {
+	// Current 资产的当前内容
+	Current *Asset
+	// Changes 提交的版本之后他人所做的修改，按时间倒序
+	Changes []AssetChange
+}
+
+func (e *VersionConflictError) Error() string {
+	return ErrVersionConflict.Error()
+}
+
+// Is 使 errors.Is(err, ErrVersionConflict) 成立
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// Asset 资产记录，对应 assets 表
+type Asset struct {
+	ID                  int    `json:"id"`
+	SerialNumber        string `json:"serial_number"`
+	Name                string `json:"name"`
+	Category            string `json:"category"`
+	Brand               string `json:"brand"`
+	ApplicationDate     string `json:"application_date"`
+	Specification       string `json:"specification"`
+	AssetCode           string `json:"asset_code"`
+	OrderDate           string `json:"order_date"`
+	CreatedAt           string `json:"created_at"`
+	Department          string `json:"department"`
+	Location            string `json:"location"`
+	Supplier            string `json:"supplier"`
+	Recipient           string `json:"recipient"`
+	RecipientDepartment string `json:"recipient_department"`
+	Remarks             string `json:"remarks"`
+	// Version 乐观锁版本号，每次修改加一
+	Version int `json:"version"`
+	// Status 生命周期状态，只能通过 Transition 按允许的路径修改
+	Status AssetStatus `json:"status"`
+	// ExpectedReturnDate 预计归还日期（YYYY-MM-DD），只在有领用人时有值，逾期提醒按此判断
+	ExpectedReturnDate string `json:"expected_return_date"`
+	// LastSeenAt、LastSeenBy 最近一次确认资产在场的时间和确认人，只能通过 MarkSeen 修改
+	LastSeenAt string `json:"last_seen_at"`
+	LastSeenBy string `json:"last_seen_by"`
+}
+
+// AssetRepository 资产存储接口，处理器和工具通过它访问资产数据。
+// 所有方法都接收 context，调用方可借此绑定请求取消和查询超时。
+// 按用途拆分为几个小接口，只用到其中一部分的调用方（和测试用的假存储）只需依赖对应的接口
+type AssetRepository interface {
+	AssetStore
+	AssetHistory
+	AssetTrash
+	AssetLifecycle
+	AssetCustody
+	AssetImporter
+	AssetLookup
+}
+
+// AssetStore 资产的增删改查
+type AssetStore interface {
+	// Get 按 ID 获取资产，不存在时返回 ErrAssetNotFound
+	Get(ctx context.Context, id int) (*Asset, error)
+	// List 按创建时间倒序返回全部资产
+	List(ctx context.Context) ([]Asset, error)
+	// Create 新建资产，成功后回填 ID
+	Create(ctx context.Context, actor Actor, asset *Asset) error
+	// Update 按 ID 更新资产，不存在时返回 ErrAssetNotFound。
+	// asset.Version 非 0 时与当前版本比较，不一致返回 *VersionConflictError；成功后回填新版本。
+	// 领用人和领取部门留空表示保持不变，与当前值不同时返回 ErrCustodyChange
+	Update(ctx context.Context, actor Actor, asset *Asset) error
+	// Delete 把资产移入回收站，不存在或已删除时返回 ErrAssetNotFound
+	Delete(ctx context.Context, actor Actor, id int, reason string) error
+	// Search 在主要文本字段中模糊匹配关键字（LIKE），按创建时间倒序返回
+	Search(ctx context.Context, query string) ([]Asset, error)
+	// Each 按创建时间倒序逐行读取资产（query 非空时与 Search 的匹配规则相同），用于导出大量资产
+	Each(ctx context.Context, query string, fn func(*Asset) error) error
+}
+
+// AssetHistory 资产的变更记录
+type AssetHistory interface {
+	// History 按时间倒序返回资产的变更记录
+	History(ctx context.Context, assetID int) ([]AssetChange, error)
+}
+
+// AssetTrash 回收站中的资产
+type AssetTrash interface {
+	// ListDeleted 按删除时间倒序返回回收站中的资产
+	ListDeleted(ctx context.Context) ([]DeletedAsset, error)
+	// Restore 从回收站恢复资产，资产不在回收站时返回 ErrAssetNotFound
+	Restore(ctx context.Context, actor Actor, id int) error
+	// Purge 彻底删除回收站中的资产，资产不在回收站时返回 ErrAssetNotFound
+	Purge(ctx context.Context, actor Actor, id int) error
+}
+
+// AssetLifecycle 资产状态变更
+type AssetLifecycle interface {
+	// Transition 把资产转换到目标状态并记录原因，不允许的转换返回 *TransitionError。
+	// version 非 0 时与当前版本比较，不一致返回 *VersionConflictError
+	Transition(ctx context.Context, actor Actor, id int, to AssetStatus, reason string, version int) (*Asset, error)
+}
+
+// AssetCustody 资产的领用、归还和保管记录
+type AssetCustody interface {
+	// CheckOut 把资产领用或借出给保管人，资产未归还时返回 *CheckedOutError
+	CheckOut(ctx context.Context, actor Actor, id int, co CheckOut, version int) (*Asset, error)
+	// CheckIn 归还资产，资产没有保管人时返回 ErrNotCheckedOut
+	CheckIn(ctx context.Context, actor Actor, id int, ci CheckIn, version int) (*Asset, error)
+	// CustodyHistory 按领用时间倒序返回资产的保管记录
+	CustodyHistory(ctx context.Context, assetID int) ([]CustodyRecord, error)
+	// HeldAssets 返回当前被领用的资产，holder 非空时只返回该保管人的资产
+	HeldAssets(ctx context.Context, holder string) ([]HeldAsset, error)
+}
+
+// AssetImporter 批量导入资产
+type AssetImporter interface {
+	// Import 在一个事务中新建多条资产，任一条失败时全部回滚；成功后回填 ID
+	Import(ctx context.Context, actor Actor, assets []*Asset, reason string) error
+}
+
+// AssetLookup 扫码查找资产和确认在场
+type AssetLookup interface {
+	// FindByCode 返回资产编码或序列号与 code 完全相同的资产，资产编码匹配的在前
+	FindByCode(ctx context.Context, code string) ([]Asset, error)
+	// MarkSeen 记录资产在场，不增加版本也不写变更记录
+	MarkSeen(ctx context.Context, actor Actor, id int) (*Asset, error)
+}
+
+// DeletedAsset 回收站中的资产及删除信息
+type DeletedAsset struct {
+	Asset
+	DeletedAt     string `json:"deleted_at"`
+	DeletedBy     int    `json:"deleted_by"`
+	DeletedByName string `json:"deleted_by_name"`
+	DeleteReason  string `json:"delete_reason"`
+}
+
+// assetColumns 查询资产时使用的列，顺序与 scanAsset 保持一致
+const assetColumns = `id, serial_number, name, category, brand, application_date, specification, asset_code, order_date, created_at, department, location, supplier, recipient, recipient_department, remarks, version, status, expected_return_date, last_seen_at, last_seen_by_name`
+
+// MySQLAssetRepository 基于 MySQL 的资产存储实现
+type MySQLAssetRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLAssetRepository 使用已打开的数据库连接创建资产存储
+func NewMySQLAssetRepository(db *sql.DB) *MySQLAssetRepository {
+	return &MySQLAssetRepository{db: db}
+}
+
+// rowScanner 兼容 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAsset 读取 assetColumns 对应的一行，extra 追加在资产列之后。
+// 旧数据中可为空的列读取为空字符串
+func scanAsset(row rowScanner, extra ...interface{}) (*Asset, error) {
+	var asset Asset
+	fields := []*string{&asset.SerialNumber, &asset.Name, &asset.Category, &asset.Brand, &asset.ApplicationDate, &asset.Specification, &asset.AssetCode, &asset.OrderDate, &asset.CreatedAt, &asset.Department, &asset.Location, &asset.Supplier, &asset.Recipient, &asset.RecipientDepartment, &asset.Remarks}
+	values := make([]sql.NullString, len(fields))
+	dest := []interface{}{&asset.ID}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	var expected, seen sql.NullString
+	dest = append(dest, &asset.Version, &asset.Status, &expected, &seen, &asset.LastSeenBy)
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		*fields[i] = v.String
+	}
+	asset.ExpectedReturnDate = expected.String
+	asset.LastSeenAt = seen.String
+	return &asset, nil
+}
+
+func (r *MySQLAssetRepository) queryAssets(ctx context.Context, query string, args ...interface{}) ([]Asset, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assets := []Asset{}
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			log.Printf("解析资产数据失败: %v", err)
+			continue
+		}
+		assets = append(assets, *asset)
+	}
+	return assets, rows.Err()
+}
+
+// Get 按 ID 获取资产
+func (r *MySQLAssetRepository) Get(ctx context.Context, id int) (*Asset, error) {
+	asset, err := scanAsset(r.db.QueryRowContext(ctx, `SELECT `+assetColumns+` FROM assets WHERE id = ? AND deleted_at IS NULL`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAssetNotFound
+	}
+	return asset, err
+}
+
+// List 返回全部未删除的资产
+func (r *MySQLAssetRepository) List(ctx context.Context) ([]Asset, error) {
+	return r.queryAssets(ctx, `SELECT `+assetColumns+` FROM assets WHERE deleted_at IS NULL ORDER BY created_at DESC`)
+}
+
+// Create 在事务中插入资产并记录审计。填写了领用人时同时登记保管记录，未指定状态时为在用，否则为在库
+func (r *MySQLAssetRepository) Create(ctx context.Context, actor Actor, asset *Asset) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := createAsset(ctx, tx, actor, asset, ""); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		asset.ID, asset.Version = 0, 0
+		return err
+	}
+	return nil
+}
+
+// Import 在一个事务中插入多条资产，任一条失败时全部回滚。reason 写入每条资产的新建记录和保管记录
+func (r *MySQLAssetRepository) Import(ctx context.Context, actor Actor, assets []*Asset, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for i, asset := range assets {
+		if err := createAsset(ctx, tx, actor, asset, reason); err != nil {
+			tx.Rollback()
+			for _, a := range assets[:i] {
+				a.ID, a.Version = 0, 0
+			}
+			return fmt.Errorf("导入第 %d 条资产失败: %w", i+1, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		for _, a := range assets {
+			a.ID, a.Version = 0, 0
+		}
+		return err
+	}
+	return nil
+}
+
+// createAsset 在事务中插入资产、写入新建记录，填写了领用人时登记保管记录，成功后回填 ID 和版本
+func createAsset(ctx context.Context, tx *sql.Tx, actor Actor, asset *Asset, reason string) error {
+	status, err := InitialStatus(asset.Status, asset.Recipient)
+	if err != nil {
+		return err
+	}
+	asset.Status = status
+	if asset.Recipient == "" {
+		asset.ExpectedReturnDate = ""
+	}
+	asset.LastSeenAt, asset.LastSeenBy = "", ""
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO assets (serial_number, name, category, brand, application_date, specification, asset_code, order_date, created_at, department, location, supplier, recipient, recipient_department, remarks, status, expected_return_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		asset.SerialNumber, asset.Name, asset.Category, asset.Brand, asset.ApplicationDate, asset.Specification, asset.AssetCode, asset.OrderDate, asset.CreatedAt, asset.Department, asset.Location, asset.Supplier, asset.Recipient, asset.RecipientDepartment, asset.Remarks, asset.Status, nullIfEmpty(asset.ExpectedReturnDate))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	changes := append(diffAssets(nil, asset), AssetChange{Field: "status", NewValue: string(asset.Status)})
+	for i := range changes {
+		changes[i].Reason = reason
+	}
+	if err := recordHistory(ctx, tx, actor, int(id), 1, ActionCreate, changes); err != nil {
+		return err
+	}
+	if asset.Recipient != "" {
+		note := reason
+		if note == "" {
+			note = "新建资产时登记"
+		}
+		co := CheckOut{Holder: asset.Recipient, HolderDepartment: asset.RecipientDepartment, ExpectedReturnDate: asset.ExpectedReturnDate, Note: note}
+		if err := insertCustody(ctx, tx, actor, int(id), co, time.Now().Format(DateTimeLayout)); err != nil {
+			return err
+		}
+	}
+	asset.ID = int(id)
+	asset.Version = 1
+	return nil
+}
+
+// Update 在事务中更新资产，并记录每个变化字段的旧值和新值
+func (r *MySQLAssetRepository) Update(ctx context.Context, actor Actor, asset *Asset) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	before, err := lockAsset(ctx, tx, asset.ID, asset.Version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 状态、领用人和领取部门不随普通编辑修改，分别由状态变更和领用/归还维护；最近在场时间由 MarkSeen 维护
+	if (asset.Recipient != "" || asset.RecipientDepartment != "") &&
+		(asset.Recipient != before.Recipient || asset.RecipientDepartment != before.RecipientDepartment) {
+		tx.Rollback()
+		return ErrCustodyChange
+	}
+	asset.Status = before.Status
+	asset.LastSeenAt, asset.LastSeenBy = before.LastSeenAt, before.LastSeenBy
+	asset.Recipient, asset.RecipientDepartment = before.Recipient, before.RecipientDepartment
+	if asset.Recipient == "" {
+		// 没有领用人时没有预计归还日期
+		asset.ExpectedReturnDate = before.ExpectedReturnDate
+	}
+	changes := diffAssets(before, asset)
+	if len(changes) == 0 {
+		// 内容没有变化时不增加版本，也不写审计记录
+		tx.Rollback()
+		asset.Version = before.Version
+		return nil
+	}
+	version := before.Version + 1
+	_, err = tx.ExecContext(ctx, `
+		UPDATE assets
+		SET serial_number = ?, name = ?, category = ?, brand = ?, application_date = ?, specification = ?, asset_code = ?, order_date = ?, created_at = ?, department = ?, location = ?, supplier = ?, recipient = ?, recipient_department = ?, remarks = ?, expected_return_date = ?, version = ?
+		WHERE id = ?`,
+		asset.SerialNumber, asset.Name, asset.Category, asset.Brand, asset.ApplicationDate, asset.Specification, asset.AssetCode, asset.OrderDate, asset.CreatedAt, asset.Department, asset.Location, asset.Supplier, asset.Recipient, asset.RecipientDepartment, asset.Remarks, nullIfEmpty(asset.ExpectedReturnDate), version, asset.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if asset.ExpectedReturnDate != before.ExpectedReturnDate {
+		// 延期或提前归还时同步未归还的保管记录，到期提醒按新日期重新计算
+		_, err = tx.ExecContext(ctx, `UPDATE asset_custody SET expected_return_date = ? WHERE asset_id = ? AND checked_in_at IS NULL`,
+			nullIfEmpty(asset.ExpectedReturnDate), asset.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := recordHistory(ctx, tx, actor, asset.ID, version, ActionUpdate, changes); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	asset.Version = version
+	return nil
+}
+
+// nullIfEmpty 把空字符串转为 NULL，用于可为空的 DATE 列
+func nullIfEmpty(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
+// lockAsset 在事务中锁定未删除的资产。version 非 0 时与当前版本比较，不一致返回 *VersionConflictError，
+// 其中包含 version 之后的变更记录
+func lockAsset(ctx context.Context, tx *sql.Tx, id, version int) (*Asset, error) {
+	asset, err := scanAsset(tx.QueryRowContext(ctx, `SELECT `+assetColumns+` FROM assets WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAssetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != asset.Version {
+		conflict := &VersionConflictError{Current: asset}
+		if conflict.Changes, err = historySince(ctx, tx, id, version); err != nil {
+			return nil, err
+		}
+		return nil, conflict
+	}
+	return asset, nil
+}
+
+// Delete 在事务中把资产标记为已删除，记录删除人、时间和原因
+func (r *MySQLAssetRepository) Delete(ctx context.Context, actor Actor, id int, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var version int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM assets WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, id).Scan(&version)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return ErrAssetNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	version++
+	_, err = tx.ExecContext(ctx, `
+		UPDATE assets SET deleted_at = ?, deleted_by = ?, deleted_by_name = ?, delete_reason = ?, version = ?
+		WHERE id = ?`,
+		time.Now().Format(DateTimeLayout), actor.UserID, actor.Username, reason, version, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := recordHistory(ctx, tx, actor, id, version, ActionDelete, []AssetChange{{Field: "delete_reason", NewValue: reason}}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListDeleted 返回回收站中的资产
+func (r *MySQLAssetRepository) ListDeleted(ctx context.Context) ([]DeletedAsset, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+assetColumns+`, deleted_at, deleted_by, deleted_by_name, delete_reason
+		FROM assets WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assets := []DeletedAsset{}
+	for rows.Next() {
+		var d DeletedAsset
+		asset, err := scanAsset(rows, &d.DeletedAt, &d.DeletedBy, &d.DeletedByName, &d.DeleteReason)
+		if err != nil {
+			return nil, err
+		}
+		d.Asset = *asset
+		assets = append(assets, d)
+	}
+	return assets, rows.Err()
+}
+
+// Restore 在事务中清除删除标记
+func (r *MySQLAssetRepository) Restore(ctx context.Context, actor Actor, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var version int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM assets WHERE id = ? AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&version)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return ErrAssetNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	version++
+	_, err = tx.ExecContext(ctx, `
+		UPDATE assets SET deleted_at = NULL, deleted_by = 0, deleted_by_name = '', delete_reason = '', version = ?
+		WHERE id = ?`, version, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := recordHistory(ctx, tx, actor, id, version, ActionRestore, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Purge 在事务中彻底删除回收站中的资产，审计记录保留删除前的全部字段
+func (r *MySQLAssetRepository) Purge(ctx context.Context, actor Actor, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	before, err := scanAsset(tx.QueryRowContext(ctx, `SELECT `+assetColumns+` FROM assets WHERE id = ? AND deleted_at IS NOT NULL FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return ErrAssetNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM assets WHERE id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := recordHistory(ctx, tx, actor, id, before.Version, ActionPurge, diffAssets(before, nil)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Search 使用 LIKE 对序列号、名称、类型、品牌、部门、所在地、供应商、领用人、领取部门和备注做模糊匹配
+func (r *MySQLAssetRepository) Search(ctx context.Context, query string) ([]Asset, error) {
+	return r.queryAssets(ctx, `
+		SELECT `+assetColumns+`
+		FROM assets
+		WHERE deleted_at IS NULL AND `+searchCondition+`
+		ORDER BY created_at DESC`,
+		searchArgs(query)...)
+}
+
+// searchCondition Search 使用的 LIKE 条件，参数由 searchArgs 生成
+const searchCondition = `(serial_number LIKE ? OR name LIKE ? OR category LIKE ? OR brand LIKE ? OR department LIKE ? OR location LIKE ? OR supplier LIKE ? OR recipient LIKE ? OR recipient_department LIKE ? OR remarks LIKE ?)`
+
+func searchArgs(query string) []interface{} {
+	likeQuery := "%" + query + "%"
+	args := make([]interface{}, strings.Count(searchCondition, "?"))
+	for i := range args {
+		args[i] = likeQuery
+	}
+	return args
+}
+
+// Each 按创建时间倒序逐行读取资产并调用 fn，不把结果集载入内存。
+// query 非空时只读取 LIKE 匹配的资产（与 Search 相同）；fn 返回错误时停止并返回该错误
+func (r *MySQLAssetRepository) Each(ctx context.Context, query string, fn func(*Asset) error) error {
+	sqlQuery := `SELECT ` + assetColumns + ` FROM assets WHERE deleted_at IS NULL`
+	var args []interface{}
+	if query != "" {
+		sqlQuery += ` AND ` + searchCondition
+		args = searchArgs(query)
+	}
+	rows, err := r.db.QueryContext(ctx, sqlQuery+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(asset); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
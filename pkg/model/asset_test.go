@@ -0,0 +1,128 @@
+package model
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueriesExcludeDeleted(t *testing.T) {
+	f := &fakeDB{}
+	f.on("FROM assets", assetColumnNames)
+	repo := NewMySQLAssetRepository(openFakeDB(t, f))
+	ctx := context.Background()
+
+	if _, err := repo.Get(ctx, 1); err != ErrAssetNotFound {
+		t.Errorf("Get 没有匹配的行: %v，期望 ErrAssetNotFound", err)
+	}
+	if _, err := repo.List(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Search(ctx, "SN-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Each(ctx, "", func(*Asset) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	// 回收站中的资产不出现在详情、列表、搜索和导出中
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.queries) != 4 {
+		t.Fatalf("执行了 %d 条查询", len(f.queries))
+	}
+	for _, q := range f.queries {
+		if !strings.Contains(q, "deleted_at IS NULL") {
+			t.Errorf("查询没有排除回收站中的资产: %s", q)
+		}
+	}
+}
+
+func TestDeleteAndRestore(t *testing.T) {
+	actor := Actor{UserID: 7, Username: "alice"}
+	ctx := context.Background()
+	versionColumns := []string{"version"}
+
+	f := &fakeDB{}
+	f.on("deleted_at IS NULL FOR UPDATE", versionColumns, []driver.Value{int64(3)})
+	f.on("deleted_at IS NOT NULL FOR UPDATE", versionColumns)
+	repo := NewMySQLAssetRepository(openFakeDB(t, f))
+	if err := repo.Restore(ctx, actor, 1); err != ErrAssetNotFound {
+		t.Errorf("恢复不在回收站中的资产: %v，期望 ErrAssetNotFound", err)
+	}
+	if err := repo.Delete(ctx, actor, 1, "报废"); err != nil {
+		t.Fatal(err)
+	}
+	updates := f.execsMatching("UPDATE assets SET deleted_at = ?")
+	if len(updates) != 1 {
+		t.Fatalf("删除写入 %d 条更新，期望 1", len(updates))
+	}
+	// 参数依次为删除时间、删除人、删除人用户名、原因、版本和 ID
+	if got := updates[0].args[1:]; !reflect.DeepEqual(got, []driver.Value{int64(7), "alice", "报废", int64(4), int64(1)}) {
+		t.Errorf("删除参数 %v", got)
+	}
+	history := f.execsMatching("INSERT INTO asset_history")
+	want := [][]driver.Value{{int64(1), int64(4), ActionDelete, "delete_reason", "", "报废", "", int64(7), "alice"}}
+	if len(history) != 1 || !reflect.DeepEqual(historyArgs(history[0]), want[0]) {
+		t.Errorf("删除的审计记录 %v，期望 %v", history, want)
+	}
+
+	f = &fakeDB{}
+	f.on("deleted_at IS NOT NULL FOR UPDATE", versionColumns, []driver.Value{int64(4)})
+	f.on("deleted_at IS NULL FOR UPDATE", versionColumns)
+	repo = NewMySQLAssetRepository(openFakeDB(t, f))
+	if err := repo.Delete(ctx, actor, 1, ""); err != ErrAssetNotFound {
+		t.Errorf("删除回收站中的资产: %v，期望 ErrAssetNotFound", err)
+	}
+	if err := repo.Restore(ctx, actor, 1); err != nil {
+		t.Fatal(err)
+	}
+	updates = f.execsMatching("UPDATE assets SET deleted_at = NULL")
+	if len(updates) != 1 || !reflect.DeepEqual(updates[0].args, []driver.Value{int64(5), int64(1)}) {
+		t.Errorf("恢复写入的更新 %v", updates)
+	}
+	history = f.execsMatching("INSERT INTO asset_history")
+	want = [][]driver.Value{{int64(1), int64(5), ActionRestore, "", "", "", "", int64(7), "alice"}}
+	if len(history) != 1 || !reflect.DeepEqual(historyArgs(history[0]), want[0]) {
+		t.Errorf("恢复的审计记录 %v，期望 %v", history, want)
+	}
+	if f.commits != 1 {
+		t.Errorf("提交 %d 次，期望 1", f.commits)
+	}
+}
+
+func TestUpdateVersionConflict(t *testing.T) {
+	current := Asset{ID: 1, SerialNumber: "SN-1", Name: "笔记本", Department: "IT", Location: "6F", Version: 5, Status: StatusInStock}
+	historyColumns := []string{"id", "asset_id", "version", "action", "field", "old_value", "new_value", "reason", "changed_by", "changed_by_name", "changed_at"}
+	f := &fakeDB{}
+	f.on("FROM assets WHERE id = ?", assetColumnNames, assetRow(current))
+	f.on("FROM asset_history", historyColumns,
+		[]driver.Value{int64(12), int64(1), int64(5), ActionUpdate, "location", "5F", "6F", "", int64(8), "bob", "2026-03-05 10:00:00"},
+		[]driver.Value{int64(11), int64(1), int64(4), ActionUpdate, "remarks", "", "借用", "", int64(8), "bob", "2026-03-05 09:00:00"})
+	repo := NewMySQLAssetRepository(openFakeDB(t, f))
+
+	stale := current
+	stale.Version, stale.Location = 3, "7F"
+	err := repo.Update(context.Background(), Actor{UserID: 7, Username: "alice"}, &stale)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("提交过期的版本: %v，期望 ErrVersionConflict", err)
+	}
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("错误类型 %T", err)
+	}
+	if !reflect.DeepEqual(*conflict.Current, current) {
+		t.Errorf("当前内容 %+v，期望 %+v", conflict.Current, current)
+	}
+	if len(conflict.Changes) != 2 || conflict.Changes[0].Field != "location" || conflict.Changes[1].ChangedByName != "bob" {
+		t.Errorf("他人所做的修改 %+v", conflict.Changes)
+	}
+	if q := f.queries[len(f.queries)-1]; !strings.Contains(q, "version > ?") {
+		t.Errorf("没有按提交的版本查询修改记录: %s", q)
+	}
+	if len(f.execs) != 0 || f.commits != 0 || f.rollbacks != 1 {
+		t.Errorf("版本冲突时写入 %d 条语句，提交 %d 次，回滚 %d 次", len(f.execs), f.commits, f.rollbacks)
+	}
+}
@@ -0,0 +1,362 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 领用和归还的审计操作类型，Reason 记录领用或归还说明
+const (
+	ActionCheckOut = "checkout"
+	ActionCheckIn  = "checkin"
+)
+
+// CustodyCondition 归还时的资产状况
+type CustodyCondition string
+
+const (
+	// ConditionGood 完好，归还后在库
+	ConditionGood CustodyCondition = "good"
+	// ConditionIncomplete 配件缺失，归还后在库，由说明记录缺失内容
+	ConditionIncomplete CustodyCondition = "incomplete"
+	// ConditionDamaged 损坏，归还后进入维修
+	ConditionDamaged CustodyCondition = "damaged"
+)
+
+var conditionLabels = map[CustodyCondition]string{
+	ConditionGood:       "完好",
+	ConditionIncomplete: "配件缺失",
+	ConditionDamaged:    "损坏",
+}
+
+// Conditions 返回全部归还状况
+func Conditions() []CustodyCondition {
+	return []CustodyCondition{ConditionGood, ConditionIncomplete, ConditionDamaged}
+}
+
+// Valid 判断是否为已定义的归还状况
+func (c CustodyCondition) Valid() bool {
+	_, ok := conditionLabels[c]
+	return ok
+}
+
+// Label 返回归还状况的中文名称
+func (c CustodyCondition) Label() string {
+	if label, ok := conditionLabels[c]; ok {
+		return label
+	}
+	return string(c)
+}
+
+// ReturnStatus 归还后资产应处的状态
+func (c CustodyCondition) ReturnStatus() AssetStatus {
+	if c == ConditionDamaged {
+		return StatusUnderRepair
+	}
+	return StatusInStock
+}
+
+// ErrCheckedOut 表示资产仍有未归还的保管记录，具体内容见 CheckedOutError
+var ErrCheckedOut = errors.New("资产尚未归还")
+
+// ErrNotCheckedOut 表示资产当前没有保管人，无需归还
+var ErrNotCheckedOut = errors.New("资产当前没有保管人")
+
+// ErrCustodyChange 表示普通编辑试图修改领用人或领取部门，它们只能通过领用和归还修改
+var ErrCustodyChange = errors.New("领用人和领取部门只能通过领用和归还修改")
+
+// CheckedOutError 资产仍由他人保管，需要先办理归还
+type CheckedOutError struct {
+	Holder string
+}
+
+func (e *CheckedOutError) Error() string {
+	return fmt.Sprintf("资产当前由%s保管，请先办理归还", e.Holder)
+}
+
+// Is 使 errors.Is(err, ErrCheckedOut) 成立
+func (e *CheckedOutError) Is(target error) bool {
+	return target == ErrCheckedOut
+}
+
+// CustodyRecord 一次领用或借出：谁在什么时候领走、预计何时归还，以及归还时间和状况。
+// CheckedInAt 为空表示仍在保管中
+type CustodyRecord struct {
+	ID                 int64            `json:"id"`
+	AssetID            int              `json:"asset_id"`
+	Holder             string           `json:"holder"`
+	HolderDepartment   string           `json:"holder_department"`
+	CheckedOutAt       string           `json:"checked_out_at"`
+	ExpectedReturnDate string           `json:"expected_return_date"`
+	CheckoutNote       string           `json:"checkout_note"`
+	CheckedOutBy       int              `json:"checked_out_by"`
+	CheckedOutByName   string           `json:"checked_out_by_name"`
+	CheckedInAt        string           `json:"checked_in_at"`
+	Condition          CustodyCondition `json:"condition"`
+	CheckinNote        string           `json:"checkin_note"`
+	CheckedInBy        int              `json:"checked_in_by"`
+	CheckedInByName    string           `json:"checked_in_by_name"`
+}
+
+// Open 判断是否仍在保管中
+func (c *CustodyRecord) Open() bool {
+	return c.CheckedInAt == ""
+}
+
+// HeldAsset 当前被领用的资产及其保管记录
+type HeldAsset struct {
+	Asset
+	Custody CustodyRecord `json:"custody"`
+}
+
+// CheckOut 领用或借出的内容。Status 为 in_use（领用，默认）或 lent_out（借出）
+type CheckOut struct {
+	Holder             string
+	HolderDepartment   string
+	ExpectedReturnDate string
+	Note               string
+	Status             AssetStatus
+}
+
+// CheckIn 归还的内容
+type CheckIn struct {
+	Condition CustodyCondition
+	Note      string
+}
+
+// custodyColumns 查询保管记录时使用的列，顺序与 scanCustody 保持一致
+const custodyColumns = `id, asset_id, holder, holder_department, checked_out_at, COALESCE(expected_return_date, ''), checkout_note, checked_out_by, checked_out_by_name, COALESCE(checked_in_at, ''), checkin_condition, checkin_note, checked_in_by, checked_in_by_name`
+
+func scanCustody(row rowScanner) (*CustodyRecord, error) {
+	var c CustodyRecord
+	err := row.Scan(&c.ID, &c.AssetID, &c.Holder, &c.HolderDepartment, &c.CheckedOutAt, &c.ExpectedReturnDate, &c.CheckoutNote, &c.CheckedOutBy, &c.CheckedOutByName,
+		&c.CheckedInAt, &c.Condition, &c.CheckinNote, &c.CheckedInBy, &c.CheckedInByName)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// openCustody 返回资产未归还的保管记录，没有时返回 nil
+func openCustody(ctx context.Context, tx *sql.Tx, assetID int) (*CustodyRecord, error) {
+	c, err := scanCustody(tx.QueryRowContext(ctx, `SELECT `+custodyColumns+` FROM asset_custody WHERE asset_id = ? AND checked_in_at IS NULL ORDER BY id DESC LIMIT 1 FOR UPDATE`, assetID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// insertCustody 在事务中为资产新建一条未归还的保管记录
+func insertCustody(ctx context.Context, tx *sql.Tx, actor Actor, assetID int, co CheckOut, checkedOutAt string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO asset_custody (asset_id, holder, holder_department, checked_out_at, expected_return_date, checkout_note, checked_out_by, checked_out_by_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		assetID, co.Holder, co.HolderDepartment, checkedOutAt, nullIfEmpty(co.ExpectedReturnDate), co.Note, actor.UserID, actor.Username)
+	return err
+}
+
+// custodyChanges 领用或归还引起的字段变化，每条都带上说明
+func custodyChanges(before, after *Asset, note string) []AssetChange {
+	changes := diffAssets(before, after)
+	if before.Status != after.Status {
+		changes = append(changes, AssetChange{Field: "status", OldValue: string(before.Status), NewValue: string(after.Status)})
+	}
+	for i := range changes {
+		changes[i].Reason = note
+	}
+	return changes
+}
+
+// CheckOut 在事务中把资产领用或借出给保管人：写入保管记录，同步领用人、领取部门和状态。
+// 资产未归还时返回 *CheckedOutError，当前状态不能转为目标状态时返回 *TransitionError
+func (r *MySQLAssetRepository) CheckOut(ctx context.Context, actor Actor, id int, co CheckOut, version int) (*Asset, error) {
+	if co.Status == "" {
+		co.Status = StatusInUse
+	}
+	if co.Status != StatusInUse && co.Status != StatusLentOut {
+		return nil, fmt.Errorf("领用后的状态只能是在用或外借: %s", co.Status)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := lockAsset(ctx, tx, id, version)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	open, err := openCustody(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if open != nil {
+		tx.Rollback()
+		return nil, &CheckedOutError{Holder: open.Holder}
+	}
+	if asset.Status != co.Status && !asset.Status.CanTransition(co.Status) {
+		tx.Rollback()
+		return nil, &TransitionError{From: asset.Status, To: co.Status}
+	}
+
+	before := *asset
+	asset.Recipient, asset.RecipientDepartment, asset.Status = co.Holder, co.HolderDepartment, co.Status
+	asset.ExpectedReturnDate = co.ExpectedReturnDate
+	asset.Version++
+	_, err = tx.ExecContext(ctx, `UPDATE assets SET recipient = ?, recipient_department = ?, status = ?, expected_return_date = ?, version = ? WHERE id = ?`,
+		asset.Recipient, asset.RecipientDepartment, asset.Status, nullIfEmpty(asset.ExpectedReturnDate), asset.Version, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := insertCustody(ctx, tx, actor, id, co, time.Now().Format(DateTimeLayout)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := recordHistory(ctx, tx, actor, id, asset.Version, ActionCheckOut, custodyChanges(&before, asset, co.Note)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// CheckIn 在事务中办理归还：补全保管记录，清空领用人、领取部门和预计归还日期，按归还状况转为在库或维修中。
+// 资产没有保管人时返回 ErrNotCheckedOut
+func (r *MySQLAssetRepository) CheckIn(ctx context.Context, actor Actor, id int, ci CheckIn, version int) (*Asset, error) {
+	if !ci.Condition.Valid() {
+		return nil, fmt.Errorf("无效的归还状况: %s", ci.Condition)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := lockAsset(ctx, tx, id, version)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	open, err := openCustody(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if open == nil {
+		tx.Rollback()
+		return nil, ErrNotCheckedOut
+	}
+	to := ci.Condition.ReturnStatus()
+	if asset.Status != to && !asset.Status.CanTransition(to) {
+		tx.Rollback()
+		return nil, &TransitionError{From: asset.Status, To: to}
+	}
+
+	before := *asset
+	asset.Recipient, asset.RecipientDepartment, asset.Status, asset.ExpectedReturnDate = "", "", to, ""
+	asset.Version++
+	_, err = tx.ExecContext(ctx, `UPDATE assets SET recipient = '', recipient_department = '', status = ?, expected_return_date = NULL, version = ? WHERE id = ?`,
+		asset.Status, asset.Version, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE asset_custody
+		SET checked_in_at = ?, checkin_condition = ?, checkin_note = ?, checked_in_by = ?, checked_in_by_name = ?
+		WHERE id = ?`,
+		time.Now().Format(DateTimeLayout), ci.Condition, ci.Note, actor.UserID, actor.Username, open.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	note := ci.Condition.Label()
+	if ci.Note != "" {
+		note += "：" + ci.Note
+	}
+	if err := recordHistory(ctx, tx, actor, id, asset.Version, ActionCheckIn, custodyChanges(&before, asset, note)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// CustodyHistory 按领用时间倒序返回资产的全部保管记录
+func (r *MySQLAssetRepository) CustodyHistory(ctx context.Context, assetID int) ([]CustodyRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+custodyColumns+` FROM asset_custody WHERE asset_id = ? ORDER BY checked_out_at DESC, id DESC`, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []CustodyRecord{}
+	for rows.Next() {
+		c, err := scanCustody(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *c)
+	}
+	return records, rows.Err()
+}
+
+// HeldAssets 返回当前被领用或借出的资产，按保管人和领用时间排序。holder 非空时只返回该保管人的资产
+func (r *MySQLAssetRepository) HeldAssets(ctx context.Context, holder string) ([]HeldAsset, error) {
+	query := `SELECT ` + custodyColumns + ` FROM asset_custody WHERE checked_in_at IS NULL`
+	var args []interface{}
+	if holder = strings.TrimSpace(holder); holder != "" {
+		query += ` AND holder = ?`
+		args = append(args, holder)
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY holder, checked_out_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	var records []CustodyRecord
+	for rows.Next() {
+		c, err := scanCustody(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		records = append(records, *c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	held := []HeldAsset{}
+	if len(records) == 0 {
+		return held, nil
+	}
+	placeholders := make([]string, len(records))
+	ids := make([]interface{}, len(records))
+	for i, c := range records {
+		placeholders[i] = "?"
+		ids[i] = c.AssetID
+	}
+	assets, err := r.queryAssets(ctx, `SELECT `+assetColumns+` FROM assets WHERE deleted_at IS NULL AND id IN (`+strings.Join(placeholders, ", ")+`)`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]Asset, len(assets))
+	for _, a := range assets {
+		byID[a.ID] = a
+	}
+	// 回收站中的资产不出现在保管人名下
+	for _, c := range records {
+		if a, ok := byID[c.AssetID]; ok {
+			held = append(held, HeldAsset{Asset: a, Custody: c})
+		}
+	}
+	return held, nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"asset-management-system/pkg/config"
+	"database/sql"
+	_ "github.com/go-sql-driver/mysql"
+	"log"
+)
+
+// InitDB 打开数据库连接池并按配置设置连接数
+func InitDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	log.Println("初始化数据库连接...")
+	db, err := sql.Open("mysql", cfg.DSN)
+	if err != nil {
+		log.Printf("打开数据库连接失败: %v", err)
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		log.Printf("数据库 Ping 失败: %v", err)
+		return nil, err
+	}
+
+	log.Println("数据库连接成功")
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	return db, nil
+}
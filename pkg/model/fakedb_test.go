@@ -0,0 +1,148 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB 测试用的 database/sql 驱动，不解析 SQL：
+// 查询按 SQL 片段返回预设的结果，写操作只记录语句和参数，供测试检查存储层发出的语句
+type fakeDB struct {
+	mu        sync.Mutex
+	results   []fakeResult
+	execs     []fakeExec
+	queries   []string
+	commits   int
+	rollbacks int
+}
+
+// fakeResult 包含 match 的查询返回的结果，rows 为空表示没有匹配的行
+type fakeResult struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeExec 一条写操作
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+// openFakeDB 返回连接到 f 的 *sql.DB，测试结束时关闭
+func openFakeDB(t *testing.T, f *fakeDB) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// on 设置包含 match 的查询的结果，先设置的优先匹配
+func (f *fakeDB) on(match string, columns []string, rows ...[]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results = append(f.results, fakeResult{match: match, columns: columns, rows: rows})
+}
+
+// execsMatching 返回包含 match 的写操作
+func (f *fakeDB) execsMatching(match string) []fakeExec {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var execs []fakeExec
+	for _, e := range f.execs {
+		if strings.Contains(e.query, match) {
+			execs = append(execs, e)
+		}
+	}
+	return execs
+}
+
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDB 不支持预处理语句")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.commits++
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.rollbacks++
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	e := fakeExec{query: query}
+	for _, a := range args {
+		e.args = append(e.args, a.Value)
+	}
+	c.db.execs = append(c.db.execs, e)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, query)
+	for _, r := range c.db.results {
+		if strings.Contains(query, r.match) {
+			return &fakeRows{columns: r.columns, rows: r.rows}, nil
+		}
+	}
+	return nil, errors.New("fakeDB 没有设置查询结果: " + query)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// assetRow 把资产转换为按 assetColumns 顺序排列的一行
+func assetRow(a Asset) []driver.Value {
+	var expected, seen driver.Value
+	if a.ExpectedReturnDate != "" {
+		expected = a.ExpectedReturnDate
+	}
+	if a.LastSeenAt != "" {
+		seen = a.LastSeenAt
+	}
+	return []driver.Value{int64(a.ID), a.SerialNumber, a.Name, a.Category, a.Brand, a.ApplicationDate, a.Specification, a.AssetCode,
+		a.OrderDate, a.CreatedAt, a.Department, a.Location, a.Supplier, a.Recipient, a.RecipientDepartment, a.Remarks,
+		int64(a.Version), string(a.Status), expected, seen, a.LastSeenBy}
+}
+
+// assetColumnNames assetColumns 的列名列表
+var assetColumnNames = strings.Split(assetColumns, ", ")
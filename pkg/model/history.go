@@ -0,0 +1,165 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// DateTimeLayout 审计记录等时间字段的存储和展示格式（本地时间）
+const DateTimeLayout = "2006-01-02 15:04:05"
+
+// 审计记录的操作类型
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+	// ActionTransition 状态转换，Reason 记录转换原因
+	ActionTransition = "transition"
+)
+
+// Actor 执行变更的用户，写入审计记录。UserID 为 0 表示系统或命令行工具
+type Actor struct {
+	UserID   int
+	Username string
+}
+
+// SystemActor 命令行工具和后台任务使用的操作人
+var SystemActor = Actor{Username: "system"}
+
+// ActorFor 返回用户对应的操作人，nil 时返回 SystemActor
+func ActorFor(u *User) Actor {
+	if u == nil {
+		return SystemActor
+	}
+	return Actor{UserID: u.ID, Username: u.Username}
+}
+
+// AssetChange 一条资产变更记录：谁在什么时候把哪个字段从什么值改成了什么值。
+// 新建时 OldValue 为空；移入回收站时记录删除原因；彻底删除时 NewValue 为空；状态转换时 Reason 为转换原因
+type AssetChange struct {
+	ID            int64  `json:"id"`
+	AssetID       int    `json:"asset_id"`
+	Version       int    `json:"version"`
+	Action        string `json:"action"`
+	Field         string `json:"field"`
+	OldValue      string `json:"old_value"`
+	NewValue      string `json:"new_value"`
+	ChangedBy     int    `json:"changed_by"`
+	ChangedByName string `json:"changed_by_name"`
+	ChangedAt     string `json:"changed_at"`
+	Reason        string `json:"reason"`
+}
+
+// assetField 资产字段名（与 JSON 字段一致）和值
+type assetField struct {
+	name  string
+	value string
+}
+
+// fields 按固定顺序返回参与审计的资产字段
+func (a *Asset) fields() []assetField {
+	return []assetField{
+		{"serial_number", a.SerialNumber},
+		{"name", a.Name},
+		{"category", a.Category},
+		{"brand", a.Brand},
+		{"application_date", a.ApplicationDate},
+		{"specification", a.Specification},
+		{"asset_code", a.AssetCode},
+		{"order_date", a.OrderDate},
+		{"created_at", a.CreatedAt},
+		{"department", a.Department},
+		{"location", a.Location},
+		{"supplier", a.Supplier},
+		{"recipient", a.Recipient},
+		{"recipient_department", a.RecipientDepartment},
+		{"expected_return_date", a.ExpectedReturnDate},
+		{"remarks", a.Remarks},
+	}
+}
+
+// diffAssets 比较两个版本的资产，返回发生变化的字段。before 为 nil 表示新建，after 为 nil 表示删除
+func diffAssets(before, after *Asset) []AssetChange {
+	var oldFields, newFields []assetField
+	if before != nil {
+		oldFields = before.fields()
+	}
+	if after != nil {
+		newFields = after.fields()
+	}
+	n := len(oldFields)
+	if len(newFields) > n {
+		n = len(newFields)
+	}
+
+	var changes []AssetChange
+	for i := 0; i < n; i++ {
+		var c AssetChange
+		if oldFields != nil {
+			c.Field, c.OldValue = oldFields[i].name, oldFields[i].value
+		}
+		if newFields != nil {
+			c.Field, c.NewValue = newFields[i].name, newFields[i].value
+		}
+		if c.OldValue != c.NewValue {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// recordHistory 在事务中写入审计记录，同一次操作的记录使用相同的时间和操作后的资产版本
+func recordHistory(ctx context.Context, tx *sql.Tx, actor Actor, assetID, version int, action string, changes []AssetChange) error {
+	changedAt := time.Now().Format(DateTimeLayout)
+	if len(changes) == 0 && action != ActionUpdate {
+		// 恢复等没有字段变化的操作也留下一条记录
+		changes = []AssetChange{{}}
+	}
+	for _, c := range changes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO asset_history (asset_id, version, action, field, old_value, new_value, reason, changed_by, changed_by_name, changed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			assetID, version, action, c.Field, c.OldValue, c.NewValue, c.Reason, actor.UserID, actor.Username, changedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// History 按时间倒序返回资产的全部变更记录
+func (r *MySQLAssetRepository) History(ctx context.Context, assetID int) ([]AssetChange, error) {
+	// 版本号引入前的记录 version 为 0，同样需要返回
+	return historySince(ctx, r.db, assetID, -1)
+}
+
+// querier 兼容 *sql.DB 和 *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// historySince 返回资产在 version 之后的变更记录，按时间倒序
+func historySince(ctx context.Context, q querier, assetID, version int) ([]AssetChange, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, asset_id, version, action, field, COALESCE(old_value, ''), COALESCE(new_value, ''), reason, changed_by, changed_by_name, changed_at
+		FROM asset_history
+		WHERE asset_id = ? AND version > ?
+		ORDER BY changed_at DESC, id DESC`, assetID, version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []AssetChange{}
+	for rows.Next() {
+		var c AssetChange
+		if err := rows.Scan(&c.ID, &c.AssetID, &c.Version, &c.Action, &c.Field, &c.OldValue, &c.NewValue, &c.Reason, &c.ChangedBy, &c.ChangedByName, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestDiffAssets(t *testing.T) {
+	before := &Asset{SerialNumber: "SN-1", Name: "笔记本", Department: "IT", Location: "5F"}
+	moved := *before
+	moved.Location, moved.Remarks = "6F", "换工位"
+
+	tests := []struct {
+		name          string
+		before, after *Asset
+		want          []AssetChange
+	}{
+		{"新建时记录非空字段", nil, before, []AssetChange{
+			{Field: "serial_number", NewValue: "SN-1"},
+			{Field: "name", NewValue: "笔记本"},
+			{Field: "department", NewValue: "IT"},
+			{Field: "location", NewValue: "5F"},
+		}},
+		{"修改时只记录变化的字段", before, &moved, []AssetChange{
+			{Field: "location", OldValue: "5F", NewValue: "6F"},
+			{Field: "remarks", NewValue: "换工位"},
+		}},
+		{"没有变化", before, &Asset{SerialNumber: "SN-1", Name: "笔记本", Department: "IT", Location: "5F", Version: 3}, nil},
+		{"删除时记录原来的值", &moved, nil, []AssetChange{
+			{Field: "serial_number", OldValue: "SN-1"},
+			{Field: "name", OldValue: "笔记本"},
+			{Field: "department", OldValue: "IT"},
+			{Field: "location", OldValue: "6F"},
+			{Field: "remarks", OldValue: "换工位"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := diffAssets(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %+v，期望 %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// historyArgs 返回审计记录 INSERT 中 changed_at 以外的参数
+func historyArgs(e fakeExec) []driver.Value {
+	return e.args[:len(e.args)-1]
+}
+
+func TestRecordHistory(t *testing.T) {
+	actor := Actor{UserID: 7, Username: "alice"}
+	tests := []struct {
+		name    string
+		action  string
+		changes []AssetChange
+		want    [][]driver.Value
+	}{
+		{"每个变化的字段一条记录", ActionUpdate, []AssetChange{
+			{Field: "location", OldValue: "5F", NewValue: "6F"},
+			{Field: "remarks", NewValue: "换工位"},
+		}, [][]driver.Value{
+			{int64(1), int64(4), ActionUpdate, "location", "5F", "6F", "", int64(7), "alice"},
+			{int64(1), int64(4), ActionUpdate, "remarks", "", "换工位", "", int64(7), "alice"},
+		}},
+		{"修改没有变化时不写记录", ActionUpdate, nil, nil},
+		{"恢复等没有字段变化的操作写一条记录", ActionRestore, nil, [][]driver.Value{
+			{int64(1), int64(4), ActionRestore, "", "", "", "", int64(7), "alice"},
+		}},
+		{"状态转换记录原因", ActionTransition, []AssetChange{{Field: "status", OldValue: "in_stock", NewValue: "in_use", Reason: "领用"}}, [][]driver.Value{
+			{int64(1), int64(4), ActionTransition, "status", "in_stock", "in_use", "领用", int64(7), "alice"},
+		}},
+	}
+	for _, tt := range tests {
+		f := &fakeDB{}
+		tx, err := openFakeDB(t, f).Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := recordHistory(context.Background(), tx, actor, 1, 4, tt.action, tt.changes); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		tx.Commit()
+
+		execs := f.execsMatching("INSERT INTO asset_history")
+		if len(execs) != len(tt.want) {
+			t.Errorf("%s: 写入 %d 条记录，期望 %d", tt.name, len(execs), len(tt.want))
+			continue
+		}
+		for i, e := range execs {
+			if got := historyArgs(e); !reflect.DeepEqual(got, tt.want[i]) {
+				t.Errorf("%s: 第 %d 条记录 %v，期望 %v", tt.name, i+1, got, tt.want[i])
+			}
+			// 同一次操作的记录使用相同的时间
+			if at, first := e.args[len(e.args)-1], execs[0].args[len(e.args)-1]; at != first {
+				t.Errorf("%s: 记录时间 %v 与 %v 不同", tt.name, at, first)
+			}
+		}
+	}
+}
+
+func TestUpdateRecordsHistory(t *testing.T) {
+	current := Asset{ID: 1, SerialNumber: "SN-1", Name: "笔记本", Department: "IT", Location: "5F", Version: 3, Status: StatusInStock}
+	tests := []struct {
+		name    string
+		edit    func(a *Asset)
+		version int
+		want    [][]driver.Value
+	}{
+		{"修改两个字段", func(a *Asset) { a.Location, a.Remarks = "6F", "换工位" }, 4, [][]driver.Value{
+			{int64(1), int64(4), ActionUpdate, "location", "5F", "6F", "", int64(7), "alice"},
+			{int64(1), int64(4), ActionUpdate, "remarks", "", "换工位", "", int64(7), "alice"},
+		}},
+		{"没有变化", func(a *Asset) {}, 3, nil},
+		{"状态由状态变更维护，不算作修改", func(a *Asset) { a.Status = StatusDisposed }, 3, nil},
+	}
+	for _, tt := range tests {
+		f := &fakeDB{}
+		f.on("FROM assets WHERE id = ?", assetColumnNames, assetRow(current))
+		repo := NewMySQLAssetRepository(openFakeDB(t, f))
+
+		asset := current
+		tt.edit(&asset)
+		if err := repo.Update(context.Background(), Actor{UserID: 7, Username: "alice"}, &asset); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if asset.Version != tt.version || asset.Status != StatusInStock {
+			t.Errorf("%s: 版本 %d 状态 %s，期望版本 %d", tt.name, asset.Version, asset.Status, tt.version)
+		}
+
+		updates := f.execsMatching("UPDATE assets")
+		history := f.execsMatching("INSERT INTO asset_history")
+		if tt.want == nil {
+			// 没有变化时不修改资产、不增加版本、不写审计记录
+			if len(updates) != 0 || len(history) != 0 || f.commits != 0 {
+				t.Errorf("%s: 写入 %d 条更新、%d 条审计记录，提交 %d 次", tt.name, len(updates), len(history), f.commits)
+			}
+			continue
+		}
+		if len(updates) != 1 || f.commits != 1 {
+			t.Fatalf("%s: 写入 %d 条更新，提交 %d 次", tt.name, len(updates), f.commits)
+		}
+		if args := updates[0].args; args[len(args)-2] != int64(tt.version) || args[len(args)-1] != int64(1) {
+			t.Errorf("%s: 更新参数 %v", tt.name, args)
+		}
+		if len(history) != len(tt.want) {
+			t.Fatalf("%s: 写入 %d 条审计记录，期望 %d", tt.name, len(history), len(tt.want))
+		}
+		for i, e := range history {
+			if got := historyArgs(e); !reflect.DeepEqual(got, tt.want[i]) {
+				t.Errorf("%s: 第 %d 条审计记录 %v，期望 %v", tt.name, i+1, got, tt.want[i])
+			}
+		}
+	}
+}
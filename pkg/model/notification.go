@@ -0,0 +1,141 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrNotificationNotFound 表示消息不存在或不属于当前用户
+var ErrNotificationNotFound = errors.New("消息不存在")
+
+// Notification 站内消息，对应 notifications 表。ReadAt 为空表示未读
+type Notification struct {
+	ID        int64  `json:"id"`
+	UserID    int    `json:"user_id"`
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	AssetID   int    `json:"asset_id"`
+	CreatedAt string `json:"created_at"`
+	ReadAt    string `json:"read_at"`
+}
+
+// NotificationRepository 站内消息存储接口，用户只能读取和标记自己的消息
+type NotificationRepository interface {
+	// Create 新建消息，成功后回填 ID 和创建时间
+	Create(ctx context.Context, n *Notification) error
+	// List 按创建时间倒序返回用户的消息，unreadOnly 为 true 时只返回未读消息，limit 为返回条数上限
+	List(ctx context.Context, userID int, unreadOnly bool, limit int) ([]Notification, error)
+	// UnreadCount 返回用户的未读消息数量
+	UnreadCount(ctx context.Context, userID int) (int, error)
+	// MarkRead 把消息标记为已读，消息不存在或不属于该用户时返回 ErrNotificationNotFound
+	MarkRead(ctx context.Context, userID int, id int64) error
+	// MarkAllRead 把用户的全部未读消息标记为已读，返回标记的数量
+	MarkAllRead(ctx context.Context, userID int) (int64, error)
+}
+
+// ReminderLog 到期提醒的发送记录，用于避免重复提醒
+type ReminderLog interface {
+	// LastReminder 返回保管记录在该预计归还日期下最近一次通过 channel 发送某类提醒的时间，从未发送时返回零值
+	LastReminder(ctx context.Context, custodyID int64, kind, dueDate, channel string) (time.Time, error)
+	// RecordReminder 记录一次通过 channel 成功发送的提醒
+	RecordReminder(ctx context.Context, custodyID int64, kind, dueDate, channel string, sentAt time.Time) error
+}
+
+// MySQLNotificationRepository 基于 MySQL 的站内消息和提醒记录存储
+type MySQLNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLNotificationRepository 使用已打开的数据库连接创建站内消息存储
+func NewMySQLNotificationRepository(db *sql.DB) *MySQLNotificationRepository {
+	return &MySQLNotificationRepository{db: db}
+}
+
+const notificationColumns = `id, user_id, kind, title, COALESCE(body, ''), asset_id, created_at, COALESCE(read_at, '')`
+
+// Create 插入消息
+func (r *MySQLNotificationRepository) Create(ctx context.Context, n *Notification) error {
+	n.CreatedAt = time.Now().Format(DateTimeLayout)
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO notifications (user_id, kind, title, body, asset_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		n.UserID, n.Kind, n.Title, n.Body, n.AssetID, n.CreatedAt)
+	if err != nil {
+		return err
+	}
+	n.ID, err = result.LastInsertId()
+	return err
+}
+
+// List 返回用户的消息
+func (r *MySQLNotificationRepository) List(ctx context.Context, userID int, unreadOnly bool, limit int) ([]Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = ?`
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY created_at DESC, id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Title, &n.Body, &n.AssetID, &n.CreatedAt, &n.ReadAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// UnreadCount 返回未读消息数量
+func (r *MySQLNotificationRepository) UnreadCount(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead 标记单条消息为已读，已读的消息保持原来的阅读时间
+func (r *MySQLNotificationRepository) MarkRead(ctx context.Context, userID int, id int64) error {
+	var exists int
+	err := r.db.QueryRowContext(ctx, `SELECT 1 FROM notifications WHERE id = ? AND user_id = ?`, id, userID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return ErrNotificationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `UPDATE notifications SET read_at = ? WHERE id = ? AND read_at IS NULL`, time.Now().Format(DateTimeLayout), id)
+	return err
+}
+
+// MarkAllRead 标记用户的全部未读消息为已读
+func (r *MySQLNotificationRepository) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`, time.Now().Format(DateTimeLayout), userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// LastReminder 返回最近一次提醒的时间
+func (r *MySQLNotificationRepository) LastReminder(ctx context.Context, custodyID int64, kind, dueDate, channel string) (time.Time, error) {
+	var sentAt sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT MAX(sent_at) FROM reminder_log WHERE custody_id = ? AND kind = ? AND due_date = ? AND channel = ?`, custodyID, kind, dueDate, channel).Scan(&sentAt)
+	if err != nil || !sentAt.Valid {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation(DateTimeLayout, sentAt.String, time.Local)
+}
+
+// RecordReminder 写入提醒记录
+func (r *MySQLNotificationRepository) RecordReminder(ctx context.Context, custodyID int64, kind, dueDate, channel string, sentAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO reminder_log (custody_id, kind, due_date, channel, sent_at) VALUES (?, ?, ?, ?, ?)`,
+		custodyID, kind, dueDate, channel, sentAt.Format(DateTimeLayout))
+	return err
+}
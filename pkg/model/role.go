@@ -0,0 +1,79 @@
+package model
+
+// Role 用户角色
+type Role string
+
+const (
+	// RoleAdmin 系统管理员，拥有全部权限
+	RoleAdmin Role = "admin"
+	// RoleAssetManager 资产管理员，可维护和导出资产
+	RoleAssetManager Role = "asset_manager"
+	// RoleDepartmentLead 部门负责人，可查看、编辑和导出资产
+	RoleDepartmentLead Role = "department_lead"
+	// RoleViewer 只读用户
+	RoleViewer Role = "viewer"
+)
+
+// Permission 操作权限
+type Permission string
+
+const (
+	PermViewAsset   Permission = "asset:view"
+	PermCreateAsset Permission = "asset:create"
+	PermEditAsset   Permission = "asset:edit"
+	PermDeleteAsset Permission = "asset:delete"
+	PermExportAsset Permission = "asset:export"
+	PermManageUsers Permission = "user:manage"
+	// PermAllDepartments 不受部门范围限制，可见全部资产
+	PermAllDepartments Permission = "asset:all-departments"
+	// PermRecycleBin 查看回收站，恢复或彻底删除资产
+	PermRecycleBin Permission = "asset:recycle-bin"
+	// PermCustody 办理资产领用、借出和归还
+	PermCustody Permission = "asset:custody"
+	// PermStocktake 发起和执行资产盘点
+	PermStocktake Permission = "asset:stocktake"
+)
+
+// rolePermissions 各角色拥有的权限
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:          {PermViewAsset, PermCreateAsset, PermEditAsset, PermDeleteAsset, PermExportAsset, PermManageUsers, PermAllDepartments, PermRecycleBin, PermCustody, PermStocktake},
+	RoleAssetManager:   {PermViewAsset, PermCreateAsset, PermEditAsset, PermDeleteAsset, PermExportAsset, PermCustody, PermStocktake},
+	RoleDepartmentLead: {PermViewAsset, PermEditAsset, PermExportAsset, PermCustody, PermStocktake},
+	RoleViewer:         {PermViewAsset},
+}
+
+var roleLabels = map[Role]string{
+	RoleAdmin:          "系统管理员",
+	RoleAssetManager:   "资产管理员",
+	RoleDepartmentLead: "部门负责人",
+	RoleViewer:         "只读用户",
+}
+
+// Roles 返回全部角色，按权限从高到低排列
+func Roles() []Role {
+	return []Role{RoleAdmin, RoleAssetManager, RoleDepartmentLead, RoleViewer}
+}
+
+// Valid 判断是否为已定义的角色
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Label 返回角色的中文名称
+func (r Role) Label() string {
+	if label, ok := roleLabels[r]; ok {
+		return label
+	}
+	return string(r)
+}
+
+// Can 判断角色是否拥有权限
+func (r Role) Can(p Permission) bool {
+	for _, perm := range rolePermissions[r] {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
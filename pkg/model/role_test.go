@@ -0,0 +1,54 @@
+package model
+
+import "testing"
+
+// allPermissions 按 rolePermissions 中管理员权限的顺序排列
+var allPermissions = []Permission{
+	PermViewAsset, PermCreateAsset, PermEditAsset, PermDeleteAsset, PermExportAsset,
+	PermManageUsers, PermAllDepartments, PermRecycleBin, PermCustody, PermStocktake,
+}
+
+// roleMatrix 各角色对 allPermissions 中每项权限的预期，单独列出以免与 rolePermissions 一起被改错
+var roleMatrix = map[Role][]bool{
+	RoleAdmin:          {true, true, true, true, true, true, true, true, true, true},
+	RoleAssetManager:   {true, true, true, true, true, false, false, false, true, true},
+	RoleDepartmentLead: {true, false, true, false, true, false, false, false, true, true},
+	RoleViewer:         {true, false, false, false, false, false, false, false, false, false},
+}
+
+func TestRolePermissions(t *testing.T) {
+	for _, role := range Roles() {
+		want, ok := roleMatrix[role]
+		if !ok {
+			t.Errorf("角色 %s 没有预期的权限", role)
+			continue
+		}
+		for i, p := range allPermissions {
+			if got := role.Can(p); got != want[i] {
+				t.Errorf("%s.Can(%s) = %v，期望 %v", role, p, got, want[i])
+			}
+		}
+	}
+	if Role("guest").Valid() || Role("guest").Can(PermViewAsset) {
+		t.Error("未定义的角色不应该有任何权限")
+	}
+}
+
+func TestUserCan(t *testing.T) {
+	var nobody *User
+	tests := []struct {
+		name string
+		user *User
+		want bool
+	}{
+		{"未登录", nobody, false},
+		{"已停用的管理员", &User{Role: RoleAdmin, IsActive: false}, false},
+		{"启用的管理员", &User{Role: RoleAdmin, IsActive: true}, true},
+		{"未定义的角色", &User{Role: "guest", IsActive: true}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.user.Can(PermViewAsset); got != tt.want {
+			t.Errorf("%s: Can = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// FindByCode 按资产编码或序列号精确查找未删除的资产，资产编码匹配的排在前面。
+// 序列号在不同品牌间可能重复，因此可能返回多项
+func (r *MySQLAssetRepository) FindByCode(ctx context.Context, code string) ([]Asset, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return []Asset{}, nil
+	}
+	return r.queryAssets(ctx, `
+		SELECT `+assetColumns+`
+		FROM assets
+		WHERE deleted_at IS NULL AND (asset_code = ? OR serial_number = ?)
+		ORDER BY asset_code = ? DESC, created_at DESC`,
+		code, code, code)
+}
+
+// MarkSeen 把资产的最近在场时间更新为当前时间。只记录确认时间和确认人，
+// 不属于资产内容的修改，因此不增加版本，也不写变更记录
+func (r *MySQLAssetRepository) MarkSeen(ctx context.Context, actor Actor, id int) (*Asset, error) {
+	// 同一秒内重复确认时 MySQL 报告的影响行数为 0，因此不据此判断资产是否存在，由 Get 返回 ErrAssetNotFound
+	_, err := r.db.ExecContext(ctx, `UPDATE assets SET last_seen_at = ?, last_seen_by_name = ? WHERE id = ? AND deleted_at IS NULL`,
+		time.Now().Format(DateTimeLayout), actor.Username, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
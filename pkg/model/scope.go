@@ -0,0 +1,57 @@
+package model
+
+// AssetScope 用户可见的资产范围。资产的所在部门或领取部门属于范围内的任一部门即可见
+type AssetScope struct {
+	// All 为 true 时不受部门限制
+	All         bool
+	Departments []string
+}
+
+// AllAssets 不受部门限制的范围，用于缓存加载和命令行工具
+var AllAssets = AssetScope{All: true}
+
+// ScopeFor 返回用户的资产可见范围：拥有全局权限的用户可见全部资产，其他用户只能看到绑定部门的资产
+func ScopeFor(u *User) AssetScope {
+	if u == nil {
+		return AssetScope{}
+	}
+	if u.Can(PermAllDepartments) {
+		return AllAssets
+	}
+	return AssetScope{Departments: u.Departments}
+}
+
+// Allows 判断资产是否在范围内
+func (s AssetScope) Allows(a *Asset) bool {
+	return s.All || s.Includes(a.Department) || s.Includes(a.RecipientDepartment)
+}
+
+// Includes 判断部门是否在范围内
+func (s AssetScope) Includes(department string) bool {
+	if s.All {
+		return true
+	}
+	if department == "" {
+		return false
+	}
+	for _, d := range s.Departments {
+		if d == department {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter 返回范围内的资产
+func (s AssetScope) Filter(assets []Asset) []Asset {
+	if s.All {
+		return assets
+	}
+	filtered := make([]Asset, 0, len(assets))
+	for i := range assets {
+		if s.Allows(&assets[i]) {
+			filtered = append(filtered, assets[i])
+		}
+	}
+	return filtered
+}
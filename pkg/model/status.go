@@ -0,0 +1,162 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AssetStatus 资产生命周期状态
+type AssetStatus string
+
+const (
+	// StatusInStock 在库，新建资产的默认状态
+	StatusInStock AssetStatus = "in_stock"
+	// StatusInUse 在用
+	StatusInUse AssetStatus = "in_use"
+	// StatusUnderRepair 维修中
+	StatusUnderRepair AssetStatus = "under_repair"
+	// StatusLentOut 外借
+	StatusLentOut AssetStatus = "lent_out"
+	// StatusRetired 已退役，等待处置，仍可重新入库
+	StatusRetired AssetStatus = "retired"
+	// StatusDisposed 已处置（报废、变卖或捐赠），终止状态
+	StatusDisposed AssetStatus = "disposed"
+)
+
+// statusTransitions 各状态允许转换到的状态
+var statusTransitions = map[AssetStatus][]AssetStatus{
+	StatusInStock:     {StatusInUse, StatusUnderRepair, StatusLentOut, StatusRetired},
+	StatusInUse:       {StatusInStock, StatusUnderRepair, StatusRetired},
+	StatusUnderRepair: {StatusInStock, StatusInUse, StatusRetired},
+	StatusLentOut:     {StatusInStock, StatusUnderRepair},
+	StatusRetired:     {StatusInStock, StatusDisposed},
+	StatusDisposed:    {},
+}
+
+var statusLabels = map[AssetStatus]string{
+	StatusInStock:     "在库",
+	StatusInUse:       "在用",
+	StatusUnderRepair: "维修中",
+	StatusLentOut:     "外借",
+	StatusRetired:     "已退役",
+	StatusDisposed:    "已处置",
+}
+
+// ErrInvalidTransition 表示不允许的状态转换，具体内容见 TransitionError
+var ErrInvalidTransition = errors.New("不允许的状态转换")
+
+// TransitionError 资产当前状态不能转换到目标状态
+type TransitionError struct {
+	From AssetStatus
+	To   AssetStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("不能从“%s”转换为“%s”", e.From.Label(), e.To.Label())
+}
+
+// Is 使 errors.Is(err, ErrInvalidTransition) 成立
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// Statuses 按生命周期顺序返回全部状态
+func Statuses() []AssetStatus {
+	return []AssetStatus{StatusInStock, StatusInUse, StatusUnderRepair, StatusLentOut, StatusRetired, StatusDisposed}
+}
+
+// Valid 判断是否为已定义的状态
+func (s AssetStatus) Valid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// Label 返回状态的中文名称
+func (s AssetStatus) Label() string {
+	if label, ok := statusLabels[s]; ok {
+		return label
+	}
+	return string(s)
+}
+
+// Next 返回允许转换到的状态
+func (s AssetStatus) Next() []AssetStatus {
+	return append([]AssetStatus{}, statusTransitions[s]...)
+}
+
+// CanTransition 判断能否从 s 转换到 to
+func (s AssetStatus) CanTransition(to AssetStatus) bool {
+	for _, next := range statusTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// InitialStatus 返回新建资产的状态。新资产只能在库（未填写领用人）或在用（已填写领用人），
+// 其他状态须在新建后通过状态转换进入；status 为空时按是否填写领用人取默认值
+func InitialStatus(status AssetStatus, recipient string) (AssetStatus, error) {
+	want := StatusInStock
+	if recipient != "" {
+		want = StatusInUse
+	}
+	if status == "" || status == want {
+		return want, nil
+	}
+	if !status.Valid() {
+		return "", fmt.Errorf("无效的资产状态: %s", status)
+	}
+	if recipient != "" {
+		return "", fmt.Errorf("填写了领用人的新资产只能是“%s”状态", StatusInUse.Label())
+	}
+	return "", fmt.Errorf("新资产只能是“%s”状态，填写领用人时为“%s”，其他状态请在新建后通过状态转换设置", StatusInStock.Label(), StatusInUse.Label())
+}
+
+// Transition 在事务中转换资产状态并记录原因和操作人。
+// version 非 0 时与当前版本比较，不一致返回 *VersionConflictError；不允许的转换返回 *TransitionError；
+// 资产未归还时不能转为在库、已退役或已处置，返回 *CheckedOutError
+func (r *MySQLAssetRepository) Transition(ctx context.Context, actor Actor, id int, to AssetStatus, reason string, version int) (*Asset, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := lockAsset(ctx, tx, id, version)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !asset.Status.CanTransition(to) {
+		tx.Rollback()
+		return nil, &TransitionError{From: asset.Status, To: to}
+	}
+	if to == StatusInStock || to == StatusRetired || to == StatusDisposed {
+		open, err := openCustody(ctx, tx, id)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if open != nil {
+			tx.Rollback()
+			return nil, &CheckedOutError{Holder: open.Holder}
+		}
+	}
+
+	from := asset.Status
+	asset.Status = to
+	asset.Version++
+	if _, err := tx.ExecContext(ctx, `UPDATE assets SET status = ?, version = ? WHERE id = ?`, to, asset.Version, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	change := AssetChange{Field: "status", OldValue: string(from), NewValue: string(to), Reason: reason}
+	if err := recordHistory(ctx, tx, actor, id, asset.Version, ActionTransition, []AssetChange{change}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
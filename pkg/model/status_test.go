@@ -0,0 +1,86 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[AssetStatus][]AssetStatus{
+		StatusInStock:     {StatusInUse, StatusUnderRepair, StatusLentOut, StatusRetired},
+		StatusInUse:       {StatusInStock, StatusUnderRepair, StatusRetired},
+		StatusUnderRepair: {StatusInStock, StatusInUse, StatusRetired},
+		StatusLentOut:     {StatusInStock, StatusUnderRepair},
+		StatusRetired:     {StatusInStock, StatusDisposed},
+		StatusDisposed:    nil,
+	}
+	for _, from := range Statuses() {
+		want := map[AssetStatus]bool{}
+		for _, to := range allowed[from] {
+			want[to] = true
+		}
+		for _, to := range append(Statuses(), "unknown") {
+			if got := from.CanTransition(to); got != want[to] {
+				t.Errorf("%s -> %s: %v，期望 %v", from, to, got, want[to])
+			}
+		}
+	}
+	if AssetStatus("unknown").CanTransition(StatusInStock) {
+		t.Error("未知状态不应允许转换")
+	}
+}
+
+func TestStatusHelpers(t *testing.T) {
+	for _, s := range Statuses() {
+		if !s.Valid() {
+			t.Errorf("%s 应为有效状态", s)
+		}
+		if s.Label() == string(s) {
+			t.Errorf("%s 缺少中文名称", s)
+		}
+	}
+	if AssetStatus("lost").Valid() || AssetStatus("").Valid() {
+		t.Error("未定义的状态不应有效")
+	}
+	if got := AssetStatus("lost").Label(); got != "lost" {
+		t.Errorf("未知状态名称 %q", got)
+	}
+	next := StatusInStock.Next()
+	next[0] = StatusDisposed
+	if StatusInStock.Next()[0] != StatusInUse {
+		t.Error("Next 应返回副本")
+	}
+	if len(StatusDisposed.Next()) != 0 {
+		t.Error("已处置是终止状态")
+	}
+	err := error(&TransitionError{From: StatusDisposed, To: StatusInStock})
+	if !errors.Is(err, ErrInvalidTransition) || err.Error() != "不能从“已处置”转换为“在库”" {
+		t.Errorf("TransitionError: %v", err)
+	}
+}
+
+func TestInitialStatus(t *testing.T) {
+	tests := []struct {
+		status    AssetStatus
+		recipient string
+		want      AssetStatus
+		ok        bool
+	}{
+		{"", "", StatusInStock, true},
+		{"", "张三", StatusInUse, true},
+		{StatusInStock, "", StatusInStock, true},
+		{StatusInUse, "张三", StatusInUse, true},
+		{StatusInStock, "张三", "", false},
+		{StatusInUse, "", "", false},
+		{StatusLentOut, "张三", "", false},
+		{StatusRetired, "", "", false},
+		{StatusDisposed, "", "", false},
+		{"lost", "", "", false},
+	}
+	for _, tt := range tests {
+		got, err := InitialStatus(tt.status, tt.recipient)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("InitialStatus(%q, %q) = %q, %v，期望 %q", tt.status, tt.recipient, got, err, tt.want)
+		}
+	}
+}
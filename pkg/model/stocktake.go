@@ -0,0 +1,456 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActionStocktake 按盘点结果更正资产所在地，Reason 记录盘点名称
+const ActionStocktake = "stocktake"
+
+// ErrStocktakeNotFound 表示盘点不存在
+var ErrStocktakeNotFound = errors.New("盘点不存在")
+
+// ErrStocktakeClosed 表示盘点已结束，不能再登记资产
+var ErrStocktakeClosed = errors.New("盘点已结束")
+
+// StocktakeStatus 盘点状态
+type StocktakeStatus string
+
+const (
+	// StocktakeOpen 进行中，可以登记盘到的资产
+	StocktakeOpen StocktakeStatus = "open"
+	// StocktakeClosed 已结束，只能查看差异和更正所在地
+	StocktakeClosed StocktakeStatus = "closed"
+)
+
+// 登记盘到资产的方式
+const (
+	// FoundByScan 扫描资产标签
+	FoundByScan = "scan"
+	// FoundManually 在清单中手动确认
+	FoundManually = "manual"
+)
+
+// Stocktake 一次盘点，对应 stocktakes 表。Department、Location 为盘点范围，为空表示不限；
+// Location 按前缀匹配，如“北京总部”包含“北京总部 5F”。Expected、Found、Unexpected 为统计数量，不含已移入回收站的资产
+type Stocktake struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Department    string          `json:"department"`
+	Location      string          `json:"location"`
+	Note          string          `json:"note"`
+	Status        StocktakeStatus `json:"status"`
+	CreatedAt     string          `json:"created_at"`
+	CreatedBy     int             `json:"created_by"`
+	CreatedByName string          `json:"created_by_name"`
+	ClosedAt      string          `json:"closed_at"`
+	ClosedByName  string          `json:"closed_by_name"`
+	// Expected 开始盘点时范围内的资产数量
+	Expected int `json:"expected"`
+	// Found 已盘到的范围内资产数量
+	Found int `json:"found"`
+	// Unexpected 盘到的范围外资产数量
+	Unexpected int `json:"unexpected"`
+}
+
+// StocktakeItem 盘点明细：一项应盘或盘到的资产。ExpectedDepartment、ExpectedLocation 为登记时资产的部门和所在地，
+// Asset 为资产的当前内容。FoundAt 为空表示尚未盘到，AppliedAt 非空表示已按实盘所在地更正
+type StocktakeItem struct {
+	ID                 int64  `json:"id"`
+	StocktakeID        int    `json:"stocktake_id"`
+	AssetID            int    `json:"asset_id"`
+	Expected           bool   `json:"expected"`
+	ExpectedDepartment string `json:"expected_department"`
+	ExpectedLocation   string `json:"expected_location"`
+	FoundAt            string `json:"found_at"`
+	FoundLocation      string `json:"found_location"`
+	FoundMethod        string `json:"found_method"`
+	FoundBy            int    `json:"found_by"`
+	FoundByName        string `json:"found_by_name"`
+	Note               string `json:"note"`
+	AppliedAt          string `json:"applied_at"`
+	Asset              Asset  `json:"asset"`
+}
+
+// Found 判断是否已盘到
+func (i *StocktakeItem) Found() bool {
+	return i.FoundAt != ""
+}
+
+// Relocated 判断盘到的位置与资产登记的所在地不同，需要更正
+func (i *StocktakeItem) Relocated() bool {
+	return i.Found() && i.FoundLocation != "" && i.FoundLocation != i.Asset.Location
+}
+
+// StocktakeFind 登记盘到资产的内容。Location 为实际所在地，为空表示与登记的一致
+type StocktakeFind struct {
+	Location string
+	Method   string
+	Note     string
+}
+
+// StocktakeReport 盘点差异：缺失（应盘未盘到）、范围外（盘到但不在开始时的范围内）和位置变化（实盘所在地与登记的不同）。
+// 范围外的资产位置不同时同时出现在位置变化中
+type StocktakeReport struct {
+	Missing    []StocktakeItem `json:"missing"`
+	Unexpected []StocktakeItem `json:"unexpected"`
+	Relocated  []StocktakeItem `json:"relocated"`
+}
+
+// Reconcile 按盘点明细生成差异报告
+func Reconcile(items []StocktakeItem) StocktakeReport {
+	report := StocktakeReport{Missing: []StocktakeItem{}, Unexpected: []StocktakeItem{}, Relocated: []StocktakeItem{}}
+	for _, item := range items {
+		if item.Expected && !item.Found() {
+			report.Missing = append(report.Missing, item)
+		}
+		if !item.Expected {
+			report.Unexpected = append(report.Unexpected, item)
+		}
+		if item.Relocated() {
+			report.Relocated = append(report.Relocated, item)
+		}
+	}
+	return report
+}
+
+// StocktakeRepository 盘点存储接口
+type StocktakeRepository interface {
+	// Create 新建盘点并登记范围内的全部资产（已处置的除外），成功后回填 ID、状态、创建时间和应盘数量
+	Create(ctx context.Context, actor Actor, st *Stocktake) error
+	// List 按创建时间倒序返回全部盘点
+	List(ctx context.Context) ([]Stocktake, error)
+	// Get 按 ID 获取盘点，不存在时返回 ErrStocktakeNotFound
+	Get(ctx context.Context, id int) (*Stocktake, error)
+	// Items 返回盘点明细，移入回收站的资产不再出现
+	Items(ctx context.Context, id int) ([]StocktakeItem, error)
+	// Find 登记盘到资产，同时记录资产在场。资产不在盘点范围内时追加为范围外资产；
+	// 重复登记时更新实盘信息，duplicate 为 true，f.Location 为空时保留之前登记的实盘所在地。盘点已结束时返回 ErrStocktakeClosed
+	Find(ctx context.Context, actor Actor, id, assetID int, f StocktakeFind) (item *StocktakeItem, duplicate bool, err error)
+	// Close 结束盘点，已结束时返回 ErrStocktakeClosed
+	Close(ctx context.Context, actor Actor, id int) error
+	// ApplyLocations 把位置变化的资产所在地更正为实盘所在地并写入变更记录，返回更正的资产 ID。
+	// 不是位置变化的资产忽略
+	ApplyLocations(ctx context.Context, actor Actor, id int, assetIDs []int) ([]int, error)
+}
+
+// MySQLStocktakeRepository 基于 MySQL 的盘点存储
+type MySQLStocktakeRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLStocktakeRepository 使用已打开的数据库连接创建盘点存储
+func NewMySQLStocktakeRepository(db *sql.DB) *MySQLStocktakeRepository {
+	return &MySQLStocktakeRepository{db: db}
+}
+
+// stocktakeItemsJoin 盘点明细及对应的资产，已移入回收站的资产不计入明细
+const stocktakeItemsJoin = `stocktake_items i JOIN assets a ON a.id = i.asset_id AND a.deleted_at IS NULL`
+
+// stocktakeQuery 查询盘点及统计数量，顺序与 scanStocktake 保持一致；统计与 Items 返回的明细一致
+const stocktakeQuery = `
+	SELECT s.id, s.name, s.department, s.location, s.note, s.status, s.created_at, s.created_by, s.created_by_name, COALESCE(s.closed_at, ''), s.closed_by_name,
+		COALESCE(SUM(i.expected = 1), 0), COALESCE(SUM(i.expected = 1 AND i.found_at IS NOT NULL), 0), COALESCE(SUM(i.expected = 0), 0)
+	FROM stocktakes s LEFT JOIN (` + stocktakeItemsJoin + `) ON i.stocktake_id = s.id`
+
+func scanStocktake(row rowScanner) (*Stocktake, error) {
+	var st Stocktake
+	err := row.Scan(&st.ID, &st.Name, &st.Department, &st.Location, &st.Note, &st.Status, &st.CreatedAt, &st.CreatedBy, &st.CreatedByName, &st.ClosedAt, &st.ClosedByName,
+		&st.Expected, &st.Found, &st.Unexpected)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// qualifiedAssetColumns 带表别名 a 的 assetColumns，用于连接查询
+var qualifiedAssetColumns = "a." + strings.Join(strings.Split(assetColumns, ", "), ", a.")
+
+// stocktakeItemColumns 查询盘点明细时资产列之后的列，顺序与 scanStocktakeItem 保持一致
+const stocktakeItemColumns = `i.id, i.stocktake_id, i.asset_id, i.expected, i.expected_department, i.expected_location, COALESCE(i.found_at, ''), i.found_location, i.found_method, i.found_by, i.found_by_name, i.note, COALESCE(i.applied_at, '')`
+
+func scanStocktakeItem(row rowScanner) (*StocktakeItem, error) {
+	var item StocktakeItem
+	asset, err := scanAsset(row, &item.ID, &item.StocktakeID, &item.AssetID, &item.Expected, &item.ExpectedDepartment, &item.ExpectedLocation,
+		&item.FoundAt, &item.FoundLocation, &item.FoundMethod, &item.FoundBy, &item.FoundByName, &item.Note, &item.AppliedAt)
+	if err != nil {
+		return nil, err
+	}
+	item.Asset = *asset
+	return &item, nil
+}
+
+// Create 在事务中新建盘点，并把范围内的资产登记为应盘资产
+func (r *MySQLStocktakeRepository) Create(ctx context.Context, actor Actor, st *Stocktake) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	createdAt := time.Now().Format(DateTimeLayout)
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO stocktakes (name, department, location, note, status, created_at, created_by, created_by_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		st.Name, st.Department, st.Location, st.Note, StocktakeOpen, createdAt, actor.UserID, actor.Username)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `
+		INSERT INTO stocktake_items (stocktake_id, asset_id, expected, expected_department, expected_location)
+		SELECT ?, id, 1, COALESCE(department, ''), COALESCE(location, '')
+		FROM assets
+		WHERE deleted_at IS NULL AND status <> ?`
+	args := []interface{}{id, StatusDisposed}
+	if st.Department != "" {
+		query += ` AND department = ?`
+		args = append(args, st.Department)
+	}
+	if st.Location != "" {
+		// 前缀匹配，不使用 LIKE 以免所在地中的 % 和 _ 被当作通配符
+		query += ` AND LEFT(location, CHAR_LENGTH(?)) = ?`
+		args = append(args, st.Location, st.Location)
+	}
+	result, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	expected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	st.ID, st.Status, st.CreatedAt = int(id), StocktakeOpen, createdAt
+	st.CreatedBy, st.CreatedByName = actor.UserID, actor.Username
+	st.Expected, st.Found, st.Unexpected = int(expected), 0, 0
+	return nil
+}
+
+// List 返回全部盘点
+func (r *MySQLStocktakeRepository) List(ctx context.Context) ([]Stocktake, error) {
+	rows, err := r.db.QueryContext(ctx, stocktakeQuery+` GROUP BY s.id ORDER BY s.created_at DESC, s.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocktakes := []Stocktake{}
+	for rows.Next() {
+		st, err := scanStocktake(rows)
+		if err != nil {
+			return nil, err
+		}
+		stocktakes = append(stocktakes, *st)
+	}
+	return stocktakes, rows.Err()
+}
+
+// Get 按 ID 获取盘点
+func (r *MySQLStocktakeRepository) Get(ctx context.Context, id int) (*Stocktake, error) {
+	st, err := scanStocktake(r.db.QueryRowContext(ctx, stocktakeQuery+` WHERE s.id = ? GROUP BY s.id`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrStocktakeNotFound
+	}
+	return st, err
+}
+
+// Items 按登记顺序返回盘点明细，应盘资产在前
+func (r *MySQLStocktakeRepository) Items(ctx context.Context, id int) ([]StocktakeItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+qualifiedAssetColumns+`, `+stocktakeItemColumns+`
+		FROM `+stocktakeItemsJoin+`
+		WHERE i.stocktake_id = ?
+		ORDER BY i.expected DESC, i.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []StocktakeItem{}
+	for rows.Next() {
+		item, err := scanStocktakeItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// Find 在事务中登记盘到资产并更新资产的最近在场时间
+func (r *MySQLStocktakeRepository) Find(ctx context.Context, actor Actor, id, assetID int, f StocktakeFind) (*StocktakeItem, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := lockOpenStocktake(ctx, tx, id); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	var department, location sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT department, location FROM assets WHERE id = ? AND deleted_at IS NULL`, assetID).Scan(&department, &location)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, false, ErrAssetNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+
+	now := time.Now().Format(DateTimeLayout)
+	var itemID int64
+	var foundAt sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT id, found_at FROM stocktake_items WHERE stocktake_id = ? AND asset_id = ? FOR UPDATE`, id, assetID).Scan(&itemID, &foundAt)
+	switch {
+	case err == sql.ErrNoRows:
+		// 不在开始盘点时的范围内
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stocktake_items (stocktake_id, asset_id, expected, expected_department, expected_location, found_at, found_location, found_method, found_by, found_by_name, note)
+			VALUES (?, ?, 0, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, assetID, department.String, location.String, now, f.Location, f.Method, actor.UserID, actor.Username, f.Note)
+	case err == nil:
+		// 重复扫码时通常不再填写所在地，不能清掉之前登记的实盘所在地
+		_, err = tx.ExecContext(ctx, `
+			UPDATE stocktake_items SET found_at = ?, found_location = IF(? = '', found_location, ?), found_method = ?, found_by = ?, found_by_name = ?, note = ?
+			WHERE id = ?`,
+			now, f.Location, f.Location, f.Method, actor.UserID, actor.Username, f.Note, itemID)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE assets SET last_seen_at = ?, last_seen_by_name = ? WHERE id = ?`, now, actor.Username, assetID); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	item, err := scanStocktakeItem(r.db.QueryRowContext(ctx, `
+		SELECT `+qualifiedAssetColumns+`, `+stocktakeItemColumns+`
+		FROM stocktake_items i JOIN assets a ON a.id = i.asset_id
+		WHERE i.stocktake_id = ? AND i.asset_id = ?`, id, assetID))
+	if err != nil {
+		return nil, false, err
+	}
+	return item, foundAt.Valid, nil
+}
+
+// lockOpenStocktake 在事务中锁定进行中的盘点
+func lockOpenStocktake(ctx context.Context, tx *sql.Tx, id int) error {
+	var status StocktakeStatus
+	err := tx.QueryRowContext(ctx, `SELECT status FROM stocktakes WHERE id = ? FOR UPDATE`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrStocktakeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != StocktakeOpen {
+		return ErrStocktakeClosed
+	}
+	return nil
+}
+
+// Close 结束盘点，记录结束时间和操作人
+func (r *MySQLStocktakeRepository) Close(ctx context.Context, actor Actor, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := lockOpenStocktake(ctx, tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE stocktakes SET status = ?, closed_at = ?, closed_by_name = ? WHERE id = ?`,
+		StocktakeClosed, time.Now().Format(DateTimeLayout), actor.Username, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ApplyLocations 在一个事务中更正资产所在地，每项资产增加版本并写入变更记录，原因为盘点名称
+func (r *MySQLStocktakeRepository) ApplyLocations(ctx context.Context, actor Actor, id int, assetIDs []int) ([]int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var name string
+	err = tx.QueryRowContext(ctx, `SELECT name FROM stocktakes WHERE id = ? FOR UPDATE`, id).Scan(&name)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil, ErrStocktakeNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now().Format(DateTimeLayout)
+	reason := fmt.Sprintf("盘点“%s”更正所在地", name)
+	applied := []int{}
+	for _, assetID := range assetIDs {
+		var itemID int64
+		var foundLocation string
+		err := tx.QueryRowContext(ctx, `
+			SELECT id, found_location FROM stocktake_items
+			WHERE stocktake_id = ? AND asset_id = ? AND found_at IS NOT NULL AND found_location <> '' FOR UPDATE`,
+			id, assetID).Scan(&itemID, &foundLocation)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		asset, err := lockAsset(ctx, tx, assetID, 0)
+		if err == ErrAssetNotFound {
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if asset.Location == foundLocation {
+			continue
+		}
+
+		version := asset.Version + 1
+		if _, err := tx.ExecContext(ctx, `UPDATE assets SET location = ?, version = ? WHERE id = ?`, foundLocation, version, assetID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		change := AssetChange{Field: "location", OldValue: asset.Location, NewValue: foundLocation, Reason: reason}
+		if err := recordHistory(ctx, tx, actor, assetID, version, ActionStocktake, []AssetChange{change}); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE stocktake_items SET applied_at = ? WHERE id = ?`, now, itemID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		applied = append(applied, assetID)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
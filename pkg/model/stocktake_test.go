@@ -0,0 +1,110 @@
+package model
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	const found = "2024-03-05 10:00:00"
+	item := func(id int, expected bool, foundAt, location, foundLocation string) StocktakeItem {
+		return StocktakeItem{AssetID: id, Expected: expected, FoundAt: foundAt, FoundLocation: foundLocation, Asset: Asset{ID: id, Location: location}}
+	}
+	tests := []struct {
+		name                           string
+		item                           StocktakeItem
+		missing, unexpected, relocated bool
+	}{
+		{"应盘已盘到", item(1, true, found, "5F", ""), false, false, false},
+		{"应盘未盘到", item(2, true, "", "5F", ""), true, false, false},
+		{"实盘所在地与登记的相同", item(3, true, found, "5F", "5F"), false, false, false},
+		{"位置变化", item(4, true, found, "5F", "6F"), false, false, true},
+		{"未盘到时不算位置变化", item(5, true, "", "5F", "6F"), true, false, false},
+		{"范围外", item(6, false, found, "上海", ""), false, true, false},
+		{"范围外且位置变化", item(7, false, found, "上海", "5F"), false, true, true},
+	}
+
+	var items []StocktakeItem
+	var missing, unexpected, relocated []int
+	for _, tt := range tests {
+		items = append(items, tt.item)
+		if tt.missing {
+			missing = append(missing, tt.item.AssetID)
+		}
+		if tt.unexpected {
+			unexpected = append(unexpected, tt.item.AssetID)
+		}
+		if tt.relocated {
+			relocated = append(relocated, tt.item.AssetID)
+		}
+
+		report := Reconcile([]StocktakeItem{tt.item})
+		got := [3]bool{len(report.Missing) == 1, len(report.Unexpected) == 1, len(report.Relocated) == 1}
+		if want := [3]bool{tt.missing, tt.unexpected, tt.relocated}; got != want {
+			t.Errorf("%s: 缺失、范围外、位置变化为 %v，期望 %v", tt.name, got, want)
+		}
+	}
+
+	// 多项明细时保持原有顺序
+	ids := func(items []StocktakeItem) []int {
+		var ids []int
+		for _, item := range items {
+			ids = append(ids, item.AssetID)
+		}
+		return ids
+	}
+	report := Reconcile(items)
+	if got := ids(report.Missing); !reflect.DeepEqual(got, missing) {
+		t.Errorf("缺失 %v，期望 %v", got, missing)
+	}
+	if got := ids(report.Unexpected); !reflect.DeepEqual(got, unexpected) {
+		t.Errorf("范围外 %v，期望 %v", got, unexpected)
+	}
+	if got := ids(report.Relocated); !reflect.DeepEqual(got, relocated) {
+		t.Errorf("位置变化 %v，期望 %v", got, relocated)
+	}
+
+	// 没有差异时返回空数组而不是 null，便于前端直接使用
+	empty := Reconcile(nil)
+	if empty.Missing == nil || empty.Unexpected == nil || empty.Relocated == nil {
+		t.Errorf("没有明细时差异为 %+v", empty)
+	}
+}
+
+// TestStocktakeCountsExcludeDeleted 盘点的统计数量和明细使用相同的连接条件，已移入回收站的资产都不计入
+func TestStocktakeCountsExcludeDeleted(t *testing.T) {
+	f := &fakeDB{}
+	columns := []string{"id", "name", "department", "location", "note", "status", "created_at", "created_by", "created_by_name", "closed_at", "closed_by_name", "expected", "found", "unexpected"}
+	f.on("FROM stocktakes s", columns,
+		[]driver.Value{int64(1), "季度盘点", "IT", "", "", string(StocktakeOpen), "2026-03-05 09:00:00", int64(1), "admin", "", "", int64(2), int64(1), int64(0)})
+	f.on("FROM stocktake_items i", assetColumnNames)
+	repo := NewMySQLStocktakeRepository(openFakeDB(t, f))
+	ctx := context.Background()
+
+	st, err := repo.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Expected != 2 || st.Found != 1 || st.Unexpected != 0 {
+		t.Errorf("统计数量 %+v", st)
+	}
+	if _, err := repo.List(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Items(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.queries) != 3 {
+		t.Fatalf("执行了 %d 条查询", len(f.queries))
+	}
+	for _, q := range f.queries {
+		if !strings.Contains(q, "JOIN assets a ON a.id = i.asset_id AND a.deleted_at IS NULL") {
+			t.Errorf("查询没有排除回收站中的资产: %s", q)
+		}
+	}
+}
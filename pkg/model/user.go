@@ -1,35 +1,420 @@
 package model
 
 import (
+	"context"
 	"database/sql"
-	_ "github.com/go-sql-driver/mysql"
-	"log"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUserNotFound 表示指定的用户不存在
+	ErrUserNotFound = errors.New("用户不存在")
+	// ErrUsernameTaken 表示用户名已被占用
+	ErrUsernameTaken = errors.New("用户名已存在")
+	// ErrInvalidCredentials 表示用户名或密码错误，或账号已停用
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
 )
 
-func InitDB() (*sql.DB, error) {
-	log.Println("初始化数据库连接...")
-	dataSource := "zabbix:admin123@tcp(10.0.0.96:3306)/asset_management"
-	db, err := sql.Open("mysql", dataSource)
+// MinPasswordLength 密码最小长度
+const MinPasswordLength = 8
+
+// MySQL 错误码：唯一键冲突
+const mysqlErrDupEntry = 1062
+
+// 用户不存在时参与比较的哈希，使登录耗时与用户是否存在无关
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// User 系统用户，对应 users 表
+type User struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	DisplayName  string `json:"display_name"`
+	PasswordHash string `json:"-"`
+	Role         Role   `json:"role"`
+	IsActive     bool   `json:"is_active"`
+	CreatedAt    string `json:"created_at"`
+	// Email 接收到期提醒邮件的地址，可为空
+	Email string `json:"email"`
+	// Departments 用户可见的部门范围，见 ScopeFor
+	Departments []string `json:"departments"`
+}
+
+// Can 判断用户是否拥有权限，停用的用户没有任何权限
+func (u *User) Can(p Permission) bool {
+	return u != nil && u.IsActive && u.Role.Can(p)
+}
+
+// UserRepository 用户存储接口
+type UserRepository interface {
+	// Get 按 ID 获取用户，不存在时返回 ErrUserNotFound
+	Get(ctx context.Context, id int) (*User, error)
+	// GetByUsername 按用户名获取用户，不存在时返回 ErrUserNotFound
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	// List 按用户名排序返回全部用户
+	List(ctx context.Context) ([]User, error)
+	// Create 新建用户（需已设置 PasswordHash），用户名重复时返回 ErrUsernameTaken
+	Create(ctx context.Context, user *User) error
+	// Update 更新用户名、显示名称、角色、状态和邮箱，不修改密码
+	Update(ctx context.Context, user *User) error
+	// SetPassword 更新用户密码哈希
+	SetPassword(ctx context.Context, id int, passwordHash string) error
+	// Delete 删除用户，不存在时返回 ErrUserNotFound
+	Delete(ctx context.Context, id int) error
+	// CountActiveAdmins 返回启用状态的管理员数量
+	CountActiveAdmins(ctx context.Context) (int, error)
+	// Save 在一个事务中保存用户资料、部门以及非空的新密码哈希。
+	// ID 为 0 时新建用户（必须提供密码哈希）并回填 ID，否则更新已有用户
+	Save(ctx context.Context, user *User, passwordHash string) error
+}
+
+// HashPassword 使用 bcrypt 计算密码哈希
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// ValidatePassword 检查密码强度
+func ValidatePassword(password string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return fmt.Errorf("密码长度不能少于 %d 位", MinPasswordLength)
+	}
+	if len(password) > 72 {
+		return fmt.Errorf("密码长度不能超过 72 字节")
+	}
+	return nil
+}
+
+// ValidateEmail 检查邮箱格式，空字符串表示不接收邮件
+func ValidateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	if len(email) > 255 {
+		return fmt.Errorf("邮箱长度不能超过 255 个字符")
+	}
+	if _, err := mail.ParseAddress(email); err != nil || strings.ContainsAny(email, "<> ") {
+		return fmt.Errorf("邮箱格式错误")
+	}
+	return nil
+}
+
+// ValidateUsername 检查用户名格式
+func ValidateUsername(username string) error {
+	if username == "" {
+		return fmt.Errorf("用户名不能为空")
+	}
+	if len(username) > 64 {
+		return fmt.Errorf("用户名长度不能超过 64 个字符")
+	}
+	if strings.TrimSpace(username) != username || strings.ContainsAny(username, " \t\r\n") {
+		return fmt.Errorf("用户名不能包含空白字符")
+	}
+	return nil
+}
+
+// Authenticate 校验用户名和密码，成功时返回用户；失败或账号停用时返回 ErrInvalidCredentials
+func Authenticate(ctx context.Context, users UserRepository, username, password string) (*User, error) {
+	user, err := users.GetByUsername(ctx, username)
+	if err == ErrUserNotFound {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		log.Printf("打开数据库连接失败: %v", err)
 		return nil, err
 	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil || !user.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+const userColumns = `id, username, display_name, password_hash, role, is_active, created_at, email`
+
+// MySQLUserRepository 基于 MySQL 的用户存储实现
+type MySQLUserRepository struct {
+	db *sql.DB
+}
 
-	err = db.Ping()
+// NewMySQLUserRepository 使用已打开的数据库连接创建用户存储
+func NewMySQLUserRepository(db *sql.DB) *MySQLUserRepository {
+	return &MySQLUserRepository{db: db}
+}
+
+func scanUser(row rowScanner) (*User, error) {
+	var user User
+	err := row.Scan(&user.ID, &user.Username, &user.DisplayName, &user.PasswordHash, &user.Role, &user.IsActive, &user.CreatedAt, &user.Email)
 	if err != nil {
-		log.Printf("数据库 Ping 失败: %v", err)
 		return nil, err
 	}
+	return &user, nil
+}
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDupEntry
+}
+
+// Get 按 ID 获取用户
+func (r *MySQLUserRepository) Get(ctx context.Context, id int) (*User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+}
 
-	log.Println("数据库连接成功")
-	db.SetMaxIdleConns(10)
-	db.SetMaxOpenConns(100)
+// GetByUsername 按用户名获取用户
+func (r *MySQLUserRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE username = ?`, username)
+}
+
+func (r *MySQLUserRepository) getOne(ctx context.Context, query string, arg interface{}) (*User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadDepartments(ctx, []*User{user}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
 
-	return db, nil
+// loadDepartments 一次查询填充多个用户的部门
+func (r *MySQLUserRepository) loadDepartments(ctx context.Context, users []*User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	byID := make(map[int]*User, len(users))
+	args := make([]interface{}, 0, len(users))
+	for _, u := range users {
+		u.Departments = []string{}
+		byID[u.ID] = u
+		args = append(args, u.ID)
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id, department FROM user_departments
+		WHERE user_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+		ORDER BY department`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID int
+		var department string
+		if err := rows.Scan(&userID, &department); err != nil {
+			return err
+		}
+		if u, ok := byID[userID]; ok {
+			u.Departments = append(u.Departments, department)
+		}
+	}
+	return rows.Err()
 }
 
-// 验证用户（示例函数，需根据实际需求实现）
-func ValidateUser(username, password string) bool {
-	log.Printf("验证用户: username=%s, password=%s", username, password)
-	return username == "admin" && password == "admin"
-}
\ No newline at end of file
+// List 返回全部用户
+func (r *MySQLUserRepository) List(ctx context.Context) ([]User, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ptrs []*User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		ptrs = append(ptrs, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := r.loadDepartments(ctx, ptrs); err != nil {
+		return nil, err
+	}
+
+	users := make([]User, 0, len(ptrs))
+	for _, u := range ptrs {
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+// Create 插入用户
+func (r *MySQLUserRepository) Create(ctx context.Context, user *User) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (username, display_name, password_hash, role, is_active, email)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		user.Username, user.DisplayName, user.PasswordHash, user.Role, user.IsActive, user.Email)
+	if isDuplicateEntry(err) {
+		return ErrUsernameTaken
+	}
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	user.ID = int(id)
+	return nil
+}
+
+// Update 更新用户资料和状态
+func (r *MySQLUserRepository) Update(ctx context.Context, user *User) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET username = ?, display_name = ?, role = ?, is_active = ?, email = ?
+		WHERE id = ?`,
+		user.Username, user.DisplayName, user.Role, user.IsActive, user.Email, user.ID)
+	if isDuplicateEntry(err) {
+		return ErrUsernameTaken
+	}
+	if err != nil {
+		return err
+	}
+	return r.checkUpdated(ctx, result, user.ID)
+}
+
+// SetPassword 更新密码哈希
+func (r *MySQLUserRepository) SetPassword(ctx context.Context, id int, passwordHash string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	return r.checkUpdated(ctx, result, id)
+}
+
+// Delete 删除用户及其部门绑定
+func (r *MySQLUserRepository) Delete(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := requireAffected(result, ErrUserNotFound); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_departments WHERE user_id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Save 在事务中保存用户资料、新密码和部门，任一步失败时全部回滚
+func (r *MySQLUserRepository) Save(ctx context.Context, user *User, passwordHash string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := saveUser(ctx, tx, user, passwordHash); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveUser(ctx context.Context, tx *sql.Tx, user *User, passwordHash string) error {
+	if user.ID == 0 {
+		if passwordHash == "" {
+			return fmt.Errorf("新建用户必须设置密码")
+		}
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO users (username, display_name, password_hash, role, is_active, email)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			user.Username, user.DisplayName, passwordHash, user.Role, user.IsActive, user.Email)
+		if isDuplicateEntry(err) {
+			return ErrUsernameTaken
+		}
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		user.ID = int(id)
+		user.PasswordHash = passwordHash
+	} else {
+		// 先加锁确认用户存在，避免依赖 RowsAffected（值未变化时为 0）
+		var id int
+		err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = ? FOR UPDATE`, user.ID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE users SET username = ?, display_name = ?, role = ?, is_active = ?, email = ?
+			WHERE id = ?`,
+			user.Username, user.DisplayName, user.Role, user.IsActive, user.Email, user.ID)
+		if isDuplicateEntry(err) {
+			return ErrUsernameTaken
+		}
+		if err != nil {
+			return err
+		}
+		if passwordHash != "" {
+			if _, err := tx.ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, user.ID); err != nil {
+				return err
+			}
+			user.PasswordHash = passwordHash
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_departments WHERE user_id = ?`, user.ID); err != nil {
+		return err
+	}
+	for _, department := range user.Departments {
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO user_departments (user_id, department) VALUES (?, ?)`, user.ID, department); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountActiveAdmins 统计启用的管理员
+func (r *MySQLUserRepository) CountActiveAdmins(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role = ? AND is_active = 1`, RoleAdmin).Scan(&count)
+	return count, err
+}
+
+// requireAffected 在 UPDATE/DELETE 未影响任何行时返回 notFound
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// checkUpdated 确认 UPDATE 的目标用户存在。MySQL 默认返回实际变更的行数，
+// 值未变化的 UPDATE 同样得到 0，因此需要再按 ID 查询确认
+func (r *MySQLUserRepository) checkUpdated(ctx context.Context, result sql.Result, id int) error {
+	if requireAffected(result, ErrUserNotFound) == nil {
+		return nil
+	}
+	_, err := r.Get(ctx, id)
+	return err
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateUserFields(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"用户名正常", ValidateUsername("zhang.san"), ""},
+		{"用户名为空", ValidateUsername(""), "不能为空"},
+		{"用户名含空格", ValidateUsername("zhang san"), "空白字符"},
+		{"用户名过长", ValidateUsername(strings.Repeat("a", 65)), "64"},
+		{"密码正常", ValidatePassword("Secret-123"), ""},
+		{"密码过短", ValidatePassword("short"), "不能少于"},
+		{"密码超过 72 字节", ValidatePassword(strings.Repeat("密", 25)), "72"},
+		{"邮箱为空", ValidateEmail(""), ""},
+		{"邮箱正常", ValidateEmail("zhang@example.com"), ""},
+		{"邮箱带显示名", ValidateEmail("张三 <zhang@example.com>"), "格式错误"},
+		{"邮箱缺少 @", ValidateEmail("zhang.example.com"), "格式错误"},
+	}
+	for _, tt := range tests {
+		if tt.want == "" {
+			if tt.err != nil {
+				t.Errorf("%s: %v", tt.name, tt.err)
+			}
+			continue
+		}
+		if tt.err == nil || !strings.Contains(tt.err.Error(), tt.want) {
+			t.Errorf("%s: 错误 %v，期望包含 %q", tt.name, tt.err, tt.want)
+		}
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == "Secret-123" || !strings.HasPrefix(hash, "$2") {
+		t.Errorf("哈希格式错误: %q", hash)
+	}
+}
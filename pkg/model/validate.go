@@ -0,0 +1,77 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidateAssetFields 检查资产的必填字段，规则与资产表单、JSON 接口和批量导入一致
+func ValidateAssetFields(a *Asset) error {
+	required := []struct {
+		value, label string
+	}{
+		{a.SerialNumber, "序列号"},
+		{a.Name, "资产名称"},
+		{a.Category, "设备类型"},
+		{a.Brand, "品牌"},
+		{a.Department, "所在部门"},
+		{a.Location, "所在地"},
+		{a.Supplier, "供应商"},
+	}
+	for _, f := range required {
+		if f.value == "" {
+			return fmt.Errorf("%s不能为空", f.label)
+		}
+	}
+	// 领用人可以为空（资产在库）；填写领用人时必须同时填写领取部门
+	if a.Recipient != "" && a.RecipientDepartment == "" {
+		return fmt.Errorf("领取部门不能为空")
+	}
+	return nil
+}
+
+// PrepareAsset 校验资产并把日期规范为 YYYY-MM-DD，创建日期为空时使用当天
+func PrepareAsset(a *Asset) error {
+	if err := ValidateAssetFields(a); err != nil {
+		return err
+	}
+	if a.Status != "" && !a.Status.Valid() {
+		return fmt.Errorf("无效的资产状态: %s", a.Status)
+	}
+	dates := []struct {
+		value *string
+		label string
+	}{
+		{&a.ApplicationDate, "申请时间"},
+		{&a.OrderDate, "订购日期"},
+		{&a.CreatedAt, "创建日期"},
+		{&a.ExpectedReturnDate, "预计归还日期"},
+	}
+	for _, d := range dates {
+		if *d.value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", *d.value)
+		if err != nil {
+			return fmt.Errorf("%s格式错误，应为 YYYY-MM-DD", d.label)
+		}
+		*d.value = t.Format("2006-01-02")
+	}
+	if a.CreatedAt == "" {
+		a.CreatedAt = time.Now().Format("2006-01-02")
+	}
+	return nil
+}
+
+// PrepareNewAsset 在 PrepareAsset 的基础上确定新资产的初始状态，见 InitialStatus
+func PrepareNewAsset(a *Asset) error {
+	if err := PrepareAsset(a); err != nil {
+		return err
+	}
+	status, err := InitialStatus(a.Status, a.Recipient)
+	if err != nil {
+		return err
+	}
+	a.Status = status
+	return nil
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrepareAsset(t *testing.T) {
+	valid := func() Asset {
+		return Asset{SerialNumber: "SN-1", Name: "笔记本电脑", Category: "笔记本", Brand: "联想", Department: "IT", Location: "北京", Supplier: "京东"}
+	}
+	tests := []struct {
+		name   string
+		modify func(*Asset)
+		err    string
+	}{
+		{"完整", func(a *Asset) {}, ""},
+		{"缺少序列号", func(a *Asset) { a.SerialNumber = "" }, "序列号不能为空"},
+		{"缺少供应商", func(a *Asset) { a.Supplier = "" }, "供应商不能为空"},
+		{"领用人缺少领取部门", func(a *Asset) { a.Recipient = "张三" }, "领取部门不能为空"},
+		{"日期格式错误", func(a *Asset) { a.OrderDate = "2024/03/05" }, "订购日期格式错误"},
+		{"无效状态", func(a *Asset) { a.Status = "lost" }, "无效的资产状态"},
+	}
+	for _, tt := range tests {
+		a := valid()
+		tt.modify(&a)
+		err := PrepareAsset(&a)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			} else if a.CreatedAt != time.Now().Format("2006-01-02") {
+				t.Errorf("%s: 创建日期应默认为当天，实际 %q", tt.name, a.CreatedAt)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: 错误 %v，期望包含 %q", tt.name, err, tt.err)
+		}
+	}
+
+	a := valid()
+	a.Recipient, a.RecipientDepartment = "张三", "IT"
+	if err := PrepareNewAsset(&a); err != nil || a.Status != StatusInUse {
+		t.Errorf("填写领用人的新资产: %q, %v", a.Status, err)
+	}
+}
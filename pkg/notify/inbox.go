@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"asset-management-system/pkg/model"
+	"context"
+	"fmt"
+)
+
+// InboxNotifier 把提醒写入接收人的站内消息
+type InboxNotifier struct {
+	store model.NotificationRepository
+}
+
+// NewInboxNotifier 创建站内消息渠道
+func NewInboxNotifier(store model.NotificationRepository) *InboxNotifier {
+	return &InboxNotifier{store: store}
+}
+
+// Name 渠道名称
+func (n *InboxNotifier) Name() string {
+	return "inbox"
+}
+
+// Notify 为每个系统用户写入一条消息，不是系统用户的接收人跳过
+func (n *InboxNotifier) Notify(ctx context.Context, msg Message, recipients []Recipient) error {
+	for _, r := range recipients {
+		if r.UserID == 0 {
+			continue
+		}
+		notification := &model.Notification{UserID: r.UserID, Kind: msg.Kind, Title: msg.Title, Body: msg.Body, AssetID: msg.AssetID}
+		if err := n.store.Create(ctx, notification); err != nil {
+			return fmt.Errorf("写入用户 %d 的站内消息失败: %w", r.UserID, err)
+		}
+	}
+	return nil
+}
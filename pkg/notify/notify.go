@@ -0,0 +1,60 @@
+// Package notify 发送资产到期归还提醒。
+//
+// 提醒通过 Notifier 接口发送，内置邮件（SMTP）、webhook 和站内消息三种渠道；
+// Reminder 定时扫描未归还的资产，按预计归还日期决定提醒谁、何时提醒。
+package notify
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"context"
+	"net/http"
+)
+
+// 提醒类型
+const (
+	// KindDueSoon 即将到期，每个预计归还日期只提醒一次
+	KindDueSoon = "due_soon"
+	// KindOverdue 已逾期，按配置的间隔重复提醒直到归还
+	KindOverdue = "overdue"
+)
+
+// Message 一条提醒
+type Message struct {
+	Kind    string
+	Title   string
+	Body    string
+	AssetID int
+	// Custody 触发提醒的保管记录
+	Custody model.CustodyRecord
+}
+
+// Recipient 提醒的接收人。UserID 为 0 表示保管人不是系统用户，只能通过邮件抄送或 webhook 送达
+type Recipient struct {
+	UserID int    `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+}
+
+// Notifier 提醒渠道
+type Notifier interface {
+	// Name 渠道名称，用于日志
+	Name() string
+	// Notify 把提醒发送给接收人，渠道无法送达任何接收人时直接返回 nil
+	Notify(ctx context.Context, msg Message, recipients []Recipient) error
+}
+
+// FromConfig 按配置创建已启用的提醒渠道
+func FromConfig(cfg config.RemindersConfig, inbox model.NotificationRepository) []Notifier {
+	var notifiers []Notifier
+	if cfg.Inbox {
+		notifiers = append(notifiers, NewInboxNotifier(inbox))
+	}
+	if cfg.SMTP.Addr != "" {
+		notifiers = append(notifiers, NewSMTPNotifier(cfg.SMTP))
+	}
+	if cfg.Webhook.URL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(cfg.Webhook.URL, &http.Client{Timeout: cfg.Webhook.Timeout}))
+	}
+	return notifiers
+}
@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Reminder 到期归还提醒调度器。每次扫描找出未归还且预计归还日期已到或临近的资产，
+// 即将到期的每个日期只提醒一次，逾期的按 OverdueRepeat 间隔重复提醒，直到归还或延期
+type Reminder struct {
+	cfg       config.RemindersConfig
+	assets    model.AssetCustody
+	users     model.UserRepository
+	log       model.ReminderLog
+	notifiers []Notifier
+	// DryRun 为 true 时只打印将要发送的提醒，不发送也不记录
+	DryRun bool
+}
+
+// NewReminder 创建提醒调度器
+func NewReminder(cfg config.RemindersConfig, assets model.AssetCustody, users model.UserRepository, reminderLog model.ReminderLog, notifiers []Notifier) *Reminder {
+	return &Reminder{cfg: cfg, assets: assets, users: users, log: reminderLog, notifiers: notifiers}
+}
+
+// Run 启动后立即扫描一次，之后按配置的间隔扫描，ctx 取消后退出
+func (r *Reminder) Run(ctx context.Context) {
+	if !r.cfg.Enabled || len(r.notifiers) == 0 {
+		log.Println("到期提醒未启用或没有可用的提醒渠道")
+		return
+	}
+	log.Printf("到期提醒已启动，扫描间隔: %s，渠道: %s", r.cfg.Interval, notifierNames(r.notifiers))
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if sent, err := r.RunOnce(ctx, time.Now()); err != nil {
+			log.Printf("到期提醒扫描失败: %v", err)
+		} else if sent > 0 {
+			log.Printf("到期提醒扫描完成，发送 %d 条提醒", sent)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 以 now 为当前时间扫描一次，返回至少通过一个渠道发送（DryRun 时为将要发送）的提醒数量。
+// 提醒按渠道分别记录，某个渠道发送失败只记录日志，下次扫描时只重试该渠道
+func (r *Reminder) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	held, err := r.assets.HeldAssets(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("查询在用资产失败: %w", err)
+	}
+	users, err := r.users.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	today := now.Format("2006-01-02")
+	dueSoonUntil := now.AddDate(0, 0, r.cfg.DueSoonDays).Format("2006-01-02")
+	sent := 0
+	for i := range held {
+		h := &held[i]
+		due := h.Custody.ExpectedReturnDate
+		if due == "" || due > dueSoonUntil {
+			continue
+		}
+		kind := KindDueSoon
+		if due < today {
+			kind = KindOverdue
+		}
+		// 每个渠道单独记录，部分渠道失败时下次扫描只重试失败的渠道，已送达的渠道不会重复打扰
+		var pending []Notifier
+		for _, n := range r.notifiers {
+			last, err := r.log.LastReminder(ctx, h.Custody.ID, kind, due, n.Name())
+			if err != nil {
+				return sent, fmt.Errorf("查询提醒记录失败: %w", err)
+			}
+			if last.IsZero() || (kind == KindOverdue && now.Sub(last) >= r.cfg.OverdueRepeat) {
+				pending = append(pending, n)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		msg := buildMessage(kind, h, now)
+		recipients := r.recipients(h, users)
+		if r.DryRun {
+			log.Printf("[试运行] %s，渠道: %s，接收人: %s", msg.Title, notifierNames(pending), describeRecipients(recipients))
+			sent++
+			continue
+		}
+		delivered := false
+		for _, n := range pending {
+			if err := n.Notify(ctx, msg, recipients); err != nil {
+				log.Printf("通过 %s 发送到期提醒失败: asset_id=%d, %v", n.Name(), msg.AssetID, err)
+				continue
+			}
+			if err := r.log.RecordReminder(ctx, h.Custody.ID, kind, due, n.Name(), now); err != nil {
+				return sent, fmt.Errorf("写入提醒记录失败: %w", err)
+			}
+			delivered = true
+		}
+		if delivered {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func notifierNames(notifiers []Notifier) string {
+	names := make([]string, len(notifiers))
+	for i, n := range notifiers {
+		names[i] = n.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// recipients 返回提醒的接收人：用户名或显示名称与保管人一致的启用用户；
+// 开启 NotifyManagers 时还包括拥有保管权限且部门范围包含该资产的用户。
+// 保管人不是系统用户时保留一个只有名称的接收人，供 webhook 使用
+func (r *Reminder) recipients(h *model.HeldAsset, users []model.User) []Recipient {
+	var recipients []Recipient
+	seen := map[int]bool{}
+	holderFound := false
+	for i := range users {
+		u := &users[i]
+		if !u.IsActive || seen[u.ID] {
+			continue
+		}
+		isHolder := u.Username == h.Custody.Holder || u.DisplayName == h.Custody.Holder
+		if isHolder {
+			holderFound = true
+		}
+		if isHolder || (r.cfg.NotifyManagers && u.Can(model.PermCustody) && model.ScopeFor(u).Allows(&h.Asset)) {
+			seen[u.ID] = true
+			name := u.DisplayName
+			if name == "" {
+				name = u.Username
+			}
+			recipients = append(recipients, Recipient{UserID: u.ID, Name: name, Email: u.Email})
+		}
+	}
+	if !holderFound {
+		recipients = append([]Recipient{{Name: h.Custody.Holder}}, recipients...)
+	}
+	return recipients
+}
+
+// buildMessage 生成提醒的标题和正文
+func buildMessage(kind string, h *model.HeldAsset, now time.Time) Message {
+	due, _ := time.ParseInLocation("2006-01-02", h.Custody.ExpectedReturnDate, now.Location())
+	today, _ := time.ParseInLocation("2006-01-02", now.Format("2006-01-02"), now.Location())
+	days := int(due.Sub(today).Hours() / 24)
+
+	name := h.Name
+	if h.SerialNumber != "" {
+		name += "（" + h.SerialNumber + "）"
+	}
+	var title, remaining string
+	if kind == KindOverdue {
+		title = "资产逾期未归还：" + name
+		remaining = fmt.Sprintf("已逾期 %d 天", -days)
+	} else {
+		title = "资产即将到期归还：" + name
+		remaining = "今天到期"
+		if days > 0 {
+			remaining = fmt.Sprintf("还有 %d 天到期", days)
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("资产：%s", name),
+		fmt.Sprintf("资产编码：%s", h.AssetCode),
+		fmt.Sprintf("保管人：%s（%s）", h.Custody.Holder, h.Custody.HolderDepartment),
+		fmt.Sprintf("领用时间：%s", h.Custody.CheckedOutAt),
+		fmt.Sprintf("预计归还日期：%s，%s", h.Custody.ExpectedReturnDate, remaining),
+	}
+	if h.Custody.CheckoutNote != "" {
+		lines = append(lines, fmt.Sprintf("领用说明：%s", h.Custody.CheckoutNote))
+	}
+	lines = append(lines, "", "请及时归还，或联系资产管理员办理延期。")
+	return Message{Kind: kind, Title: title, Body: strings.Join(lines, "\n"), AssetID: h.ID, Custody: h.Custody}
+}
+
+func describeRecipients(recipients []Recipient) string {
+	parts := make([]string, len(recipients))
+	for i, r := range recipients {
+		parts[i] = r.Name
+		if r.Email != "" {
+			parts[i] += " <" + r.Email + ">"
+		}
+	}
+	return strings.Join(parts, "、")
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"asset-management-system/pkg/config"
+	"asset-management-system/pkg/model"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeCustody struct {
+	model.AssetCustody
+	held []model.HeldAsset
+}
+
+func (f *fakeCustody) HeldAssets(ctx context.Context, holder string) ([]model.HeldAsset, error) {
+	return f.held, nil
+}
+
+type fakeUsers struct {
+	model.UserRepository
+	users []model.User
+}
+
+func (f *fakeUsers) List(ctx context.Context) ([]model.User, error) {
+	return f.users, nil
+}
+
+type reminderKey struct {
+	custodyID          int64
+	kind, due, channel string
+}
+
+type fakeReminderLog struct {
+	sent map[reminderKey]time.Time
+}
+
+func (f *fakeReminderLog) LastReminder(ctx context.Context, custodyID int64, kind, dueDate, channel string) (time.Time, error) {
+	return f.sent[reminderKey{custodyID, kind, dueDate, channel}], nil
+}
+
+func (f *fakeReminderLog) RecordReminder(ctx context.Context, custodyID int64, kind, dueDate, channel string, sentAt time.Time) error {
+	f.sent[reminderKey{custodyID, kind, dueDate, channel}] = sentAt
+	return nil
+}
+
+// fakeNotifier 记录收到的提醒，fail 为 true 时返回错误
+type fakeNotifier struct {
+	name     string
+	fail     bool
+	messages []Message
+}
+
+func (n *fakeNotifier) Name() string { return n.name }
+
+func (n *fakeNotifier) Notify(ctx context.Context, msg Message, recipients []Recipient) error {
+	if n.fail {
+		return errors.New("渠道不可用")
+	}
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func TestReminderRetriesOnlyFailedChannels(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	held := []model.HeldAsset{{
+		Asset:   model.Asset{ID: 1, Name: "笔记本电脑", Department: "IT"},
+		Custody: model.CustodyRecord{ID: 7, Holder: "zhang", ExpectedReturnDate: "2026-03-08"},
+	}}
+	users := []model.User{{ID: 2, Username: "zhang", DisplayName: "张三", Email: "zhang@example.com", IsActive: true}}
+	cfg := config.RemindersConfig{DueSoonDays: 3, OverdueRepeat: 24 * time.Hour}
+	inbox := &fakeNotifier{name: "inbox"}
+	mail := &fakeNotifier{name: "smtp", fail: true}
+	reminderLog := &fakeReminderLog{sent: map[reminderKey]time.Time{}}
+	r := NewReminder(cfg, &fakeCustody{held: held}, &fakeUsers{users: users}, reminderLog, []Notifier{inbox, mail})
+
+	steps := []struct {
+		name      string
+		at        time.Time
+		mailFails bool
+		sent      int
+		inbox     int
+		mail      int
+	}{
+		{"邮件失败时站内消息照常发送", now, true, 1, 1, 0},
+		{"下次扫描只重试邮件", now.Add(time.Hour), false, 1, 1, 1},
+		{"两个渠道都已送达", now.Add(2 * time.Hour), false, 0, 1, 1},
+		{"超过重复间隔后再次提醒", now.Add(25 * time.Hour), false, 1, 2, 2},
+	}
+	for _, step := range steps {
+		mail.fail = step.mailFails
+		sent, err := r.RunOnce(context.Background(), step.at)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if sent != step.sent || len(inbox.messages) != step.inbox || len(mail.messages) != step.mail {
+			t.Errorf("%s: 发送 %d，站内消息 %d，邮件 %d；期望 %d、%d、%d",
+				step.name, sent, len(inbox.messages), len(mail.messages), step.sent, step.inbox, step.mail)
+		}
+	}
+	if msg := inbox.messages[0]; msg.Kind != KindOverdue || !strings.Contains(msg.Body, "已逾期 2 天") {
+		t.Errorf("提醒内容错误: %+v", msg)
+	}
+}
+
+func TestReminderDueSoonOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		due  string
+		kind string
+	}{
+		{"2026-03-10", KindDueSoon},
+		{"2026-03-13", KindDueSoon},
+		{"2026-03-14", ""},
+		{"2026-03-09", KindOverdue},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		held := []model.HeldAsset{{Asset: model.Asset{ID: 1, Name: "显示器"}, Custody: model.CustodyRecord{ID: 1, Holder: "外部人员", ExpectedReturnDate: tt.due}}}
+		n := &fakeNotifier{name: "webhook"}
+		r := NewReminder(config.RemindersConfig{DueSoonDays: 3, OverdueRepeat: time.Hour}, &fakeCustody{held: held}, &fakeUsers{},
+			&fakeReminderLog{sent: map[reminderKey]time.Time{}}, []Notifier{n})
+		for i := 0; i < 2; i++ {
+			if _, err := r.RunOnce(context.Background(), now.Add(time.Duration(i)*2*time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var kinds []string
+		for _, m := range n.messages {
+			kinds = append(kinds, m.Kind)
+		}
+		want := map[string][]string{KindDueSoon: {KindDueSoon}, KindOverdue: {KindOverdue, KindOverdue}}[tt.kind]
+		if strings.Join(kinds, ",") != strings.Join(want, ",") {
+			t.Errorf("预计归还 %q: 提醒 %v，期望 %v", tt.due, kinds, want)
+		}
+	}
+}
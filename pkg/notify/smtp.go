@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"asset-management-system/pkg/config"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier 通过 SMTP 发送提醒邮件。服务器支持 STARTTLS 时自动加密；
+// 未配置用户名时不做认证，便于使用 MailHog 等本地 SMTP 替身调试
+type SMTPNotifier struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTPNotifier 创建邮件渠道
+func NewSMTPNotifier(cfg config.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+// Name 渠道名称
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Notify 给有邮箱的接收人和配置的抄送地址发送一封邮件，没有任何收件地址时跳过
+func (n *SMTPNotifier) Notify(ctx context.Context, msg Message, recipients []Recipient) error {
+	var to []string
+	seen := map[string]bool{}
+	for _, r := range recipients {
+		if r.Email != "" && !seen[strings.ToLower(r.Email)] {
+			seen[strings.ToLower(r.Email)] = true
+			to = append(to, r.Email)
+		}
+	}
+	var cc []string
+	for _, addr := range n.cfg.To {
+		if !seen[strings.ToLower(addr)] {
+			seen[strings.ToLower(addr)] = true
+			cc = append(cc, addr)
+		}
+	}
+	if len(to)+len(cc) == 0 {
+		return nil
+	}
+	return n.send(ctx, append(to, cc...), buildMail(n.cfg.From, to, cc, msg.Title, msg.Body, time.Now()))
+}
+
+// send 建立连接并投递邮件，整个会话受 ctx 和配置的超时限制
+func (n *SMTPNotifier) send(ctx context.Context, rcpt []string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", n.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	host, _, _ := net.SplitHostPort(n.cfg.Addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("STARTTLS 失败: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		// PlainAuth 只允许在 TLS 连接或本机地址上发送密码
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+	if err := client.Mail(envelopeAddress(n.cfg.From)); err != nil {
+		return err
+	}
+	for _, addr := range rcpt {
+		if err := client.Rcpt(envelopeAddress(addr)); err != nil {
+			return fmt.Errorf("收件人 %s 被拒绝: %w", addr, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// envelopeAddress 从“名称 <地址>”格式中取出地址
+func envelopeAddress(addr string) string {
+	if parsed, err := mail.ParseAddress(addr); err == nil {
+		return parsed.Address
+	}
+	return addr
+}
+
+// buildMail 生成 UTF-8 纯文本邮件，主题按 RFC 2047 编码，正文使用 base64
+func buildMail(from string, to, cc []string, subject, body string, date time.Time) []byte {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from)
+	if len(to) > 0 {
+		header("To", strings.Join(to, ", "))
+	}
+	if len(cc) > 0 {
+		header("Cc", strings.Join(cc, ", "))
+	}
+	header("Subject", mime.BEncoding.Encode("UTF-8", subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"asset-management-system/pkg/config"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession 测试 SMTP 服务器收到的一次投递
+type smtpSession struct {
+	from string
+	rcpt []string
+	data string
+}
+
+// startSMTPServer 在本机监听并处理一次不加密、不认证的 SMTP 会话
+func startSMTPServer(t *testing.T) (string, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	done := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var s smtpSession
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 localhost")
+			case "MAIL":
+				s.from = strings.TrimSuffix(strings.TrimPrefix(line[len("MAIL FROM:"):], "<"), ">")
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				s.rcpt = append(s.rcpt, strings.TrimSuffix(strings.TrimPrefix(line[len("RCPT TO:"):], "<"), ">"))
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 继续")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				s.data = string(data)
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				done <- s
+				return
+			default:
+				tp.PrintfLine("502 未实现")
+			}
+		}
+	}()
+	return ln.Addr().String(), done
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, done := startSMTPServer(t)
+	n := NewSMTPNotifier(config.SMTPConfig{
+		Addr:    addr,
+		From:    "资产管理 <ams@example.com>",
+		To:      []string{"admin@example.com", "Zhang@example.com"},
+		Timeout: 5 * time.Second,
+	})
+	msg := Message{Title: "资产逾期未归还：笔记本电脑", Body: "资产：笔记本电脑\n请及时归还。"}
+	recipients := []Recipient{
+		{UserID: 2, Name: "张三", Email: "zhang@example.com"},
+		{Name: "外部人员"},
+	}
+	if err := n.Notify(context.Background(), msg, recipients); err != nil {
+		t.Fatal(err)
+	}
+
+	var s smtpSession
+	select {
+	case s = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP 服务器没有收到邮件")
+	}
+	if s.from != "ams@example.com" {
+		t.Errorf("MAIL FROM 为 %q", s.from)
+	}
+	// 抄送地址与收件人重复时（不区分大小写）只投递一次
+	if strings.Join(s.rcpt, ",") != "zhang@example.com,admin@example.com" {
+		t.Errorf("RCPT TO 为 %v", s.rcpt)
+	}
+	m, err := mail.ReadMessage(strings.NewReader(s.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if subject != msg.Title {
+		t.Errorf("主题为 %q", subject)
+	}
+	if m.Header.Get("To") != "zhang@example.com" || m.Header.Get("Cc") != "admin@example.com" {
+		t.Errorf("收件人 %q，抄送 %q", m.Header.Get("To"), m.Header.Get("Cc"))
+	}
+	encoded, err := io.ReadAll(m.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "资产：笔记本电脑\r\n请及时归还。" {
+		t.Errorf("正文为 %q", body)
+	}
+}
+
+func TestSMTPNotifierSkipsWithoutAddress(t *testing.T) {
+	// 地址无法连接，没有收件人时不应尝试发送
+	n := NewSMTPNotifier(config.SMTPConfig{Addr: "127.0.0.1:1", From: "ams@example.com", Timeout: time.Second})
+	if err := n.Notify(context.Background(), Message{Title: "t"}, []Recipient{{Name: "外部人员"}}); err != nil {
+		t.Errorf("没有收件地址时应跳过: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookPayload webhook 请求体
+type webhookPayload struct {
+	Kind               string      `json:"kind"`
+	Title              string      `json:"title"`
+	Body               string      `json:"body"`
+	AssetID            int         `json:"asset_id"`
+	Holder             string      `json:"holder"`
+	HolderDepartment   string      `json:"holder_department"`
+	ExpectedReturnDate string      `json:"expected_return_date"`
+	Recipients         []Recipient `json:"recipients"`
+	SentAt             string      `json:"sent_at"`
+}
+
+// WebhookNotifier 把提醒以 JSON POST 到指定地址，可对接企业微信、钉钉等机器人的转发服务
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建 webhook 渠道，client 的超时决定单次调用的最长时间
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: client}
+}
+
+// Name 渠道名称
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify 发送一次请求，响应状态码不是 2xx 时返回错误
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message, recipients []Recipient) error {
+	payload := webhookPayload{
+		Kind:               msg.Kind,
+		Title:              msg.Title,
+		Body:               msg.Body,
+		AssetID:            msg.AssetID,
+		Holder:             msg.Custody.Holder,
+		HolderDepartment:   msg.Custody.HolderDepartment,
+		ExpectedReturnDate: msg.Custody.ExpectedReturnDate,
+		Recipients:         recipients,
+		SentAt:             time.Now().Format(time.RFC3339),
+	}
+	if payload.Recipients == nil {
+		payload.Recipients = []Recipient{}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook 返回 %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
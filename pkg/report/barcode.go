@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/go-pdf/fpdf"
+)
+
+// Symbology 条码类型
+type Symbology string
+
+const (
+	// QR 二维码，可以编码中文和链接，适合手机扫描
+	QR Symbology = "qr"
+	// Code128 一维条码，只能编码 ASCII 字符，适合扫码枪
+	Code128 Symbology = "code128"
+)
+
+// Valid 判断是否为已定义的条码类型
+func (s Symbology) Valid() bool {
+	return s == QR || s == Code128
+}
+
+// Encode 把内容编码为条码
+func (s Symbology) Encode(content string) (barcode.Barcode, error) {
+	if content == "" {
+		return nil, fmt.Errorf("条码内容为空")
+	}
+	switch s {
+	case QR:
+		return qr.Encode(content, qr.M, qr.Auto)
+	case Code128:
+		for _, r := range content {
+			if r < 0x20 || r > 0x7e {
+				return nil, fmt.Errorf("Code128 条码只能包含 ASCII 字符: %q", content)
+			}
+		}
+		return code128.Encode(content)
+	}
+	return nil, fmt.Errorf("不支持的条码类型: %s", s)
+}
+
+// WritePNG 把内容编码为条码并输出 PNG。二维码为 size×size 像素，一维条码宽 size、高 size/3；
+// size 小于条码的模块数时使用模块数，保证每个模块至少一个像素
+func (s Symbology) WritePNG(w io.Writer, content string, size int) error {
+	code, err := s.Encode(content)
+	if err != nil {
+		return err
+	}
+	width, height := size, size
+	if s == Code128 {
+		height = size / 3
+	}
+	bounds := code.Bounds()
+	if width < bounds.Dx() {
+		width = bounds.Dx()
+	}
+	if height < bounds.Dy() {
+		height = bounds.Dy()
+	}
+	scaled, err := barcode.Scale(code, width, height)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, scaled)
+}
+
+// drawBarcode 以矢量矩形在 (x, y) 处绘制 width×height 毫米的条码，打印时边缘清晰。
+// 同一行相邻的深色模块合并为一个矩形
+func drawBarcode(pdf *fpdf.Fpdf, code barcode.Barcode, x, y, width, height float64) {
+	bounds := code.Bounds()
+	cols, rows := bounds.Dx(), bounds.Dy()
+	moduleWidth := width / float64(cols)
+	moduleHeight := height / float64(rows)
+	// 一维条码每列只有一个模块高度，整列绘制
+	if code.Metadata().Dimensions == 1 {
+		rows, moduleHeight = 1, height
+	}
+	pdf.SetFillColor(0, 0, 0)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; {
+			if !dark(code, bounds.Min.X+col, bounds.Min.Y+row) {
+				col++
+				continue
+			}
+			start := col
+			for col < cols && dark(code, bounds.Min.X+col, bounds.Min.Y+row) {
+				col++
+			}
+			pdf.Rect(x+float64(start)*moduleWidth, y+float64(row)*moduleHeight, float64(col-start)*moduleWidth, moduleHeight, "F")
+		}
+	}
+}
+
+// dark 判断条码在 (x, y) 处的模块是否为深色
+func dark(code barcode.Barcode, x, y int) bool {
+	r, _, _, _ := code.At(x, y).RGBA()
+	return r < 0x8000
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestSymbologyValid(t *testing.T) {
+	for _, s := range []Symbology{QR, Code128} {
+		if !s.Valid() {
+			t.Errorf("%s 应该有效", s)
+		}
+	}
+	for _, s := range []Symbology{"", "ean13", "QR"} {
+		if s.Valid() {
+			t.Errorf("%q 不应该有效", s)
+		}
+	}
+}
+
+func TestSymbologyEncode(t *testing.T) {
+	tests := []struct {
+		s       Symbology
+		content string
+		ok      bool
+	}{
+		{QR, "ZC-0001", true},
+		{QR, "投影仪 ZC-0001", true},
+		{Code128, "ZC-0001", true},
+		{Code128, "投影仪", false},
+		{Code128, "ZC\t0001", false},
+		{QR, "", false},
+		{Code128, "", false},
+		{"ean13", "123", false},
+	}
+	for _, tt := range tests {
+		code, err := tt.s.Encode(tt.content)
+		if tt.ok != (err == nil) {
+			t.Errorf("%s %q: 错误 %v", tt.s, tt.content, err)
+			continue
+		}
+		if err == nil && code.Content() != tt.content {
+			t.Errorf("%s %q: 编码内容为 %q", tt.s, tt.content, code.Content())
+		}
+	}
+}
+
+func TestSymbologyWritePNG(t *testing.T) {
+	tests := []struct {
+		s             Symbology
+		size          int
+		width, height int
+	}{
+		{QR, 200, 200, 200},
+		{Code128, 300, 300, 100},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := tt.s.WritePNG(&buf, "ZC-0001", tt.size); err != nil {
+			t.Fatalf("%s: %v", tt.s, err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.s, err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("%s: 图片 %d×%d，期望 %d×%d", tt.s, b.Dx(), b.Dy(), tt.width, tt.height)
+		}
+	}
+
+	// size 小于模块数时按模块数输出，每个模块至少一个像素
+	code, err := QR.Encode("ZC-0001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := QR.WritePNG(&buf, "ZC-0001", 1); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds().Dx(), code.Bounds().Dx(); got != want {
+		t.Errorf("size=1 时图片宽 %d，期望 %d", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/boombuler/barcode"
+	"github.com/go-pdf/fpdf"
+)
+
+// LabelLayout 标签纸规格，尺寸单位为毫米。Left、Top 为第一张标签到页面左边和上边的距离，
+// GapX、GapY 为相邻标签之间的间隔
+type LabelLayout struct {
+	Name       string  `json:"name"`
+	Title      string  `json:"title"`
+	PageWidth  float64 `json:"page_width"`
+	PageHeight float64 `json:"page_height"`
+	Columns    int     `json:"columns"`
+	Rows       int     `json:"rows"`
+	Width      float64 `json:"width"`
+	Height     float64 `json:"height"`
+	Left       float64 `json:"left"`
+	Top        float64 `json:"top"`
+	GapX       float64 `json:"gap_x"`
+	GapY       float64 `json:"gap_y"`
+}
+
+// PerPage 每页的标签数
+func (l LabelLayout) PerPage() int {
+	return l.Columns * l.Rows
+}
+
+// labelLayouts 支持的标签纸：常见的 A4 不干胶标签纸和标签打印机的卷纸，第一个为默认规格
+var labelLayouts = []LabelLayout{
+	{Name: "a4-3x8", Title: "A4 3×8（70×37mm，每页 24 张）", PageWidth: 210, PageHeight: 297, Columns: 3, Rows: 8, Width: 70, Height: 37, Top: 0.5},
+	{Name: "a4-4x10", Title: "A4 4×10（52.5×29.7mm，每页 40 张）", PageWidth: 210, PageHeight: 297, Columns: 4, Rows: 10, Width: 52.5, Height: 29.7},
+	{Name: "a4-5x13", Title: "A4 5×13（38.1×21.2mm，每页 65 张）", PageWidth: 210, PageHeight: 297, Columns: 5, Rows: 13, Width: 38.1, Height: 21.2, Left: 4.75, Top: 10.7, GapX: 2.5},
+	{Name: "roll-50x30", Title: "标签打印机 50×30mm", PageWidth: 50, PageHeight: 30, Columns: 1, Rows: 1, Width: 50, Height: 30},
+	{Name: "roll-60x40", Title: "标签打印机 60×40mm", PageWidth: 60, PageHeight: 40, Columns: 1, Rows: 1, Width: 60, Height: 40},
+}
+
+// LabelLayouts 返回支持的标签纸规格，第一个为默认规格
+func LabelLayouts() []LabelLayout {
+	return labelLayouts
+}
+
+// FindLabelLayout 按名称查找标签纸规格
+func FindLabelLayout(name string) (LabelLayout, bool) {
+	for _, l := range labelLayouts {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	return LabelLayout{}, false
+}
+
+// Label 一张标签：条码内容和条码旁边的文字，Lines 的第一行为标题（如资产名称）
+type Label struct {
+	Content string
+	Lines   []string
+}
+
+// 标签的尺寸（毫米）：内边距、文字行高与字号之比，以及文字的最大、最小字号
+const (
+	labelPadding     = 1.5
+	labelLineSpacing = 1.25
+	labelMaxFont     = 3.2
+	labelMinFont     = 1.8
+)
+
+// Labels 按标签纸规格排版并输出 PDF。skip 为整页标签纸上已用掉的张数，从第 skip+1 个位置开始打印
+func (g *Generator) Labels(w io.Writer, layout LabelLayout, symbology Symbology, labels []Label, skip int) error {
+	codes := make([]barcode.Barcode, len(labels))
+	for i, label := range labels {
+		code, err := symbology.Encode(label.Content)
+		if err != nil {
+			return fmt.Errorf("第 %d 张标签: %w", i+1, err)
+		}
+		codes[i] = code
+	}
+	if skip < 0 || skip >= layout.PerPage() {
+		skip = 0
+	}
+
+	pdf := g.newPDF(&fpdf.InitType{OrientationStr: "P", Size: fpdf.SizeType{Wd: layout.PageWidth, Ht: layout.PageHeight}}, "资产标签")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetCellMargin(0)
+	pdf.SetAutoPageBreak(false, 0)
+	for i, label := range labels {
+		slot := (i + skip) % layout.PerPage()
+		if i == 0 || slot == 0 {
+			pdf.AddPage()
+		}
+		x := layout.Left + float64(slot%layout.Columns)*(layout.Width+layout.GapX)
+		y := layout.Top + float64(slot/layout.Columns)*(layout.Height+layout.GapY)
+		if symbology == QR {
+			drawQRLabel(pdf, codes[i], label, x, y, layout.Width, layout.Height)
+		} else {
+			drawLinearLabel(pdf, codes[i], label, x, y, layout.Width, layout.Height)
+		}
+	}
+	return pdf.Output(w)
+}
+
+// drawQRLabel 二维码在左侧占满标签高度，文字在右侧垂直居中，标题最多两行
+func drawQRLabel(pdf *fpdf.Fpdf, code barcode.Barcode, label Label, x, y, width, height float64) {
+	side := height - 2*labelPadding
+	drawBarcode(pdf, code, x+labelPadding, y+labelPadding, side, side)
+
+	textX := x + side + 2.5*labelPadding
+	textWidth := x + width - labelPadding - textX
+	if textWidth < 5 || len(label.Lines) == 0 {
+		return
+	}
+	// 按四行文字估算字号：标题两行，其余各一行；窄标签每行至少放下六个汉字
+	size := clamp(math.Min(side/4/labelLineSpacing, textWidth/6), labelMinFont, labelMaxFont)
+	pdf.SetFontUnitSize(size)
+	lineHeight := size * labelLineSpacing
+
+	var details []string
+	for _, v := range label.Lines[1:] {
+		if v != "" {
+			details = append(details, v)
+		}
+	}
+	maxLines := int(side / lineHeight)
+	if len(details) > maxLines-1 {
+		details = details[:maxLines-1]
+	}
+	titleLines := int(math.Min(2, float64(maxLines-len(details))))
+	title := wrapText(pdf, label.Lines[0], textWidth)
+	if len(title) > titleLines {
+		title = append(title[:titleLines-1], fit(pdf, title[titleLines-1]+title[titleLines], textWidth))
+	}
+
+	top := y + (height-float64(len(title)+len(details))*lineHeight)/2
+	for i, line := range title {
+		pdf.SetXY(textX, top+float64(i)*lineHeight)
+		pdf.CellFormat(textWidth, lineHeight, line, "", 0, "L", false, 0, "")
+	}
+	top += float64(len(title)) * lineHeight
+	for i, v := range details {
+		pdf.SetXY(textX, top+float64(i)*lineHeight)
+		pdf.CellFormat(textWidth, lineHeight, shrinkToFit(pdf, v, textWidth, size), "", 0, "L", false, 0, "")
+		pdf.SetFontUnitSize(size)
+	}
+}
+
+// drawLinearLabel 标题在上、一维条码居中、条码内容在下，条码两侧保留 10 个模块宽的空白区
+func drawLinearLabel(pdf *fpdf.Fpdf, code barcode.Barcode, label Label, x, y, width, height float64) {
+	size := clamp(height/6/labelLineSpacing, labelMinFont, labelMaxFont)
+	pdf.SetFontUnitSize(size)
+	lineHeight := size * labelLineSpacing
+	inner := width - 2*labelPadding
+
+	top := y + labelPadding
+	if len(label.Lines) > 0 {
+		pdf.SetXY(x+labelPadding, top)
+		pdf.CellFormat(inner, lineHeight, fit(pdf, label.Lines[0], inner), "", 0, "C", false, 0, "")
+		top += lineHeight
+	}
+	bottom := y + height - labelPadding - lineHeight
+	module := inner / float64(code.Bounds().Dx()+20)
+	drawBarcode(pdf, code, x+labelPadding+10*module, top+0.5, module*float64(code.Bounds().Dx()), bottom-top-1)
+	pdf.SetXY(x+labelPadding, bottom)
+	pdf.CellFormat(inner, lineHeight, shrinkToFit(pdf, label.Content, inner, size), "", 0, "C", false, 0, "")
+}
+
+// shrinkToFit 编码、序列号等不宜截断的文字先缩小字号（不小于最小字号）以放下全文，仍放不下时截断。
+// 会修改当前字号，调用方需要时自行恢复
+func shrinkToFit(pdf *fpdf.Fpdf, text string, width, size float64) string {
+	if w := pdf.GetStringWidth(text); w > width {
+		pdf.SetFontUnitSize(math.Max(labelMinFont, size*width/w))
+	}
+	return fit(pdf, text, width)
+}
+
+// clamp 把 v 限制在 [lo, hi] 范围内
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
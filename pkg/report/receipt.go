@@ -0,0 +1,178 @@
+package report
+
+import (
+	"asset-management-system/pkg/model"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ReceiptKind 单据类型
+type ReceiptKind string
+
+const (
+	// Handover 领用交接单，领用人签收资产
+	Handover ReceiptKind = "handover"
+	// Return 归还单，接收人确认资产已归还及归还状况
+	Return ReceiptKind = "return"
+)
+
+// Valid 判断是否为已定义的单据类型
+func (k ReceiptKind) Valid() bool {
+	return k == Handover || k == Return
+}
+
+// Title 单据标题
+func (k ReceiptKind) Title() string {
+	if k == Return {
+		return "资产归还单"
+	}
+	return "资产领用交接单"
+}
+
+// Number 单据编号，由类型前缀和保管记录 ID 组成，重复打印时编号不变
+func (k ReceiptKind) Number(custodyID int64) string {
+	prefix := "LY"
+	if k == Return {
+		prefix = "GH"
+	}
+	return fmt.Sprintf("%s-%06d", prefix, custodyID)
+}
+
+// 单据表格的尺寸（毫米）：标签列宽、行高
+const (
+	receiptLabelWidth = 28
+	receiptLineHeight = 7
+)
+
+// Receipt 生成一次领用的交接单或归还单：资产信息、保管信息、确认声明和签字栏。
+// 归还单要求保管记录已归还
+func (g *Generator) Receipt(w io.Writer, kind ReceiptKind, asset *model.Asset, record *model.CustodyRecord, printedAt time.Time) error {
+	if kind == Return && record.Open() {
+		return fmt.Errorf("资产尚未归还，不能生成归还单")
+	}
+	pdf := g.newDocument("P", kind.Title())
+	pdf.AddPage()
+
+	if g.organization != "" {
+		pdf.SetFont(fontFamily, "", 12)
+		pdf.CellFormat(0, 7, g.organization, "", 1, "C", false, 0, "")
+	}
+	pdf.SetFont(fontFamily, "", 18)
+	pdf.CellFormat(0, 12, kind.Title(), "", 1, "C", false, 0, "")
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.CellFormat(90, 6, "单据编号："+kind.Number(record.ID), "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "打印时间："+printedAt.Format("2006-01-02 15:04"), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	receiptSection(pdf, "资产信息", [][2]string{
+		{"资产名称", asset.Name}, {"资产编码", asset.AssetCode},
+		{"序列号", asset.SerialNumber}, {"设备类型", asset.Category},
+		{"品牌", asset.Brand}, {"设备规格", asset.Specification},
+		{"所在部门", asset.Department}, {"所在地", asset.Location},
+	})
+	receiptSection(pdf, "领用信息", [][2]string{
+		{"领用人", record.Holder}, {"领用部门", record.HolderDepartment},
+		{"领用时间", formatTime(record.CheckedOutAt)}, {"预计归还日期", record.ExpectedReturnDate},
+		{"经办人", record.CheckedOutByName}, {"领用说明", record.CheckoutNote},
+	})
+	if kind == Return {
+		receiptSection(pdf, "归还信息", [][2]string{
+			{"归还时间", formatTime(record.CheckedInAt)}, {"归还状况", record.Condition.Label()},
+			{"经办人", record.CheckedInByName}, {"归还说明", record.CheckinNote},
+		})
+	}
+
+	statement := "领用人确认已收到上述资产，外观及配件完好。领用期间妥善保管、仅用于工作，不得转借他人；" +
+		"离职、调岗或到期时应按时归还，因保管不当造成的丢失或损坏按公司规定处理。"
+	signers := [2]string{"领用人", "经办人"}
+	if kind == Return {
+		statement = "双方确认上述资产已归还，归还状况如上所列。资产自签字之日起不再由原领用人保管。"
+		signers = [2]string{"归还人", "接收人"}
+	}
+	pdf.Ln(4)
+	pdf.SetFont(fontFamily, "", 10)
+	for _, line := range wrapText(pdf, statement, 180) {
+		pdf.CellFormat(0, 6, line, "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(16)
+	for _, signer := range signers {
+		pdf.CellFormat(30, 8, signer+"（签字）：", "", 0, "L", false, 0, "")
+		x, y := pdf.GetX(), pdf.GetY()
+		pdf.Line(x, y+7, x+55, y+7)
+		pdf.SetX(x + 65)
+		pdf.CellFormat(15, 8, "日期：", "", 0, "L", false, 0, "")
+		x = pdf.GetX()
+		pdf.Line(x, y+7, x+45, y+7)
+		pdf.Ln(18)
+	}
+	return pdf.Output(w)
+}
+
+// receiptSection 输出带标题的两栏表格，每行两组“标签：值”，过长的值截断；说明类字段独占一行并折行
+func receiptSection(pdf *fpdf.Fpdf, title string, fields [][2]string) {
+	pdf.SetFont(fontFamily, "", 11)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(0, 8, title, "1", 1, "L", true, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	width := pageWidth - left - right
+	half := width / 2
+	for i := 0; i < len(fields); i++ {
+		label, value := fields[i][0], orDash(fields[i][1])
+		if isNote(label) {
+			receiptRow(pdf, label, value, width-receiptLabelWidth)
+			continue
+		}
+		pdf.SetFillColor(248, 248, 248)
+		pdf.CellFormat(receiptLabelWidth, receiptLineHeight, label, "1", 0, "L", true, 0, "")
+		pdf.CellFormat(half-receiptLabelWidth, receiptLineHeight, fit(pdf, value, half-receiptLabelWidth-2), "1", 0, "L", false, 0, "")
+		if i+1 < len(fields) && !isNote(fields[i+1][0]) {
+			i++
+			label, value = fields[i][0], orDash(fields[i][1])
+			pdf.CellFormat(receiptLabelWidth, receiptLineHeight, label, "1", 0, "L", true, 0, "")
+			pdf.CellFormat(half-receiptLabelWidth, receiptLineHeight, fit(pdf, value, half-receiptLabelWidth-2), "1", 1, "L", false, 0, "")
+		} else {
+			pdf.CellFormat(0, receiptLineHeight, "", "1", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(3)
+}
+
+// receiptRow 输出独占一行的字段，值按宽度折行，行高随内容增加
+func receiptRow(pdf *fpdf.Fpdf, label, value string, valueWidth float64) {
+	lines := wrapText(pdf, value, valueWidth-2)
+	height := float64(len(lines)) * receiptLineHeight
+	x, y := pdf.GetX(), pdf.GetY()
+	pdf.SetFillColor(248, 248, 248)
+	pdf.Rect(x, y, receiptLabelWidth, height, "DF")
+	pdf.Rect(x+receiptLabelWidth, y, valueWidth, height, "D")
+	pdf.CellFormat(receiptLabelWidth, receiptLineHeight, label, "", 0, "L", false, 0, "")
+	for i, line := range lines {
+		pdf.SetXY(x+receiptLabelWidth, y+float64(i)*receiptLineHeight)
+		pdf.CellFormat(valueWidth, receiptLineHeight, line, "", 0, "L", false, 0, "")
+	}
+	pdf.SetXY(x, y+height)
+}
+
+// isNote 判断是否为说明类字段
+func isNote(label string) bool {
+	return label == "领用说明" || label == "归还说明"
+}
+
+// fit 截断超出宽度的文本，末尾加省略号
+func fit(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
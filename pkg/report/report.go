@@ -0,0 +1,145 @@
+// Package report 生成 PDF 单据和报表：资产领用交接单、归还单、资产清单以及条码标签。
+//
+// PDF 由纯 Go 的 fpdf 生成。中文字体从 TrueType 文件加载，只嵌入实际用到的字形，
+// 生成的文件在没有安装中文字体的电脑上也能正确显示和打印。
+package report
+
+import (
+	"asset-management-system/pkg/config"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ErrNoFont 表示没有配置中文字体，且在常见的系统字体位置也没有找到
+var ErrNoFont = errors.New("未找到可用的中文字体，请在配置中设置 reports.font_path")
+
+// fontCandidates 未配置字体时依次尝试的中文 TrueType 字体
+var fontCandidates = []string{
+	"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf",
+	"/usr/share/fonts/truetype/arphic-gbsn00lp/gbsn00lp.ttf",
+	"/usr/share/fonts/truetype/arphic-gkai00mp/gkai00mp.ttf",
+	"/Library/Fonts/Arial Unicode.ttf",
+	"/System/Library/Fonts/Supplemental/Arial Unicode.ttf",
+	`C:\Windows\Fonts\simhei.ttf`,
+}
+
+// fontFamily 文档中注册中文字体使用的名称
+const fontFamily = "cjk"
+
+// Generator 使用同一份中文字体生成各类 PDF，可并发使用
+type Generator struct {
+	font         []byte
+	fontPath     string
+	organization string
+}
+
+// New 加载中文字体。cfg.FontPath 为空时在常见的系统字体位置查找，都找不到时返回 ErrNoFont
+func New(cfg config.ReportsConfig) (*Generator, error) {
+	paths := fontCandidates
+	if cfg.FontPath != "" {
+		paths = []string{cfg.FontPath}
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && cfg.FontPath == "" {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取字体 %s 失败: %w", path, err)
+		}
+		g := &Generator{font: data, fontPath: path, organization: cfg.Organization}
+		// 先生成一次空文档，确认字体能被解析
+		if err := g.newDocument("P", "").Error(); err != nil {
+			return nil, fmt.Errorf("字体 %s 无法使用（需要 TrueType 轮廓的 .ttf 字体）: %w", path, err)
+		}
+		return g, nil
+	}
+	return nil, ErrNoFont
+}
+
+// FontPath 返回实际使用的字体文件
+func (g *Generator) FontPath() string {
+	return g.fontPath
+}
+
+// newPDF 创建以毫米为单位的文档并注册中文字体
+func (g *Generator) newPDF(init *fpdf.InitType, title string) *fpdf.Fpdf {
+	init.UnitStr = "mm"
+	pdf := fpdf.NewCustom(init)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", g.font)
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("资产管理系统", true)
+	return pdf
+}
+
+// newDocument 创建 A4 文档，orientation 为 P（纵向）或 L（横向），页脚显示页码
+func (g *Generator) newDocument(orientation, title string) *fpdf.Fpdf {
+	pdf := g.newPDF(&fpdf.InitType{OrientationStr: orientation, SizeStr: "A4"}, title)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 18)
+	pdf.AliasNbPages("{nb}")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, fmt.Sprintf("第 %d 页 / 共 {nb} 页", pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	return pdf
+}
+
+// wrapText 按宽度 width（毫米）把文本折成多行，保留原有换行。
+// 中文可以在任意字符处断行，英文和数字尽量在空格处断行
+func wrapText(pdf *fpdf.Fpdf, text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		line, space := "", -1
+		for _, r := range paragraph {
+			candidate := line + string(r)
+			if line != "" && pdf.GetStringWidth(candidate) > width {
+				if r != ' ' && space > 0 {
+					// 在最后一个空格处断开，空格之后的部分移到下一行
+					lines = append(lines, line[:space])
+					line = line[space+1:] + string(r)
+				} else {
+					lines = append(lines, line)
+					line = strings.TrimLeft(string(r), " ")
+				}
+				space = -1
+				continue
+			}
+			if r == ' ' {
+				space = len(line)
+			} else if r >= utf8.RuneSelf {
+				// 中文字符之间可以断行，之前的空格不再作为断点
+				space = -1
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatTime 把数据库中的时间（YYYY-MM-DD HH:MM:SS）截到分钟
+func formatTime(v string) string {
+	if t, err := time.Parse("2006-01-02 15:04:05", v); err == nil {
+		return t.Format("2006-01-02 15:04")
+	}
+	return v
+}
+
+// orDash 空值显示为短横线，避免单据上出现空白格
+func orDash(v string) string {
+	if strings.TrimSpace(v) == "" {
+		return "—"
+	}
+	return v
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-pdf/fpdf"
+)
+
+func TestWrapText(t *testing.T) {
+	// Courier 是等宽的内置字体，按字节计算宽度：一个中文字符（UTF-8 三个字节）占三个英文字符的宽度
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Courier", "", 10)
+	width := pdf.GetStringWidth(strings.Repeat("x", 10)) + 0.01
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"空文本", "", []string{""}},
+		{"不需要折行", "hello", []string{"hello"}},
+		{"在空格处断行", "hello world foo", []string{"hello", "world foo"}},
+		{"断行处的空格被去掉", "abcdefghij klm", []string{"abcdefghij", "klm"}},
+		{"没有空格时按宽度截断", "abcdefghijklmno", []string{"abcdefghij", "klmno"}},
+		{"保留原有换行", "a\r\n\nb", []string{"a", "", "b"}},
+		{"中文在任意字符处断行", "资产管理系统", []string{"资产管", "理系统"}},
+		{"中文之后不再回退到空格", "ab 资产管理", []string{"ab 资产", "管理"}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(pdf, tt.text, width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %q，期望 %q", tt.name, got, tt.want)
+		}
+	}
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"2024-03-05 14:07:59", "2024-03-05 14:07"},
+		{"2024-03-05", "2024-03-05"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := formatTime(tt.in); got != tt.want {
+			t.Errorf("formatTime(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOrDash(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "—"},
+		{"  ", "—"},
+		{"张三", "张三"},
+	}
+	for _, tt := range tests {
+		if got := orDash(tt.in); got != tt.want {
+			t.Errorf("orDash(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Column 表格报表的一列，Weight 为相对宽度，各列按比例分配页面宽度
+type Column struct {
+	Label  string
+	Weight float64
+}
+
+// 表格的尺寸（毫米）：行高、单元格内边距、为页脚保留的底部空白，以及单元格最多显示的行数
+const (
+	tableLineHeight   = 5
+	tableCellMargin   = 1
+	tableBottomMargin = 18
+	tableMaxLines     = 6
+)
+
+// Table 横向 A4 的表格报表，逐行写入，表头在每页顶部重复
+type Table struct {
+	pdf    *fpdf.Fpdf
+	labels []string
+	widths []float64
+	rows   int
+}
+
+// NewTable 创建表格报表，title 为标题，subtitle 为标题下方的说明（如筛选条件），可为空
+func (g *Generator) NewTable(title, subtitle string, columns []Column) *Table {
+	pdf := g.newDocument("L", title)
+	// 由 row 判断换页，保证一行不会被拆到两页
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	if g.organization != "" {
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.CellFormat(0, 6, g.organization, "", 1, "C", false, 0, "")
+	}
+	pdf.SetFont(fontFamily, "", 16)
+	pdf.CellFormat(0, 10, title, "", 1, "C", false, 0, "")
+	if subtitle != "" {
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.CellFormat(0, 6, subtitle, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(1)
+
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	total := 0.0
+	for _, c := range columns {
+		total += c.Weight
+	}
+	t := &Table{pdf: pdf}
+	for _, c := range columns {
+		t.labels = append(t.labels, c.Label)
+		t.widths = append(t.widths, (pageWidth-left-right)*c.Weight/total)
+	}
+	t.header()
+	return t
+}
+
+// header 输出表头行
+func (t *Table) header() {
+	t.pdf.SetFont(fontFamily, "", 9)
+	t.pdf.SetFillColor(230, 230, 230)
+	t.row(t.labels, true)
+	t.pdf.SetFont(fontFamily, "", 8)
+}
+
+// Row 追加一行，值的数量应与列数一致。剩余空间放不下时换页并重复表头
+func (t *Table) Row(values []string) error {
+	t.rows++
+	t.row(values, false)
+	return t.pdf.Error()
+}
+
+func (t *Table) row(values []string, fill bool) {
+	cells := make([][]string, len(t.widths))
+	lines := 1
+	for i, width := range t.widths {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		cells[i] = wrapText(t.pdf, v, width-2*tableCellMargin)
+		if len(cells[i]) > tableMaxLines {
+			cells[i] = append(cells[i][:tableMaxLines-1], cells[i][tableMaxLines-1]+"…")
+		}
+		if len(cells[i]) > lines {
+			lines = len(cells[i])
+		}
+	}
+	height := float64(lines) * tableLineHeight
+
+	_, pageHeight := t.pdf.GetPageSize()
+	if !fill && t.pdf.GetY()+height > pageHeight-tableBottomMargin {
+		t.pdf.AddPage()
+		t.header()
+	}
+
+	x, y := t.pdf.GetX(), t.pdf.GetY()
+	style := "D"
+	if fill {
+		style = "DF"
+	}
+	for i, width := range t.widths {
+		t.pdf.Rect(x, y, width, height, style)
+		for n, line := range cells[i] {
+			t.pdf.SetXY(x+tableCellMargin, y+float64(n)*tableLineHeight)
+			t.pdf.CellFormat(width-2*tableCellMargin, tableLineHeight, line, "", 0, "L", false, 0, "")
+		}
+		x += width
+	}
+	left, _, _, _ := t.pdf.GetMargins()
+	t.pdf.SetXY(left, y+height)
+}
+
+// Write 在表格末尾写出合计行数并输出 PDF
+func (t *Table) Write(w io.Writer) error {
+	t.pdf.Ln(2)
+	t.pdf.SetFont(fontFamily, "", 9)
+	t.pdf.CellFormat(0, 6, fmt.Sprintf("共 %d 条", t.rows), "", 1, "R", false, 0, "")
+	return t.pdf.Output(w)
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore 进程内会话存储，重启后会话失效，仅适合单实例部署
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// NewMemoryStore 创建内存会话存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]Session{}}
+}
+
+// Get 按 ID 获取会话
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &sess, nil
+}
+
+// Save 保存会话
+func (s *MemoryStore) Save(ctx context.Context, sess *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sess.ID] = *sess
+	return nil
+}
+
+// Delete 删除会话
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// DeleteByUser 删除用户的全部会话
+func (s *MemoryStore) DeleteByUser(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sess := range s.sessions {
+		if sess.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+// DeleteExpired 删除已过期或空闲超时的会话
+func (s *MemoryStore) DeleteExpired(ctx context.Context, now time.Time, idleTimeout time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sess := range s.sessions {
+		if now.After(sess.ExpiresAt) || now.Sub(sess.LastSeenAt) > idleTimeout {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
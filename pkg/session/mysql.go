@@ -0,0 +1,83 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MySQL 驱动未开启 parseTime 时 DATETIME 以字符串返回，统一按 UTC 存取
+const mysqlTimeLayout = "2006-01-02 15:04:05"
+
+// MySQLStore 基于 sessions 表的会话存储，支持多实例共享
+type MySQLStore struct {
+	db *sql.DB
+}
+
+// NewMySQLStore 创建 MySQL 会话存储
+func NewMySQLStore(db *sql.DB) *MySQLStore {
+	return &MySQLStore{db: db}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(mysqlTimeLayout)
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.ParseInLocation(mysqlTimeLayout, value, time.UTC)
+}
+
+// Get 按 ID 获取会话
+func (s *MySQLStore) Get(ctx context.Context, id string) (*Session, error) {
+	var sess Session
+	var createdAt, lastSeenAt, expiresAt string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, created_at, last_seen_at, expires_at
+		FROM sessions WHERE id = ?`, id).
+		Scan(&sess.ID, &sess.UserID, &createdAt, &lastSeenAt, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sess.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if sess.LastSeenAt, err = parseTime(lastSeenAt); err != nil {
+		return nil, err
+	}
+	if sess.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// Save 新建或覆盖会话
+func (s *MySQLStore) Save(ctx context.Context, sess *Session) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE last_seen_at = VALUES(last_seen_at), expires_at = VALUES(expires_at)`,
+		sess.ID, sess.UserID, formatTime(sess.CreatedAt), formatTime(sess.LastSeenAt), formatTime(sess.ExpiresAt))
+	return err
+}
+
+// Delete 删除会话
+func (s *MySQLStore) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+// DeleteByUser 删除用户的全部会话
+func (s *MySQLStore) DeleteByUser(ctx context.Context, userID int) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ?`, userID)
+	return err
+}
+
+// DeleteExpired 删除已过期或空闲超时的会话
+func (s *MySQLStore) DeleteExpired(ctx context.Context, now time.Time, idleTimeout time.Duration) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < ? OR last_seen_at < ?`,
+		formatTime(now), formatTime(now.Add(-idleTimeout)))
+	return err
+}
@@ -0,0 +1,207 @@
+// Package session 实现服务端会话。
+//
+// 会话数据保存在可替换的 Store 中（内存或 MySQL），浏览器 cookie 只携带随机会话 ID
+// 及其 HMAC 签名。会话同时受空闲超时和绝对超时约束，登出时从存储中删除。
+package session
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 表示会话不存在、签名无效或已过期
+var ErrNotFound = errors.New("会话不存在或已过期")
+
+// touchInterval 最近访问时间的最小更新间隔，避免每个请求都写存储
+const touchInterval = time.Minute
+
+// Session 一个登录会话
+type Session struct {
+	ID         string
+	UserID     int
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// ExpiresAt 绝对过期时间，不随访问延长
+	ExpiresAt time.Time
+}
+
+// Store 会话存储接口
+type Store interface {
+	// Get 按 ID 获取会话，不存在时返回 ErrNotFound
+	Get(ctx context.Context, id string) (*Session, error)
+	// Save 新建或覆盖会话
+	Save(ctx context.Context, s *Session) error
+	// Delete 删除会话，不存在时不报错
+	Delete(ctx context.Context, id string) error
+	// DeleteByUser 删除用户的全部会话（修改密码、停用或删除用户时使用）
+	DeleteByUser(ctx context.Context, userID int) error
+	// DeleteExpired 删除在 now 之前已过期的会话
+	DeleteExpired(ctx context.Context, now time.Time, idleTimeout time.Duration) error
+}
+
+// Options 会话 cookie 和超时设置
+type Options struct {
+	CookieName      string
+	Secret          []byte
+	Secure          bool
+	SameSite        http.SameSite
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+}
+
+// Manager 负责会话的创建、加载和销毁
+type Manager struct {
+	store Store
+	opts  Options
+	now   func() time.Time
+}
+
+// NewManager 创建会话管理器
+func NewManager(store Store, opts Options) *Manager {
+	return &Manager{store: store, opts: opts, now: time.Now}
+}
+
+// Store 返回底层存储
+func (m *Manager) Store() Store {
+	return m.store
+}
+
+// Create 为用户创建新会话并写入 cookie
+func (m *Manager) Create(ctx context.Context, w http.ResponseWriter, userID int) (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	s := &Session{
+		ID:         id,
+		UserID:     userID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(m.opts.AbsoluteTimeout),
+	}
+	if err := m.store.Save(ctx, s); err != nil {
+		return nil, err
+	}
+	http.SetCookie(w, m.cookie(m.sign(id), s.ExpiresAt))
+	return s, nil
+}
+
+// Load 从请求 cookie 中加载并校验会话，必要时刷新最近访问时间
+func (m *Manager) Load(ctx context.Context, r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(m.opts.CookieName)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	id, ok := m.verify(cookie.Value)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	if now.After(s.ExpiresAt) || now.Sub(s.LastSeenAt) > m.opts.IdleTimeout {
+		if err := m.store.Delete(ctx, s.ID); err != nil {
+			log.Printf("删除过期会话失败: %v", err)
+		}
+		return nil, ErrNotFound
+	}
+	if now.Sub(s.LastSeenAt) > touchInterval {
+		s.LastSeenAt = now
+		if err := m.store.Save(ctx, s); err != nil {
+			log.Printf("更新会话访问时间失败: %v", err)
+		}
+	}
+	return s, nil
+}
+
+// Destroy 删除请求携带的会话并清除 cookie
+func (m *Manager) Destroy(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, m.cookie("", time.Unix(0, 0)))
+	cookie, err := r.Cookie(m.opts.CookieName)
+	if err != nil {
+		return nil
+	}
+	id, ok := m.verify(cookie.Value)
+	if !ok {
+		return nil
+	}
+	return m.store.Delete(ctx, id)
+}
+
+// RunCleanup 定期清理过期会话，ctx 取消后退出
+func (m *Manager) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.store.DeleteExpired(ctx, m.now(), m.opts.IdleTimeout); err != nil {
+				log.Printf("清理过期会话失败: %v", err)
+			}
+		}
+	}
+}
+
+func (m *Manager) cookie(value string, expires time.Time) *http.Cookie {
+	c := &http.Cookie{
+		Name:     m.opts.CookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   m.opts.Secure,
+		SameSite: m.opts.SameSite,
+	}
+	if value == "" {
+		c.MaxAge = -1
+	}
+	return c
+}
+
+// sign 返回 "ID.签名" 形式的 cookie 值
+func (m *Manager) sign(id string) string {
+	return id + "." + m.mac(id)
+}
+
+// verify 校验 cookie 值的签名，返回会话 ID
+func (m *Manager) verify(value string) (string, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i <= 0 {
+		return "", false
+	}
+	id, sig := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(m.mac(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+func (m *Manager) mac(id string) string {
+	h := hmac.New(sha256.New, m.opts.Secret)
+	h.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// newID 生成 256 位随机会话 ID
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
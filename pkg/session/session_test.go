@@ -0,0 +1,314 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// testManager 返回使用内存存储的管理器和可调整的时钟
+func testManager() (*Manager, *MemoryStore, *time.Time) {
+	store := NewMemoryStore()
+	m := NewManager(store, Options{
+		CookieName:      "ams_session",
+		Secret:          testSecret,
+		Secure:          true,
+		SameSite:        http.SameSiteLaxMode,
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 8 * time.Hour,
+	})
+	now := time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	return m, store, &now
+}
+
+// requestWith 返回携带会话 cookie 的请求
+func requestWith(value string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "ams_session", Value: value})
+	return r
+}
+
+func TestCreate(t *testing.T) {
+	m, store, now := testManager()
+	w := httptest.NewRecorder()
+	sess, err := m.Create(context.Background(), w, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.UserID != 7 || !sess.ExpiresAt.Equal(now.Add(8*time.Hour)) {
+		t.Errorf("会话 %+v", sess)
+	}
+	if _, err := store.Get(context.Background(), sess.ID); err != nil {
+		t.Errorf("会话没有保存: %v", err)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("写入 %d 个 cookie", len(cookies))
+	}
+	c := cookies[0]
+	if c.Name != "ams_session" || c.Path != "/" || !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie 属性 %+v", c)
+	}
+	// cookie 只携带会话 ID 和签名
+	if !strings.HasPrefix(c.Value, sess.ID+".") || c.Value == sess.ID+"." {
+		t.Errorf("cookie 值 %q 不是 ID.签名", c.Value)
+	}
+
+	loaded, err := m.Load(context.Background(), requestWith(c.Value))
+	if err != nil || loaded.ID != sess.ID || loaded.UserID != 7 {
+		t.Errorf("加载会话 %+v, %v", loaded, err)
+	}
+}
+
+func TestLoadRejectsInvalidCookie(t *testing.T) {
+	m, _, _ := testManager()
+	w := httptest.NewRecorder()
+	sess, err := m.Create(context.Background(), w, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := w.Result().Cookies()[0].Value
+	sig := signed[len(sess.ID)+1:]
+
+	other := NewManager(NewMemoryStore(), Options{CookieName: "ams_session", Secret: []byte("another-secret-another-secret-00")})
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"没有签名", sess.ID},
+		{"签名为空", sess.ID + "."},
+		{"只有签名", "." + sig},
+		{"篡改签名", sess.ID + "." + strings.Repeat("A", len(sig))},
+		{"篡改 ID", strings.Repeat("0", len(sess.ID)) + "." + sig},
+		{"其他密钥签名", other.sign(sess.ID)},
+		{"空值", ""},
+	}
+	for _, tt := range tests {
+		if s, err := m.Load(context.Background(), requestWith(tt.value)); err != ErrNotFound {
+			t.Errorf("%s: 返回 %+v, %v，期望 ErrNotFound", tt.name, s, err)
+		}
+	}
+	if _, err := m.Load(context.Background(), httptest.NewRequest(http.MethodGet, "/", nil)); err != ErrNotFound {
+		t.Errorf("没有 cookie: %v，期望 ErrNotFound", err)
+	}
+
+	// 签名正确但会话已从存储中删除
+	m.store.Delete(context.Background(), sess.ID)
+	if _, err := m.Load(context.Background(), requestWith(signed)); err != ErrNotFound {
+		t.Errorf("已删除的会话: %v，期望 ErrNotFound", err)
+	}
+}
+
+func TestLoadIdleTimeout(t *testing.T) {
+	m, store, now := testManager()
+	w := httptest.NewRecorder()
+	sess, err := m.Create(context.Background(), w, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := w.Result().Cookies()[0].Value
+	start := *now
+
+	// 间隔不到 touchInterval 时不更新最近访问时间
+	*now = start.Add(30 * time.Second)
+	if _, err := m.Load(context.Background(), requestWith(value)); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := store.Get(context.Background(), sess.ID); !stored.LastSeenAt.Equal(start) {
+		t.Errorf("最近访问时间更新为 %v", stored.LastSeenAt)
+	}
+
+	// 空闲时间内访问会延长空闲超时
+	*now = start.Add(29 * time.Minute)
+	if _, err := m.Load(context.Background(), requestWith(value)); err != nil {
+		t.Fatalf("空闲 29 分钟: %v", err)
+	}
+	if stored, _ := store.Get(context.Background(), sess.ID); !stored.LastSeenAt.Equal(*now) {
+		t.Errorf("最近访问时间为 %v，期望 %v", stored.LastSeenAt, *now)
+	}
+	*now = start.Add(58 * time.Minute)
+	if _, err := m.Load(context.Background(), requestWith(value)); err != nil {
+		t.Fatalf("两次访问间隔 29 分钟: %v", err)
+	}
+
+	// 超过空闲时间后失效，并从存储中删除
+	*now = now.Add(31 * time.Minute)
+	if _, err := m.Load(context.Background(), requestWith(value)); err != ErrNotFound {
+		t.Errorf("空闲 31 分钟: %v，期望 ErrNotFound", err)
+	}
+	if _, err := store.Get(context.Background(), sess.ID); err != ErrNotFound {
+		t.Errorf("空闲超时的会话没有删除: %v", err)
+	}
+}
+
+func TestLoadAbsoluteTimeout(t *testing.T) {
+	m, store, now := testManager()
+	w := httptest.NewRecorder()
+	sess, err := m.Create(context.Background(), w, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := w.Result().Cookies()[0].Value
+	expires := sess.ExpiresAt
+
+	// 持续访问也不能超过绝对过期时间
+	for *now = now.Add(20 * time.Minute); !now.After(expires); *now = now.Add(20 * time.Minute) {
+		if _, err := m.Load(context.Background(), requestWith(value)); err != nil {
+			t.Fatalf("%v 时会话失效: %v", now.Sub(sess.CreatedAt), err)
+		}
+	}
+	if _, err := m.Load(context.Background(), requestWith(value)); err != ErrNotFound {
+		t.Errorf("超过绝对过期时间: %v，期望 ErrNotFound", err)
+	}
+	if _, err := store.Get(context.Background(), sess.ID); err != ErrNotFound {
+		t.Errorf("过期的会话没有删除: %v", err)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	m, store, _ := testManager()
+	created := httptest.NewRecorder()
+	sess, err := m.Create(context.Background(), created, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := created.Result().Cookies()[0].Value
+
+	tests := []struct {
+		name    string
+		r       *http.Request
+		deleted bool
+	}{
+		{"伪造的 cookie 不删除会话", requestWith(sess.ID + ".forged"), false},
+		{"没有 cookie", httptest.NewRequest(http.MethodPost, "/logout", nil), false},
+		{"删除会话", requestWith(value), true},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		if err := m.Destroy(context.Background(), w, tt.r); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		// 无论会话是否有效都清除 cookie
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != "ams_session" || cookies[0].Value != "" || cookies[0].MaxAge >= 0 {
+			t.Errorf("%s: 没有清除 cookie: %+v", tt.name, cookies)
+		}
+		if !strings.Contains(w.Header().Get("Set-Cookie"), "Max-Age=0") {
+			t.Errorf("%s: Set-Cookie 为 %q", tt.name, w.Header().Get("Set-Cookie"))
+		}
+		_, err := store.Get(context.Background(), sess.ID)
+		if deleted := err == ErrNotFound; deleted != tt.deleted {
+			t.Errorf("%s: 会话已删除为 %v，期望 %v", tt.name, deleted, tt.deleted)
+		}
+	}
+	if _, err := m.Load(context.Background(), requestWith(value)); err != ErrNotFound {
+		t.Errorf("登出后加载会话: %v，期望 ErrNotFound", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)
+	sessions := []Session{
+		{ID: "active", UserID: 1, LastSeenAt: now.Add(-10 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+		{ID: "idle", UserID: 1, LastSeenAt: now.Add(-31 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", UserID: 2, LastSeenAt: now, ExpiresAt: now.Add(-time.Second)},
+		{ID: "other", UserID: 2, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)},
+	}
+	for i := range sessions {
+		if err := store.Save(ctx, &sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 返回的是副本，修改不影响存储
+	got, err := store.Get(ctx, "active")
+	if err != nil || got.UserID != 1 {
+		t.Fatalf("Get: %+v, %v", got, err)
+	}
+	got.UserID = 9
+	if again, _ := store.Get(ctx, "active"); again.UserID != 1 {
+		t.Error("修改 Get 的返回值影响了存储")
+	}
+	if _, err := store.Get(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("Get 不存在的会话: %v", err)
+	}
+
+	if err := store.DeleteExpired(ctx, now, 30*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	assertSessions(t, store, "DeleteExpired", "active", "other")
+
+	if err := store.DeleteByUser(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	assertSessions(t, store, "DeleteByUser", "active")
+
+	if err := store.Delete(ctx, "active"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "active"); err != nil {
+		t.Errorf("删除不存在的会话: %v", err)
+	}
+	assertSessions(t, store, "Delete")
+}
+
+// assertSessions 检查存储中恰好有 ids 这些会话
+func assertSessions(t *testing.T, store *MemoryStore, step string, ids ...string) {
+	t.Helper()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.sessions) != len(ids) {
+		t.Errorf("%s 之后有 %d 个会话，期望 %v", step, len(store.sessions), ids)
+	}
+	for _, id := range ids {
+		if _, ok := store.sessions[id]; !ok {
+			t.Errorf("%s 之后缺少会话 %s", step, id)
+		}
+	}
+}
+
+func TestRunCleanup(t *testing.T) {
+	m, store, now := testManager()
+	w := httptest.NewRecorder()
+	if _, err := m.Create(context.Background(), w, 1); err != nil {
+		t.Fatal(err)
+	}
+	// 时钟只在启动清理前调整，避免与清理协程并发读写
+	*now = now.Add(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.RunCleanup(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		store.mu.Lock()
+		n := len(store.sessions)
+		store.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("清理协程没有删除空闲超时的会话")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ctx 取消后清理协程没有退出")
+	}
+}
@@ -0,0 +1,41 @@
+package zpl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DefaultPort 打印机 raw 打印（JetDirect）端口
+const DefaultPort = "9100"
+
+// Addr 补全打印机地址，省略端口时使用 9100
+func Addr(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return net.JoinHostPort(addr, DefaultPort)
+	}
+	return addr
+}
+
+// Send 通过 raw TCP 把 ZPL 发送到打印机。打印机不返回结果，数据全部写入并正常关闭连接即视为成功
+func Send(ctx context.Context, addr string, data []byte, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", Addr(addr))
+	if err != nil {
+		return fmt.Errorf("连接打印机 %s 失败: %w", addr, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("发送到打印机 %s 失败: %w", addr, err)
+	}
+	if err := conn.Close(); err != nil {
+		return fmt.Errorf("发送到打印机 %s 失败: %w", addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package zpl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestAddr(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"192.168.1.20", "192.168.1.20:9100"},
+		{"192.168.1.20:6101", "192.168.1.20:6101"},
+		{"printer.local", "printer.local:9100"},
+		{"fe80::1", "[fe80::1]:9100"},
+		{"[fe80::1]:6101", "[fe80::1]:6101"},
+	}
+	for _, tt := range tests {
+		if got := Addr(tt.in); got != tt.want {
+			t.Errorf("Addr(%q) = %q，期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	data := []byte("^XA\n^FO10,10^FDZC-0001^FS\n^XZ\n")
+	if err := Send(context.Background(), ln.Addr().String(), data, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		if !bytes.Equal(got, data) {
+			t.Errorf("打印机收到 %q，期望 %q", got, data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("打印机没有收到数据")
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	// 先占用一个端口再关闭，保证连接被拒绝
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	if err := Send(context.Background(), addr, []byte("^XA^XZ"), time.Second); err == nil {
+		t.Error("打印机无法连接时应该返回错误")
+	}
+}
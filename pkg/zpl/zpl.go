@@ -0,0 +1,230 @@
+// Package zpl 把资产标签渲染为 Zebra 热敏标签打印机的 ZPL 指令，并通过 raw TCP（9100 端口）发送到打印机。
+//
+// 标签模板使用 text/template 语法，模板只描述标签内容，ZPL 的开始、结束、字符集和份数由 Render 添加。
+// 模板中尺寸的单位为毫米，按打印机分辨率换算为点，可用的函数：
+//
+//	at x y                    定位到 (x, y)，即 ^FO
+//	text s height             单行文字，字高 height
+//	wrap s height width n     在 width 宽度内折行，最多 n 行
+//	qr s side                 边长约为 side 的二维码
+//	code128 s height width    宽度不超过 width 的 Code128 条码，下方打印条码内容
+//	dots mm                   毫米换算为点
+package zpl
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+)
+
+// Fields 模板可以使用的资产字段。Code 为资产编码，没有时为序列号；Link 为资产页面链接
+type Fields struct {
+	Name         string
+	AssetCode    string
+	SerialNumber string
+	Category     string
+	Department   string
+	Location     string
+	Code         string
+	Link         string
+}
+
+// Options 渲染参数。DPI 为打印机分辨率（203 或 300）；Font 为打印机上的中文字体（如 E:SIMSUN.TTF），
+// 为空时使用内置字体 0，只能打印英文和数字；Copies 为每张标签的份数
+type Options struct {
+	DPI    int
+	Font   string
+	Copies int
+}
+
+// Template 一种标签模板，Width、Height 为标签尺寸（毫米）
+type Template struct {
+	Name   string  `json:"name"`
+	Title  string  `json:"title"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	tmpl   *template.Template
+}
+
+// builtinTemplates 内置模板：qr- 开头的二维码编码资产链接，code128- 开头的条码编码资产编码
+var builtinTemplates = []struct {
+	name, title   string
+	width, height float64
+	body          string
+}{
+	{"qr-50x30", "二维码 50×30mm", 50, 30, `{{at 2 3}}{{qr .Link 24}}
+{{at 27 3}}{{wrap .Name 3 21 2}}
+{{at 27 12}}{{wrap .AssetCode 2.5 21 1}}
+{{at 27 17}}{{wrap .SerialNumber 2.5 21 1}}`},
+	{"code128-50x30", "Code128 50×30mm", 50, 30, `{{at 2 2}}{{wrap .Name 3 46 1}}
+{{at 2 7}}{{code128 .Code 15 46}}`},
+	{"qr-60x40", "二维码 60×40mm", 60, 40, `{{at 2 3}}{{qr .Link 34}}
+{{at 37 3}}{{wrap .Name 3.5 21 3}}
+{{at 37 17}}{{wrap .AssetCode 2.8 21 1}}
+{{at 37 22}}{{wrap .SerialNumber 2.8 21 1}}
+{{at 37 27}}{{wrap .Location 2.8 21 2}}`},
+	{"code128-60x40", "Code128 60×40mm", 60, 40, `{{at 2 2}}{{wrap .Name 3.5 56 1}}
+{{at 2 8}}{{code128 .Code 18 56}}
+{{at 2 33}}{{wrap .SerialNumber 2.8 56 1}}`},
+}
+
+// Templates 已加载的模板，按名称查找
+type Templates struct {
+	list []*Template
+}
+
+// LoadTemplates 加载内置模板和 dir 目录下的自定义模板（可为空）。
+// 自定义模板的文件名为 <名称>-<宽>x<高>.zpl，如 desk-40x20.zpl，与内置模板同名时覆盖内置模板
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{}
+	for _, b := range builtinTemplates {
+		if err := t.add(b.name, b.title, b.width, b.height, b.body); err != nil {
+			return nil, err
+		}
+	}
+	if dir == "" {
+		return t, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.zpl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".zpl")
+		var width, height float64
+		if i := strings.LastIndexByte(name, '-'); i < 0 {
+			return nil, fmt.Errorf("模板 %s 的文件名应为 <名称>-<宽>x<高>.zpl", file)
+		} else if _, err := fmt.Sscanf(name[i+1:], "%gx%g", &width, &height); err != nil || width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("模板 %s 的文件名应为 <名称>-<宽>x<高>.zpl", file)
+		}
+		body, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取模板 %s 失败: %w", file, err)
+		}
+		if err := t.add(name, name, width, height, string(body)); err != nil {
+			return nil, fmt.Errorf("模板 %s: %w", file, err)
+		}
+	}
+	return t, nil
+}
+
+func (t *Templates) add(name, title string, width, height float64, body string) error {
+	tmpl, err := template.New(name).Funcs(funcs(Options{DPI: 203})).Parse(body)
+	if err != nil {
+		return err
+	}
+	// 试渲染一次，尽早发现模板中的错误
+	sample := Fields{Name: "0", AssetCode: "0", SerialNumber: "0", Category: "0", Department: "0", Location: "0", Code: "0", Link: "0"}
+	if err := tmpl.Execute(&bytes.Buffer{}, sample); err != nil {
+		return err
+	}
+	for i, existing := range t.list {
+		if existing.Name == name {
+			t.list[i] = &Template{Name: name, Title: title, Width: width, Height: height, tmpl: tmpl}
+			return nil
+		}
+	}
+	t.list = append(t.list, &Template{Name: name, Title: title, Width: width, Height: height, tmpl: tmpl})
+	return nil
+}
+
+// List 返回全部模板，内置模板在前，第一个为默认模板
+func (t *Templates) List() []*Template {
+	return t.list
+}
+
+// Find 按名称查找模板
+func (t *Templates) Find(name string) (*Template, bool) {
+	for _, tmpl := range t.list {
+		if tmpl.Name == name {
+			return tmpl, true
+		}
+	}
+	return nil, false
+}
+
+// Render 把每项资产渲染为一张标签（^XA ... ^XZ），依次写在一起
+func (t *Template) Render(fields []Fields, opts Options) ([]byte, error) {
+	if opts.DPI == 0 {
+		opts.DPI = 203
+	}
+	if opts.Copies < 1 {
+		opts.Copies = 1
+	}
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(funcs(opts))
+
+	var buf bytes.Buffer
+	for i, f := range fields {
+		fmt.Fprintf(&buf, "^XA\n^CI28\n^PW%d\n^LL%d\n^LH0,0\n", dots(t.Width, opts.DPI), dots(t.Height, opts.DPI))
+		if opts.Font != "" {
+			fmt.Fprintf(&buf, "^CW1,%s\n", opts.Font)
+		}
+		if err := tmpl.Execute(&buf, f); err != nil {
+			return nil, fmt.Errorf("第 %d 张标签: %w", i+1, err)
+		}
+		fmt.Fprintf(&buf, "\n^PQ%d\n^XZ\n", opts.Copies)
+	}
+	return buf.Bytes(), nil
+}
+
+// dots 把毫米换算为打印机的点
+func dots(mm float64, dpi int) int {
+	return int(math.Round(mm / 25.4 * float64(dpi)))
+}
+
+// escape 转义字段内容，配合 ^FH_ 使用：^、~ 和 _ 以十六进制表示，换行替换为空格
+func escape(s string) string {
+	r := strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E", "\r", "", "\n", " ")
+	return r.Replace(s)
+}
+
+// funcs 模板函数，尺寸按 opts.DPI 换算
+func funcs(opts Options) template.FuncMap {
+	font := "0"
+	if opts.Font != "" {
+		font = "1"
+	}
+	d := func(mm float64) int { return dots(mm, opts.DPI) }
+	return template.FuncMap{
+		"dots": d,
+		"at": func(x, y float64) string {
+			return fmt.Sprintf("^FO%d,%d", d(x), d(y))
+		},
+		"text": func(s string, height float64) string {
+			return fmt.Sprintf("^A%sN,%d,%d^FH_^FD%s^FS", font, d(height), d(height), escape(s))
+		},
+		"wrap": func(s string, height, width float64, lines int) string {
+			return fmt.Sprintf("^A%sN,%d,%d^FB%d,%d,0,L,0^FH_^FD%s^FS", font, d(height), d(height), d(width), lines, escape(s))
+		},
+		"qr": func(s string, side float64) (string, error) {
+			code, err := qr.Encode(s, qr.M, qr.Auto)
+			if err != nil {
+				return "", err
+			}
+			// 放大倍数为每个模块的点数，ZPL 允许 1 到 10
+			mag := int(math.Max(1, math.Min(10, math.Floor(float64(d(side))/float64(code.Bounds().Dx())))))
+			return fmt.Sprintf("^BQN,2,%d^FH_^FDMA,%s^FS", mag, escape(s)), nil
+		},
+		"code128": func(s string, height, width float64) (string, error) {
+			code, err := code128.Encode(s)
+			if err != nil {
+				return "", fmt.Errorf("无法编码为 Code128 条码（只支持 ASCII 字符）: %q", s)
+			}
+			module := int(math.Max(1, math.Min(10, math.Floor(float64(d(width))/float64(code.Bounds().Dx())))))
+			return fmt.Sprintf("^BY%d^BCN,%d,Y,N,N^FH_^FD%s^FS", module, d(height), escape(s)), nil
+		},
+	}
+}
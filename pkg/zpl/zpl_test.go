@@ -0,0 +1,61 @@
+package zpl
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata 下的 golden 文件")
+
+func TestRenderBuiltin(t *testing.T) {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := []Fields{
+		{
+			Name:         "联想 ThinkPad X1 Carbon 笔记本电脑",
+			AssetCode:    "ZC-0001",
+			SerialNumber: "PF2ABCDE",
+			Category:     "笔记本电脑",
+			Department:   "IT",
+			Location:     "北京总部 5F 机房",
+			Code:         "ZC-0001",
+			Link:         "https://ams.example.com/assets/1",
+		},
+		// 特殊字符需要转义，空字段不影响渲染
+		{Name: "^打印机~_测试", SerialNumber: "SN_2", Code: "SN_2", Link: "https://ams.example.com/assets/2"},
+	}
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"203dpi", Options{}},
+		{"300dpi", Options{DPI: 300, Font: "E:SIMSUN.TTF", Copies: 2}},
+	}
+	for _, tmpl := range templates.List() {
+		for _, tt := range tests {
+			got, err := tmpl.Render(fields, tt.opts)
+			if err != nil {
+				t.Fatalf("%s %s: %v", tmpl.Name, tt.name, err)
+			}
+			golden := filepath.Join("testdata", tmpl.Name+"-"+tt.name+".zpl")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v（使用 -update 生成）", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s %s 的渲染结果与 %s 不一致:\n%s", tmpl.Name, tt.name, golden, got)
+			}
+		}
+	}
+}